@@ -0,0 +1,479 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// DroppedFieldsAnnotation keeps the v1beta1 fields that v1 does not have, so
+// that converting back to v1beta1 does not lose them.
+const DroppedFieldsAnnotation = "sro.openshift.io/v1beta1-dropped-fields"
+
+// droppedFields are the v1beta1 spec fields removed in v1.
+type droppedFields struct {
+	ForceUpgrade    bool                                    `json:"forceUpgrade,omitempty"`
+	DriverContainer *v1beta1.SpecialResourceDriverContainer `json:"driverContainer,omitempty"`
+}
+
+// ConvertTo converts this SpecialResource to the Hub version (v1beta1).
+func (src *SpecialResource) ConvertTo(dstRaw conversion.Hub) error {
+
+	dst := dstRaw.(*v1beta1.SpecialResource)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	if raw, ok := dst.Annotations[DroppedFieldsAnnotation]; ok {
+		dropped := droppedFields{}
+		if err := json.Unmarshal([]byte(raw), &dropped); err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", DroppedFieldsAnnotation, err)
+		}
+
+		dst.Spec.ForceUpgrade = dropped.ForceUpgrade
+		if dropped.DriverContainer != nil {
+			dst.Spec.DriverContainer = *dropped.DriverContainer
+		}
+
+		delete(dst.Annotations, DroppedFieldsAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	var err error
+
+	dst.Spec.Chart = src.Spec.Chart
+	dst.Spec.Namespace = src.Spec.Namespace
+	dst.Spec.Debug = src.Spec.Debug
+	dst.Spec.RolloutToken = src.Spec.RolloutToken
+	dst.Spec.NodeSelector = src.Spec.NodeSelector
+	dst.Spec.Signing = signingToV1beta1(src.Spec.Signing)
+	dst.Spec.PostRender = postRenderToV1beta1(src.Spec.PostRender)
+	dst.Spec.ImagePolicy = imagePolicyToV1beta1(src.Spec.ImagePolicy)
+	dst.Spec.UpgradeStrategy = upgradeStrategyToV1beta1(src.Spec.UpgradeStrategy)
+	dst.Spec.MaintenanceWindows = maintenanceWindowsToV1beta1(src.Spec.MaintenanceWindows)
+	dst.Spec.NotReadyTaint = src.Spec.NotReadyTaint
+	dst.Spec.Suspend = src.Spec.Suspend
+	dst.Spec.SuspendDependents = src.Spec.SuspendDependents
+	dst.Spec.Drift = (*v1beta1.SpecialResourceDriftSpec)(src.Spec.Drift.DeepCopy())
+	dst.Spec.DryRun = src.Spec.DryRun
+
+	if dst.Spec.Set, err = valuesToV1beta1(src.Spec.Set); err != nil {
+		return fmt.Errorf("spec.set: %w", err)
+	}
+
+	dst.Spec.Dependencies = nil
+	for i, dep := range src.Spec.Dependencies {
+		d := v1beta1.SpecialResourceDependency{HelmChart: dep.Chart}
+		if d.Set, err = valuesToV1beta1(dep.Set); err != nil {
+			return fmt.Errorf("spec.dependencies[%d].set: %w", i, err)
+		}
+		dst.Spec.Dependencies = append(dst.Spec.Dependencies, d)
+	}
+
+	dst.Status = v1beta1.SpecialResourceStatus{
+		State:                 src.Status.State,
+		DriverImages:          driverImagesToV1beta1(src.Status.DriverImages),
+		Images:                resolvedImagesToV1beta1(src.Status.Images),
+		ObservedRolloutToken:  src.Status.ObservedRolloutToken,
+		NodeUpgrades:          nodeUpgradesToV1beta1(src.Status.NodeUpgrades),
+		PendingChanges:        pendingChangesToV1beta1(src.Status.PendingChanges),
+		Drift:                 driftToV1beta1(src.Status.Drift),
+		NextMaintenanceWindow: src.Status.NextMaintenanceWindow,
+		Conditions:            src.Status.Conditions,
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *SpecialResource) ConvertFrom(srcRaw conversion.Hub) error {
+
+	src := srcRaw.(*v1beta1.SpecialResource)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dropped := droppedFields{ForceUpgrade: src.Spec.ForceUpgrade}
+	if !reflect.DeepEqual(src.Spec.DriverContainer, v1beta1.SpecialResourceDriverContainer{}) {
+		dropped.DriverContainer = src.Spec.DriverContainer.DeepCopy()
+	}
+
+	if dropped != (droppedFields{}) {
+		raw, err := json.Marshal(dropped)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = make(map[string]string)
+		}
+		dst.Annotations[DroppedFieldsAnnotation] = string(raw)
+	}
+
+	var err error
+
+	dst.Spec.Chart = src.Spec.Chart
+	dst.Spec.Namespace = src.Spec.Namespace
+	dst.Spec.Debug = src.Spec.Debug
+	dst.Spec.RolloutToken = src.Spec.RolloutToken
+	dst.Spec.NodeSelector = src.Spec.NodeSelector
+	dst.Spec.Signing = signingFromV1beta1(src.Spec.Signing)
+	dst.Spec.PostRender = postRenderFromV1beta1(src.Spec.PostRender)
+	dst.Spec.ImagePolicy = imagePolicyFromV1beta1(src.Spec.ImagePolicy)
+	dst.Spec.UpgradeStrategy = upgradeStrategyFromV1beta1(src.Spec.UpgradeStrategy)
+	dst.Spec.MaintenanceWindows = maintenanceWindowsFromV1beta1(src.Spec.MaintenanceWindows)
+	dst.Spec.NotReadyTaint = src.Spec.NotReadyTaint
+	dst.Spec.Suspend = src.Spec.Suspend
+	dst.Spec.SuspendDependents = src.Spec.SuspendDependents
+	dst.Spec.Drift = (*SpecialResourceDriftSpec)(src.Spec.Drift.DeepCopy())
+	dst.Spec.DryRun = src.Spec.DryRun
+
+	if dst.Spec.Set, err = valuesFromV1beta1(src.Spec.Set); err != nil {
+		return fmt.Errorf("spec.set: %w", err)
+	}
+
+	dst.Spec.Dependencies = nil
+	for i, dep := range src.Spec.Dependencies {
+		d := SpecialResourceDependency{Chart: dep.HelmChart}
+		if d.Set, err = valuesFromV1beta1(dep.Set); err != nil {
+			return fmt.Errorf("spec.dependencies[%d].set: %w", i, err)
+		}
+		dst.Spec.Dependencies = append(dst.Spec.Dependencies, d)
+	}
+
+	dst.Status = SpecialResourceStatus{
+		State:                 src.Status.State,
+		DriverImages:          driverImagesFromV1beta1(src.Status.DriverImages),
+		Images:                resolvedImagesFromV1beta1(src.Status.Images),
+		ObservedRolloutToken:  src.Status.ObservedRolloutToken,
+		NodeUpgrades:          nodeUpgradesFromV1beta1(src.Status.NodeUpgrades),
+		PendingChanges:        pendingChangesFromV1beta1(src.Status.PendingChanges),
+		Drift:                 driftFromV1beta1(src.Status.Drift),
+		NextMaintenanceWindow: src.Status.NextMaintenanceWindow,
+		Conditions:            src.Status.Conditions,
+	}
+
+	return nil
+}
+
+// valuesToV1beta1 returns values as the embedded resource v1beta1 expects.
+func valuesToV1beta1(values runtime.RawExtension) (unstructured.Unstructured, error) {
+
+	set := unstructured.Unstructured{Object: make(map[string]interface{})}
+
+	if len(values.Raw) > 0 {
+		if err := json.Unmarshal(values.Raw, &set.Object); err != nil {
+			return set, err
+		}
+	}
+
+	set.SetKind("Values")
+	set.SetAPIVersion(v1beta1.GroupVersion.String())
+
+	return set, nil
+}
+
+// valuesFromV1beta1 returns the values of the embedded resource without its kind and apiVersion.
+func valuesFromV1beta1(set unstructured.Unstructured) (runtime.RawExtension, error) {
+
+	values := make(map[string]interface{}, len(set.Object))
+	for k, v := range set.Object {
+		if k != "kind" && k != "apiVersion" {
+			values[k] = v
+		}
+	}
+
+	if len(values) == 0 {
+		return runtime.RawExtension{}, nil
+	}
+
+	raw, err := json.Marshal(values)
+
+	return runtime.RawExtension{Raw: raw}, err
+}
+
+// The v1 types mirror the v1beta1 types field by field. The structs without nested
+// SpecialResource types are converted directly, a field added to only one of the
+// versions does not compile; the others are converted field by field.
+
+func signingToV1beta1(in *SpecialResourceSigning) *v1beta1.SpecialResourceSigning {
+	if in == nil {
+		return nil
+	}
+	return &v1beta1.SpecialResourceSigning{
+		KeySecretRef:  v1beta1.SpecialResourceSecretKeyRef(in.KeySecretRef),
+		CertSecretRef: v1beta1.SpecialResourceSecretKeyRef(in.CertSecretRef),
+		TagSuffix:     in.TagSuffix,
+		ModulesPath:   in.ModulesPath,
+	}
+}
+
+func signingFromV1beta1(in *v1beta1.SpecialResourceSigning) *SpecialResourceSigning {
+	if in == nil {
+		return nil
+	}
+	return &SpecialResourceSigning{
+		KeySecretRef:  SpecialResourceSecretKeyRef(in.KeySecretRef),
+		CertSecretRef: SpecialResourceSecretKeyRef(in.CertSecretRef),
+		TagSuffix:     in.TagSuffix,
+		ModulesPath:   in.ModulesPath,
+	}
+}
+
+func postRenderToV1beta1(in *SpecialResourcePostRender) *v1beta1.SpecialResourcePostRender {
+	if in == nil {
+		return nil
+	}
+	out := &v1beta1.SpecialResourcePostRender{}
+	if in.Patches != nil {
+		out.Patches = make([]v1beta1.SpecialResourcePatch, len(in.Patches))
+		for i, patch := range in.Patches {
+			out.Patches[i] = v1beta1.SpecialResourcePatch{
+				Type:   patch.Type,
+				Patch:  patch.Patch,
+				Target: (*v1beta1.SpecialResourcePatchTarget)(patch.Target.DeepCopy()),
+			}
+		}
+	}
+	if in.Images != nil {
+		out.Images = make([]v1beta1.SpecialResourceImageOverride, len(in.Images))
+		for i, image := range in.Images {
+			out.Images[i] = v1beta1.SpecialResourceImageOverride(image)
+		}
+	}
+	return out
+}
+
+func postRenderFromV1beta1(in *v1beta1.SpecialResourcePostRender) *SpecialResourcePostRender {
+	if in == nil {
+		return nil
+	}
+	out := &SpecialResourcePostRender{}
+	if in.Patches != nil {
+		out.Patches = make([]SpecialResourcePatch, len(in.Patches))
+		for i, patch := range in.Patches {
+			out.Patches[i] = SpecialResourcePatch{
+				Type:   patch.Type,
+				Patch:  patch.Patch,
+				Target: (*SpecialResourcePatchTarget)(patch.Target.DeepCopy()),
+			}
+		}
+	}
+	if in.Images != nil {
+		out.Images = make([]SpecialResourceImageOverride, len(in.Images))
+		for i, image := range in.Images {
+			out.Images[i] = SpecialResourceImageOverride(image)
+		}
+	}
+	return out
+}
+
+func imagePolicyToV1beta1(in *SpecialResourceImagePolicy) *v1beta1.SpecialResourceImagePolicy {
+	if in == nil {
+		return nil
+	}
+	out := &v1beta1.SpecialResourceImagePolicy{
+		PinDigests:        in.PinDigests,
+		AllowedRegistries: append([]string(nil), in.AllowedRegistries...),
+	}
+	if in.RegistryRewrites != nil {
+		out.RegistryRewrites = make([]v1beta1.SpecialResourceRegistryRewrite, len(in.RegistryRewrites))
+		for i, rewrite := range in.RegistryRewrites {
+			out.RegistryRewrites[i] = v1beta1.SpecialResourceRegistryRewrite(rewrite)
+		}
+	}
+	return out
+}
+
+func imagePolicyFromV1beta1(in *v1beta1.SpecialResourceImagePolicy) *SpecialResourceImagePolicy {
+	if in == nil {
+		return nil
+	}
+	out := &SpecialResourceImagePolicy{
+		PinDigests:        in.PinDigests,
+		AllowedRegistries: append([]string(nil), in.AllowedRegistries...),
+	}
+	if in.RegistryRewrites != nil {
+		out.RegistryRewrites = make([]SpecialResourceRegistryRewrite, len(in.RegistryRewrites))
+		for i, rewrite := range in.RegistryRewrites {
+			out.RegistryRewrites[i] = SpecialResourceRegistryRewrite(rewrite)
+		}
+	}
+	return out
+}
+
+func upgradeStrategyToV1beta1(in *SpecialResourceUpgradeStrategy) *v1beta1.SpecialResourceUpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	in = in.DeepCopy()
+	return &v1beta1.SpecialResourceUpgradeStrategy{
+		Type:           in.Type,
+		MaxUnavailable: in.MaxUnavailable,
+		Drain:          (*v1beta1.SpecialResourceDrainSpec)(in.Drain),
+	}
+}
+
+func upgradeStrategyFromV1beta1(in *v1beta1.SpecialResourceUpgradeStrategy) *SpecialResourceUpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	in = in.DeepCopy()
+	return &SpecialResourceUpgradeStrategy{
+		Type:           in.Type,
+		MaxUnavailable: in.MaxUnavailable,
+		Drain:          (*SpecialResourceDrainSpec)(in.Drain),
+	}
+}
+
+func maintenanceWindowsToV1beta1(in []SpecialResourceMaintenanceWindow) []v1beta1.SpecialResourceMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1beta1.SpecialResourceMaintenanceWindow, len(in))
+	for i := range in {
+		out[i] = v1beta1.SpecialResourceMaintenanceWindow(in[i])
+	}
+	return out
+}
+
+func maintenanceWindowsFromV1beta1(in []v1beta1.SpecialResourceMaintenanceWindow) []SpecialResourceMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := make([]SpecialResourceMaintenanceWindow, len(in))
+	for i := range in {
+		out[i] = SpecialResourceMaintenanceWindow(in[i])
+	}
+	return out
+}
+
+func driverImagesToV1beta1(in []DriverImageStatus) []v1beta1.DriverImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1beta1.DriverImageStatus, len(in))
+	for i := range in {
+		out[i] = v1beta1.DriverImageStatus(*in[i].DeepCopy())
+	}
+	return out
+}
+
+func driverImagesFromV1beta1(in []v1beta1.DriverImageStatus) []DriverImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]DriverImageStatus, len(in))
+	for i := range in {
+		out[i] = DriverImageStatus(*in[i].DeepCopy())
+	}
+	return out
+}
+
+func resolvedImagesToV1beta1(in []ResolvedImageStatus) []v1beta1.ResolvedImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1beta1.ResolvedImageStatus, len(in))
+	for i := range in {
+		out[i] = v1beta1.ResolvedImageStatus(in[i])
+	}
+	return out
+}
+
+func resolvedImagesFromV1beta1(in []v1beta1.ResolvedImageStatus) []ResolvedImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]ResolvedImageStatus, len(in))
+	for i := range in {
+		out[i] = ResolvedImageStatus(in[i])
+	}
+	return out
+}
+
+func nodeUpgradesToV1beta1(in []NodeUpgradeStatus) []v1beta1.NodeUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1beta1.NodeUpgradeStatus, len(in))
+	for i := range in {
+		out[i] = v1beta1.NodeUpgradeStatus(*in[i].DeepCopy())
+	}
+	return out
+}
+
+func nodeUpgradesFromV1beta1(in []v1beta1.NodeUpgradeStatus) []NodeUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]NodeUpgradeStatus, len(in))
+	for i := range in {
+		out[i] = NodeUpgradeStatus(*in[i].DeepCopy())
+	}
+	return out
+}
+
+func pendingChangesToV1beta1(in []PendingChangeStatus) []v1beta1.PendingChangeStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1beta1.PendingChangeStatus, len(in))
+	for i := range in {
+		out[i] = v1beta1.PendingChangeStatus(*in[i].DeepCopy())
+	}
+	return out
+}
+
+func pendingChangesFromV1beta1(in []v1beta1.PendingChangeStatus) []PendingChangeStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]PendingChangeStatus, len(in))
+	for i := range in {
+		out[i] = PendingChangeStatus(*in[i].DeepCopy())
+	}
+	return out
+}
+
+func driftToV1beta1(in []DriftStatus) []v1beta1.DriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1beta1.DriftStatus, len(in))
+	for i := range in {
+		out[i] = v1beta1.DriftStatus(*in[i].DeepCopy())
+	}
+	return out
+}
+
+func driftFromV1beta1(in []v1beta1.DriftStatus) []DriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]DriftStatus, len(in))
+	for i := range in {
+		out[i] = DriftStatus(*in[i].DeepCopy())
+	}
+	return out
+}
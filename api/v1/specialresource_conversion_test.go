@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestV1(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "V1 Suite")
+}
+
+func values(object map[string]interface{}) unstructured.Unstructured {
+	set := unstructured.Unstructured{Object: object}
+	set.SetKind("Values")
+	set.SetAPIVersion(v1beta1.GroupVersion.String())
+	return set
+}
+
+var _ = Describe("SpecialResource_Conversion", func() {
+	chart := helmerv1beta1.HelmChart{Name: "simple-kmod", Version: "0.0.1"}
+
+	hub := func() *v1beta1.SpecialResource {
+		return &v1beta1.SpecialResource{
+			ObjectMeta: metav1.ObjectMeta{Name: "simple-kmod", Labels: map[string]string{"a": "b"}},
+			Spec: v1beta1.SpecialResourceSpec{
+				Chart:        chart,
+				Namespace:    "simple-kmod",
+				Debug:        true,
+				RolloutToken: "1",
+				Set:          values(map[string]interface{}{"kmodNames": []interface{}{"simple-kmod"}}),
+				NodeSelector: map[string]string{"node-role.kubernetes.io/worker": ""},
+				Dependencies: []v1beta1.SpecialResourceDependency{
+					{HelmChart: helmerv1beta1.HelmChart{Name: "driver-container-base"}, Set: values(map[string]interface{}{})},
+				},
+				Signing: &v1beta1.SpecialResourceSigning{
+					KeySecretRef:  v1beta1.SpecialResourceSecretKeyRef{Name: "signing-key", Key: "key.priv"},
+					CertSecretRef: v1beta1.SpecialResourceSecretKeyRef{Name: "signing-key", Key: "cert.der"},
+				},
+				PostRender: &v1beta1.SpecialResourcePostRender{
+					Patches: []v1beta1.SpecialResourcePatch{
+						{Patch: "metadata:\n  labels:\n    team: kmod\n", Target: &v1beta1.SpecialResourcePatchTarget{Kind: "DaemonSet"}},
+					},
+					Images: []v1beta1.SpecialResourceImageOverride{{Name: "quay.io/vendor/driver", NewTag: "1.1"}},
+				},
+				ImagePolicy: &v1beta1.SpecialResourceImagePolicy{
+					PinDigests:       true,
+					RegistryRewrites: []v1beta1.SpecialResourceRegistryRewrite{{From: "quay.io", To: "mirror.corp/quay"}},
+				},
+				UpgradeStrategy: &v1beta1.SpecialResourceUpgradeStrategy{
+					Type:  v1beta1.UpgradeStrategyOnDelete,
+					Drain: &v1beta1.SpecialResourceDrainSpec{Force: true},
+				},
+				MaintenanceWindows: []v1beta1.SpecialResourceMaintenanceWindow{
+					{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+				},
+				NotReadyTaint: true,
+				Suspend:       true,
+				Drift:         &v1beta1.SpecialResourceDriftSpec{Policy: v1beta1.DriftPolicyReportOnly},
+				DryRun:        true,
+			},
+			Status: v1beta1.SpecialResourceStatus{
+				State:                "driver-container",
+				DriverImages:         []v1beta1.DriverImageStatus{{Image: "quay.io/vendor/driver:1.0", State: "Present", Present: true}},
+				Images:               []v1beta1.ResolvedImageStatus{{Kind: "DaemonSet", Name: "driver", Container: "driver", Image: "quay.io/vendor/driver:1.0"}},
+				ObservedRolloutToken: "0",
+				NodeUpgrades:         []v1beta1.NodeUpgradeStatus{{Node: "worker-0", State: v1beta1.NodeUpgradeDone}},
+				PendingChanges:       []v1beta1.PendingChangeStatus{{Kind: "DaemonSet", Name: "driver", Change: v1beta1.PendingDaemonSetUpdate}},
+				Drift:                []v1beta1.DriftStatus{{Kind: "DaemonSet", Name: "driver", Fields: []string{"spec.template.spec.hostNetwork"}}},
+				Conditions:           []metav1.Condition{{Type: v1beta1.ConditionValuesInvalid, Status: metav1.ConditionFalse}},
+			},
+		}
+	}
+
+	It("should convert from v1beta1 without kind and apiVersion in the values", func() {
+		sr := &SpecialResource{}
+		Expect(sr.ConvertFrom(hub())).To(Succeed())
+
+		Expect(sr.Name).To(Equal("simple-kmod"))
+		Expect(sr.Spec.Chart).To(Equal(chart))
+		Expect(sr.Spec.Namespace).To(Equal("simple-kmod"))
+		Expect(sr.Spec.Set.Raw).To(MatchJSON(`{"kmodNames": ["simple-kmod"]}`))
+		Expect(sr.Spec.Dependencies).To(HaveLen(1))
+		Expect(sr.Spec.Dependencies[0].Chart.Name).To(Equal("driver-container-base"))
+		Expect(sr.Spec.Dependencies[0].Set.Raw).To(BeEmpty())
+		Expect(sr.Spec.ImagePolicy.PinDigests).To(BeTrue())
+		Expect(sr.Spec.ImagePolicy.RegistryRewrites).To(Equal([]SpecialResourceRegistryRewrite{{From: "quay.io", To: "mirror.corp/quay"}}))
+		Expect(sr.Spec.Signing.KeySecretRef).To(Equal(SpecialResourceSecretKeyRef{Name: "signing-key", Key: "key.priv"}))
+		Expect(sr.Spec.PostRender.Patches[0].Target.Kind).To(Equal("DaemonSet"))
+		Expect(sr.Spec.PostRender.Images).To(Equal([]SpecialResourceImageOverride{{Name: "quay.io/vendor/driver", NewTag: "1.1"}}))
+		Expect(sr.Spec.UpgradeStrategy.Drain.Force).To(BeTrue())
+		Expect(sr.Status.DriverImages).To(HaveLen(1))
+		Expect(sr.Status.Images).To(HaveLen(1))
+		Expect(sr.Spec.RolloutToken).To(Equal("1"))
+		Expect(sr.Status.State).To(Equal("driver-container"))
+		Expect(sr.Status.ObservedRolloutToken).To(Equal("0"))
+		Expect(sr.Status.NodeUpgrades).To(HaveLen(1))
+		Expect(sr.Status.PendingChanges).To(HaveLen(1))
+		Expect(sr.Spec.MaintenanceWindows).To(HaveLen(1))
+		Expect(sr.Spec.NotReadyTaint).To(BeTrue())
+		Expect(sr.Spec.Suspend).To(BeTrue())
+		Expect(sr.Spec.Drift.Policy).To(Equal(DriftPolicyReportOnly))
+		Expect(sr.Status.Drift).To(HaveLen(1))
+		Expect(sr.Spec.DryRun).To(BeTrue())
+		Expect(sr.Spec.UpgradeStrategy.Type).To(Equal(UpgradeStrategyOnDelete))
+		Expect(sr.Status.Conditions).To(HaveLen(1))
+		Expect(sr.Annotations).NotTo(HaveKey(DroppedFieldsAnnotation))
+	})
+
+	It("should convert to v1beta1 with kind and apiVersion in the values", func() {
+		sr := &SpecialResource{
+			ObjectMeta: metav1.ObjectMeta{Name: "simple-kmod"},
+			Spec: SpecialResourceSpec{
+				Chart: chart,
+				Set:   runtime.RawExtension{Raw: []byte(`{"kmodNames": ["simple-kmod"]}`)},
+				Dependencies: []SpecialResourceDependency{
+					{Chart: helmerv1beta1.HelmChart{Name: "driver-container-base"}},
+				},
+			},
+		}
+
+		dst := &v1beta1.SpecialResource{}
+		Expect(sr.ConvertTo(dst)).To(Succeed())
+
+		Expect(dst.Spec.Set.Object).To(Equal(map[string]interface{}{
+			"kind":       "Values",
+			"apiVersion": "sro.openshift.io/v1beta1",
+			"kmodNames":  []interface{}{"simple-kmod"},
+		}))
+		Expect(dst.Spec.Dependencies[0].HelmChart.Name).To(Equal("driver-container-base"))
+		Expect(dst.Spec.Dependencies[0].Set.GetKind()).To(Equal("Values"))
+	})
+
+	It("should round-trip the fields dropped in v1", func() {
+		src := hub()
+		src.Spec.ForceUpgrade = true
+		src.Spec.DriverContainer.Source.Git.Ref = "master"
+
+		sr := &SpecialResource{}
+		Expect(sr.ConvertFrom(src)).To(Succeed())
+		Expect(sr.Annotations).To(HaveKey(DroppedFieldsAnnotation))
+
+		dst := &v1beta1.SpecialResource{}
+		Expect(sr.ConvertTo(dst)).To(Succeed())
+		Expect(dst).To(Equal(src))
+	})
+
+	It("should reject values that are not an object", func() {
+		sr := &SpecialResource{
+			Spec: SpecialResourceSpec{Set: runtime.RawExtension{Raw: []byte(`[1, 2]`)}},
+		}
+
+		Expect(sr.ConvertTo(&v1beta1.SpecialResource{})).NotTo(Succeed())
+	})
+})
@@ -19,6 +19,7 @@ package v1beta1
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
 )
@@ -66,15 +67,9 @@ type SpecialResourceConfiguration struct {
 	Value []string `json:"value"`
 }
 
-// SpecialResourceGit is not used.
-type SpecialResourceGit struct {
-	Ref string `json:"ref"`
-	Uri string `json:"uri"`
-}
-
 // SpecialResourceSource is not used.
 type SpecialResourceSource struct {
-	Git SpecialResourceGit `json:"git,omitempty"`
+	Git helmerv1beta1.HelmGit `json:"git,omitempty"`
 }
 
 // SpecialResourceDriverContainer is not used.
@@ -99,10 +94,16 @@ type SpecialResourceSpec struct {
 	// +kubebuilder:validation:Required
 	Namespace string `json:"namespace"`
 
-	// ForceUpgrade is not used.
+	// ForceUpgrade is not used, see RolloutToken.
 	// +kubebuilder:validation:Optional
 	ForceUpgrade bool `json:"forceUpgrade"`
 
+	// RolloutToken is an opaque string, changing it rebuilds the driver containers of the
+	// kernel affine states and restarts the pods of the SpecialResource's DaemonSets,
+	// Deployments and StatefulSets, e.g. when a driver image was republished under the same tag.
+	// +kubebuilder:validation:Optional
+	RolloutToken string `json:"rolloutToken,omitempty"`
+
 	// Debug enables additional logging.
 	// +kubebuilder:validation:Optional
 	Debug bool `json:"debug"`
@@ -124,6 +125,285 @@ type SpecialResourceSpec struct {
 	// Dependencies is a list of dependencies required by this SpecialReosurce.
 	// +kubebuilder:validation:Optional
 	Dependencies []SpecialResourceDependency `json:"dependencies,omitempty"`
+
+	// Signing enables signing the kernel modules built for the SpecialResource, e.g. for Secure Boot nodes.
+	// +kubebuilder:validation:Optional
+	Signing *SpecialResourceSigning `json:"signing,omitempty"`
+
+	// PostRender patches the objects rendered from the chart before they are created, e.g. to
+	// add tolerations or resource limits without forking the chart.
+	// +kubebuilder:validation:Optional
+	PostRender *SpecialResourcePostRender `json:"postRender,omitempty"`
+
+	// ImagePolicy rewrites, pins and restricts the container images of the rendered objects.
+	// It applies in addition to the operator-wide image policy.
+	// +kubebuilder:validation:Optional
+	ImagePolicy *SpecialResourceImagePolicy `json:"imagePolicy,omitempty"`
+
+	// UpgradeStrategy describes how the Pods of the driver DaemonSets are replaced when they change.
+	// +kubebuilder:validation:Optional
+	UpgradeStrategy *SpecialResourceUpgradeStrategy `json:"upgradeStrategy,omitempty"`
+
+	// MaintenanceWindows are the times the driver DaemonSets may be updated, the driver
+	// containers rebuilt for a RolloutToken, and the nodes upgraded. These changes are
+	// deferred to the next window outside of them. There is no restriction if empty.
+	// +kubebuilder:validation:Optional
+	MaintenanceWindows []SpecialResourceMaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// NotReadyTaint taints the nodes matched by NodeSelector with
+	// specialresource.openshift.io/<name>-not-ready:NoSchedule until all states are
+	// ready for their kernel and the Pods of the DaemonSets running on them are ready.
+	// +kubebuilder:validation:Optional
+	NotReadyTaint bool `json:"notReadyTaint,omitempty"`
+
+	// Suspend stops the reconciliation of the SpecialResource, the objects it owns are
+	// left untouched until it is resumed. The specialresource.openshift.io/paused
+	// annotation set to "true" suspends it as well.
+	// +kubebuilder:validation:Optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// SuspendDependents suspends the SpecialResources depending on this one while it
+	// is suspended, instead of only skipping it when reconciling them.
+	// +kubebuilder:validation:Optional
+	SuspendDependents bool `json:"suspendDependents,omitempty"`
+
+	// Drift describes how the changes made to the live objects outside of the chart are handled.
+	// +kubebuilder:validation:Optional
+	Drift *SpecialResourceDriftSpec `json:"drift,omitempty"`
+
+	// DryRun renders and applies the chart in server dry-run mode without changing the
+	// cluster, the diffs of the objects that would change are written to the
+	// <name>-dry-run ConfigMap in the namespace of the operator.
+	// +kubebuilder:validation:Optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// SpecialResourceMaintenanceWindow is a recurring period of time disruptive changes are allowed in.
+type SpecialResourceMaintenanceWindow struct {
+	// Schedule is a cron expression, e.g. "0 22 * * 1-5", of the times the window opens.
+	// +kubebuilder:validation:Required
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open, e.g. 4h.
+	// +kubebuilder:validation:Required
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA time zone of Schedule, e.g. Europe/Paris. Defaults to UTC.
+	// +kubebuilder:validation:Optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+const (
+	// DriftPolicyCorrect reverts the drifted fields to the rendered chart.
+	DriftPolicyCorrect = "correct"
+	// DriftPolicyReportOnly reports the drifted fields without changing them.
+	DriftPolicyReportOnly = "report-only"
+	// DriftPolicyIgnore does not detect drift.
+	DriftPolicyIgnore = "ignore"
+)
+
+// SpecialResourceDriftSpec describes how the drift of the objects of the chart, changes
+// of the fields set by the chart made to the live objects, is detected and handled.
+type SpecialResourceDriftSpec struct {
+	// Policy is correct, report-only or ignore. Defaults to ignore.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=correct;report-only;ignore
+	Policy string `json:"policy,omitempty"`
+
+	// Interval is how often the live objects are compared to the chart. Defaults to 5m.
+	// +kubebuilder:validation:Optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+const (
+	// UpgradeStrategyRollingUpdate leaves the update of the driver Pods to their DaemonSet.
+	UpgradeStrategyRollingUpdate = "RollingUpdate"
+	// UpgradeStrategyOnDelete has the operator upgrade the nodes one after the other.
+	UpgradeStrategyOnDelete = "OnDelete"
+)
+
+// SpecialResourceUpgradeStrategy describes how the driver DaemonSets, the DaemonSets annotated
+// with specialresource.openshift.io/state: driver-container, are upgraded.
+type SpecialResourceUpgradeStrategy struct {
+	// Type is RollingUpdate to let the DaemonSets replace their Pods, or OnDelete to have the
+	// operator cordon and drain every node before the driver Pod on it is replaced.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=RollingUpdate;OnDelete
+	// +kubebuilder:default=RollingUpdate
+	Type string `json:"type,omitempty"`
+
+	// MaxUnavailable is the number, or percentage, of nodes upgraded at the same time with
+	// the OnDelete strategy. Defaults to 1.
+	// +kubebuilder:validation:Optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// Drain evicts the consumers of the driver from the node before the driver Pod is
+	// replaced. Nodes are only cordoned if not set.
+	// +kubebuilder:validation:Optional
+	Drain *SpecialResourceDrainSpec `json:"drain,omitempty"`
+}
+
+// SpecialResourceDrainSpec selects the Pods evicted from a node before its driver Pod is replaced.
+type SpecialResourceDrainSpec struct {
+	// PodSelector selects the Pods to evict. Pods managed by a DaemonSet and mirror Pods are
+	// never evicted, all the other Pods of the node are evicted if not set.
+	// +kubebuilder:validation:Optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// Timeout is how long the Pods may take to be evicted, e.g. because of PodDisruptionBudgets,
+	// before the upgrade of the node fails. Defaults to 5m.
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Force deletes the Pods not evicted within Timeout instead of failing the upgrade of the node.
+	// +kubebuilder:validation:Optional
+	Force bool `json:"force,omitempty"`
+
+	// DeleteEmptyDirData evicts Pods using emptyDir volumes, their data is lost. Such Pods are
+	// not evicted otherwise, the upgrade of the node fails if they still run after Timeout.
+	// +kubebuilder:validation:Optional
+	DeleteEmptyDirData bool `json:"deleteEmptyDirData,omitempty"`
+}
+
+// SpecialResourceImagePolicy describes how the container images of every rendered pod spec
+// are resolved before the objects are created.
+type SpecialResourceImagePolicy struct {
+	// RegistryRewrites replace the registry or repository prefix of the images, the first
+	// matching rewrite applies.
+	// +kubebuilder:validation:Optional
+	RegistryRewrites []SpecialResourceRegistryRewrite `json:"registryRewrites,omitempty"`
+
+	// PinDigests replaces the tags of the images with the digests they currently resolve to.
+	// Images not found in their registry, e.g. driver containers yet to be built, keep their tag.
+	// +kubebuilder:validation:Optional
+	PinDigests bool `json:"pinDigests,omitempty"`
+
+	// AllowedRegistries are the registries or repository prefixes the resolved images must
+	// belong to. Objects with other images are not created. All registries are allowed if empty.
+	// +kubebuilder:validation:Optional
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+}
+
+// SpecialResourceRegistryRewrite replaces the registry or repository prefix From of an image with To.
+type SpecialResourceRegistryRewrite struct {
+	// From is a registry, e.g. quay.io, or a repository prefix, e.g. quay.io/vendor.
+	// +kubebuilder:validation:Required
+	From string `json:"from"`
+
+	// To replaces From, e.g. mirror.corp/quay.
+	// +kubebuilder:validation:Required
+	To string `json:"to"`
+}
+
+// SpecialResourcePostRender describes the changes applied to every object rendered from the
+// chart, for the states and the rest of the chart alike. Patches are applied in order, the
+// image overrides after them.
+type SpecialResourcePostRender struct {
+	// Patches are strategic merge or JSON (RFC 6902) patches of the rendered objects.
+	// +kubebuilder:validation:Optional
+	Patches []SpecialResourcePatch `json:"patches,omitempty"`
+
+	// Images overrides the container images of the rendered objects.
+	// +kubebuilder:validation:Optional
+	Images []SpecialResourceImageOverride `json:"images,omitempty"`
+}
+
+const (
+	PatchTypeStrategicMerge = "StrategicMerge"
+	PatchTypeJSON6902       = "JSON6902"
+)
+
+// SpecialResourcePatch is a patch of the rendered objects selected by Target.
+type SpecialResourcePatch struct {
+	// Type of the patch, StrategicMerge or JSON6902. Objects whose kind has no strategic
+	// merge schema, e.g. custom resources, are patched with a JSON merge patch.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=StrategicMerge;JSON6902
+	// +kubebuilder:default:="StrategicMerge"
+	Type string `json:"type,omitempty"`
+
+	// Patch is the YAML or JSON patch document.
+	// +kubebuilder:validation:Required
+	Patch string `json:"patch"`
+
+	// Target selects the objects to patch. Without a target, a strategic merge patch applies to
+	// the objects of its own kind and name, a JSON6902 patch requires a target.
+	// +kubebuilder:validation:Optional
+	Target *SpecialResourcePatchTarget `json:"target,omitempty"`
+}
+
+// SpecialResourcePatchTarget selects rendered objects, empty fields match every object.
+type SpecialResourcePatchTarget struct {
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Kind string `json:"kind,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// LabelSelector is a label selector of the objects, e.g. app=driver-container.
+	// +kubebuilder:validation:Optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// SpecialResourceImageOverride replaces the name, tag or digest of the container images named Name.
+type SpecialResourceImageOverride struct {
+	// Name of the image as rendered by the chart, without tag or digest.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// NewName replaces the name of the image.
+	// +kubebuilder:validation:Optional
+	NewName string `json:"newName,omitempty"`
+
+	// NewTag replaces the tag of the image.
+	// +kubebuilder:validation:Optional
+	NewTag string `json:"newTag,omitempty"`
+
+	// Digest pins the image to a digest, it takes precedence over NewTag.
+	// +kubebuilder:validation:Optional
+	Digest string `json:"digest,omitempty"`
+}
+
+// SpecialResourceSecretKeyRef selects a key of a Secret in the SpecialResource's namespace.
+type SpecialResourceSecretKeyRef struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Key of the Secret's data holding the file.
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+}
+
+// SpecialResourceSigning describes how the kernel modules of the driver container are signed.
+// The operator never reads the referenced Secrets, they are only mounted into the signing build.
+type SpecialResourceSigning struct {
+	// KeySecretRef references the private key used to sign the kernel modules.
+	// +kubebuilder:validation:Required
+	KeySecretRef SpecialResourceSecretKeyRef `json:"keySecretRef"`
+
+	// CertSecretRef references the public certificate (DER) enrolled on the nodes.
+	// +kubebuilder:validation:Required
+	CertSecretRef SpecialResourceSecretKeyRef `json:"certSecretRef"`
+
+	// TagSuffix is appended to the tag of the driver container image to name the signed image.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="-signed"
+	TagSuffix string `json:"tagSuffix,omitempty"`
+
+	// ModulesPath is the directory of the driver container image holding the kernel modules.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="/opt/lib/modules"
+	ModulesPath string `json:"modulesPath,omitempty"`
 }
 
 // SpecialResourceDependency is a Helm chart the SpecialResource depends on.
@@ -143,10 +423,223 @@ type SpecialResourceDependency struct {
 type SpecialResourceStatus struct {
 	// State describes at which step the chart installation is.
 	State string `json:"state"`
+
+	// DriverImages records, for every driver container image checked in its registry,
+	// whether the build objects of its state were run.
+	// +kubebuilder:validation:Optional
+	DriverImages []DriverImageStatus `json:"driverImages,omitempty"`
+
+	// Images records the container images of the rendered objects as resolved by the image policy.
+	// +kubebuilder:validation:Optional
+	Images []ResolvedImageStatus `json:"images,omitempty"`
+
+	// ObservedRolloutToken is the last RolloutToken all the states were reconciled with.
+	// +kubebuilder:validation:Optional
+	ObservedRolloutToken string `json:"observedRolloutToken,omitempty"`
+
+	// NodeUpgrades records the progress of the nodes upgraded with the OnDelete upgrade strategy.
+	// +kubebuilder:validation:Optional
+	NodeUpgrades []NodeUpgradeStatus `json:"nodeUpgrades,omitempty"`
+
+	// PendingChanges are the changes deferred to the next maintenance window.
+	// +kubebuilder:validation:Optional
+	PendingChanges []PendingChangeStatus `json:"pendingChanges,omitempty"`
+
+	// Drift are the objects whose live fields differ from the rendered chart.
+	// +kubebuilder:validation:Optional
+	Drift []DriftStatus `json:"drift,omitempty"`
+
+	// NextMaintenanceWindow is the time the next maintenance window opens, if changes are pending.
+	// +kubebuilder:validation:Optional
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+
+	// Conditions are the latest observations of the SpecialResource's state.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// ConditionChartVerified is true if the chart's signature was verified, it is false and
+	// the chart is not installed if the verification failed.
+	ConditionChartVerified = "ChartVerified"
+
+	// ConditionValuesInvalid is true if the merged values of the chart violate the chart's
+	// values.schema.json or the schema of the runtime information, no state is reconciled then.
+	ConditionValuesInvalid = "ValuesInvalid"
+
+	// ConditionSuspended is true while the reconciliation of the SpecialResource is suspended,
+	// by itself or by one of its dependencies.
+	ConditionSuspended = "Suspended"
+
+	// ConditionDrifted is true if live objects differ from the rendered chart, see Status.Drift.
+	ConditionDrifted = "Drifted"
+
+	// ConditionDryRun is true while the SpecialResource is reconciled in dry-run mode, its
+	// message counts the objects that would change.
+	ConditionDryRun = "DryRun"
+)
+
+// PausedAnnotation set to "true" suspends the reconciliation of a SpecialResource like
+// Spec.Suspend, without changing its spec.
+const PausedAnnotation = "specialresource.openshift.io/paused"
+
+// IsSuspended returns true if the reconciliation of sr is suspended by its spec or by
+// the paused annotation.
+func (sr *SpecialResource) IsSuspended() bool {
+	return sr.Spec.Suspend || sr.GetAnnotations()[PausedAnnotation] == "true"
+}
+
+// DriverImageStatus is the result of checking a driver container image in its registry
+// before the DaemonSet using it is created.
+type DriverImageStatus struct {
+	// Image is the driver container image reference, by tag or digest.
+	Image string `json:"image"`
+
+	// State is the state the image was checked in.
+	State string `json:"state"`
+
+	// KernelFullVersion is the kernel version the image was built for.
+	// +kubebuilder:validation:Optional
+	KernelFullVersion string `json:"kernelFullVersion,omitempty"`
+
+	// Vendor is the driver container vendor of the DaemonSet using the image.
+	// +kubebuilder:validation:Optional
+	Vendor string `json:"vendor,omitempty"`
+
+	// Present is true if the image was found in the registry, or pulled by the Pods of the DaemonSet.
+	Present bool `json:"present"`
+
+	// Build is true if the build objects of the state were run to produce the image.
+	Build bool `json:"build"`
+
+	// MissingSince is the time the image was first found missing, the BuildRuns of its vendor
+	// that finished before are run again.
+	// +kubebuilder:validation:Optional
+	MissingSince metav1.Time `json:"missingSince,omitempty"`
+
+	// LastCheckTime is the last time the registry was queried for the image, or the Pods of the
+	// DaemonSet were checked for ImagePullBackOff.
+	// +kubebuilder:validation:Optional
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+const (
+	NodeUpgradeDraining   = "Draining"
+	NodeUpgradeRestarting = "Restarting"
+	NodeUpgradeDone       = "Done"
+	NodeUpgradeFailed     = "Failed"
+)
+
+// NodeUpgradeStatus is the progress of the upgrade of the driver Pod of a node.
+type NodeUpgradeStatus struct {
+	// Node is the name of the node.
+	Node string `json:"node"`
+
+	// DaemonSet is the namespaced name of the driver DaemonSet upgraded on the node.
+	DaemonSet string `json:"daemonSet"`
+
+	// Revision is the DaemonSet revision hash the node is upgraded to.
+	Revision string `json:"revision"`
+
+	// State is Draining, Restarting, Done or Failed.
+	State string `json:"state"`
+
+	// Message explains why the upgrade of the node failed.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// Cordoned is true if the node was cordoned by the operator, it is uncordoned once upgraded.
+	// +kubebuilder:validation:Optional
+	Cordoned bool `json:"cordoned,omitempty"`
+
+	// BlockedPods are the Pods not evicted from the node because they use emptyDir volumes,
+	// as namespace/name. The upgrade fails once the drain times out if they are still running.
+	// +kubebuilder:validation:Optional
+	BlockedPods []string `json:"blockedPods,omitempty"`
+
+	// StartTime is the time the upgrade of the node started.
+	StartTime metav1.Time `json:"startTime"`
+
+	// LastTransitionTime is the last time State changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+const (
+	// PendingDaemonSetUpdate is the update of a driver DaemonSet.
+	PendingDaemonSetUpdate = "DaemonSetUpdate"
+	// PendingRebuild is the rebuild of the driver containers for a new RolloutToken.
+	PendingRebuild = "Rebuild"
+)
+
+// PendingChangeStatus is a change deferred to the next maintenance window.
+type PendingChangeStatus struct {
+	// Kind of the object the change applies to.
+	Kind string `json:"kind"`
+
+	// Namespace of the object.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	Name string `json:"name"`
+
+	// Change is DaemonSetUpdate or Rebuild.
+	Change string `json:"change"`
+
+	// Since is the first time the change was deferred.
+	Since metav1.Time `json:"since"`
+}
+
+// DriftStatus is an object whose live fields differ from the rendered chart.
+type DriftStatus struct {
+	// Kind of the object.
+	Kind string `json:"kind"`
+
+	// Namespace of the object.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	Name string `json:"name"`
+
+	// Fields are the paths of the drifted fields, e.g. spec.template.spec.containers[0].image.
+	Fields []string `json:"fields"`
+
+	// Corrected is true if the fields were reverted to the rendered chart.
+	// +kubebuilder:validation:Optional
+	Corrected bool `json:"corrected,omitempty"`
+
+	// DetectedTime is the first time the drift was detected.
+	DetectedTime metav1.Time `json:"detectedTime"`
+}
+
+// ResolvedImageStatus is the image of a container of a rendered object after the image policy was applied.
+type ResolvedImageStatus struct {
+	// Kind of the object.
+	Kind string `json:"kind"`
+
+	// Namespace of the object.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	Name string `json:"name"`
+
+	// Container is the name of the container.
+	Container string `json:"container"`
+
+	// Image is the image rendered by the chart.
+	Image string `json:"image"`
+
+	// Resolved is the image the container is created with.
+	Resolved string `json:"resolved"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 
 // SpecialResource describes a software stack for hardware accelerators on an existing Kubernetes cluster.
 // +kubebuilder:resource:path=specialresources,scope=Cluster
@@ -6,16 +6,109 @@
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftStatus) DeepCopyInto(out *DriftStatus) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.DetectedTime.DeepCopyInto(&out.DetectedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftStatus.
+func (in *DriftStatus) DeepCopy() *DriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriverImageStatus) DeepCopyInto(out *DriverImageStatus) {
+	*out = *in
+	in.MissingSince.DeepCopyInto(&out.MissingSince)
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverImageStatus.
+func (in *DriverImageStatus) DeepCopy() *DriverImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriverImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeUpgradeStatus) DeepCopyInto(out *NodeUpgradeStatus) {
+	*out = *in
+	if in.BlockedPods != nil {
+		in, out := &in.BlockedPods, &out.BlockedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeUpgradeStatus.
+func (in *NodeUpgradeStatus) DeepCopy() *NodeUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingChangeStatus) DeepCopyInto(out *PendingChangeStatus) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingChangeStatus.
+func (in *PendingChangeStatus) DeepCopy() *PendingChangeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingChangeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedImageStatus) DeepCopyInto(out *ResolvedImageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedImageStatus.
+func (in *ResolvedImageStatus) DeepCopy() *ResolvedImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SpecialResource) DeepCopyInto(out *SpecialResource) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResource.
@@ -135,10 +228,55 @@ func (in *SpecialResourceDependency) DeepCopy() *SpecialResourceDependency {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourceDrainSpec) DeepCopyInto(out *SpecialResourceDrainSpec) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceDrainSpec.
+func (in *SpecialResourceDrainSpec) DeepCopy() *SpecialResourceDrainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourceDrainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourceDriftSpec) DeepCopyInto(out *SpecialResourceDriftSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceDriftSpec.
+func (in *SpecialResourceDriftSpec) DeepCopy() *SpecialResourceDriftSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourceDriftSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SpecialResourceDriverContainer) DeepCopyInto(out *SpecialResourceDriverContainer) {
 	*out = *in
-	out.Source = in.Source
+	in.Source.DeepCopyInto(&out.Source)
 	in.Artifacts.DeepCopyInto(&out.Artifacts)
 }
 
@@ -153,16 +291,41 @@ func (in *SpecialResourceDriverContainer) DeepCopy() *SpecialResourceDriverConta
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SpecialResourceGit) DeepCopyInto(out *SpecialResourceGit) {
+func (in *SpecialResourceImageOverride) DeepCopyInto(out *SpecialResourceImageOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceImageOverride.
+func (in *SpecialResourceImageOverride) DeepCopy() *SpecialResourceImageOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourceImageOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourceImagePolicy) DeepCopyInto(out *SpecialResourceImagePolicy) {
 	*out = *in
+	if in.RegistryRewrites != nil {
+		in, out := &in.RegistryRewrites, &out.RegistryRewrites
+		*out = make([]SpecialResourceRegistryRewrite, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceGit.
-func (in *SpecialResourceGit) DeepCopy() *SpecialResourceGit {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceImagePolicy.
+func (in *SpecialResourceImagePolicy) DeepCopy() *SpecialResourceImagePolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(SpecialResourceGit)
+	out := new(SpecialResourceImagePolicy)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -219,6 +382,57 @@ func (in *SpecialResourceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourceMaintenanceWindow) DeepCopyInto(out *SpecialResourceMaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceMaintenanceWindow.
+func (in *SpecialResourceMaintenanceWindow) DeepCopy() *SpecialResourceMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourceMaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourcePatch) DeepCopyInto(out *SpecialResourcePatch) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(SpecialResourcePatchTarget)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourcePatch.
+func (in *SpecialResourcePatch) DeepCopy() *SpecialResourcePatch {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourcePatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourcePatchTarget) DeepCopyInto(out *SpecialResourcePatchTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourcePatchTarget.
+func (in *SpecialResourcePatchTarget) DeepCopy() *SpecialResourcePatchTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourcePatchTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SpecialResourcePaths) DeepCopyInto(out *SpecialResourcePaths) {
 	*out = *in
@@ -234,10 +448,84 @@ func (in *SpecialResourcePaths) DeepCopy() *SpecialResourcePaths {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourcePostRender) DeepCopyInto(out *SpecialResourcePostRender) {
+	*out = *in
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]SpecialResourcePatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]SpecialResourceImageOverride, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourcePostRender.
+func (in *SpecialResourcePostRender) DeepCopy() *SpecialResourcePostRender {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourcePostRender)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourceRegistryRewrite) DeepCopyInto(out *SpecialResourceRegistryRewrite) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceRegistryRewrite.
+func (in *SpecialResourceRegistryRewrite) DeepCopy() *SpecialResourceRegistryRewrite {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourceRegistryRewrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourceSecretKeyRef) DeepCopyInto(out *SpecialResourceSecretKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceSecretKeyRef.
+func (in *SpecialResourceSecretKeyRef) DeepCopy() *SpecialResourceSecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourceSecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourceSigning) DeepCopyInto(out *SpecialResourceSigning) {
+	*out = *in
+	out.KeySecretRef = in.KeySecretRef
+	out.CertSecretRef = in.CertSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceSigning.
+func (in *SpecialResourceSigning) DeepCopy() *SpecialResourceSigning {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourceSigning)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SpecialResourceSource) DeepCopyInto(out *SpecialResourceSource) {
 	*out = *in
-	out.Git = in.Git
+	in.Git.DeepCopyInto(&out.Git)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceSource.
@@ -270,6 +558,36 @@ func (in *SpecialResourceSpec) DeepCopyInto(out *SpecialResourceSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Signing != nil {
+		in, out := &in.Signing, &out.Signing
+		*out = new(SpecialResourceSigning)
+		**out = **in
+	}
+	if in.PostRender != nil {
+		in, out := &in.PostRender, &out.PostRender
+		*out = new(SpecialResourcePostRender)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePolicy != nil {
+		in, out := &in.ImagePolicy, &out.ImagePolicy
+		*out = new(SpecialResourceImagePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradeStrategy != nil {
+		in, out := &in.UpgradeStrategy, &out.UpgradeStrategy
+		*out = new(SpecialResourceUpgradeStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]SpecialResourceMaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(SpecialResourceDriftSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceSpec.
@@ -285,6 +603,50 @@ func (in *SpecialResourceSpec) DeepCopy() *SpecialResourceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SpecialResourceStatus) DeepCopyInto(out *SpecialResourceStatus) {
 	*out = *in
+	if in.DriverImages != nil {
+		in, out := &in.DriverImages, &out.DriverImages
+		*out = make([]DriverImageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]ResolvedImageStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeUpgrades != nil {
+		in, out := &in.NodeUpgrades, &out.NodeUpgrades
+		*out = make([]NodeUpgradeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingChanges != nil {
+		in, out := &in.PendingChanges, &out.PendingChanges
+		*out = make([]PendingChangeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]DriftStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextMaintenanceWindow != nil {
+		in, out := &in.NextMaintenanceWindow, &out.NextMaintenanceWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceStatus.
@@ -296,3 +658,28 @@ func (in *SpecialResourceStatus) DeepCopy() *SpecialResourceStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialResourceUpgradeStrategy) DeepCopyInto(out *SpecialResourceUpgradeStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(SpecialResourceDrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialResourceUpgradeStrategy.
+func (in *SpecialResourceUpgradeStrategy) DeepCopy() *SpecialResourceUpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialResourceUpgradeStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -1,12 +1,23 @@
 package cli
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"strings"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
 )
 
 type CommandLine struct {
-	EnableLeaderElection bool
-	MetricsAddr          string
+	ChartVerifyProvider    string
+	ChartVerifySecret      string
+	EnableLeaderElection   bool
+	EnableWebhooks         bool
+	ImageAllowedRegistries string
+	ImagePinDigests        bool
+	ImageRegistryRewrites  string
+	MetricsAddr            string
 }
 
 func ParseCommandLine(programName string, args []string) (*CommandLine, error) {
@@ -18,6 +29,96 @@ func ParseCommandLine(programName string, args []string) (*CommandLine, error) {
 	fs.BoolVar(&cl.EnableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	fs.BoolVar(&cl.EnableWebhooks, "enable-webhooks", false,
+		"Serve the admission webhooks validating SpecialResources. "+
+			"Requires a serving certificate in the webhook server's certificate directory.")
+	fs.StringVar(&cl.ChartVerifySecret, "chart-verify-secret", "",
+		"The Secret in the operator namespace holding the keys that charts are verified against, "+
+			"unless they set their own verification policy. Charts are not verified if empty.")
+	fs.StringVar(&cl.ChartVerifyProvider, "chart-verify-provider", "helm",
+		"The provider used to verify charts against the keys of --chart-verify-secret, helm or cosign.")
+	fs.StringVar(&cl.ImageRegistryRewrites, "image-registry-rewrites", "",
+		"Comma-separated FROM=TO rewrites of the registries or repository prefixes of the images "+
+			"of every SpecialResource, e.g. quay.io=mirror.corp/quay.")
+	fs.BoolVar(&cl.ImagePinDigests, "image-pin-digests", false,
+		"Pin the images of every SpecialResource to the digests their tags resolve to.")
+	fs.StringVar(&cl.ImageAllowedRegistries, "image-allowed-registries", "",
+		"Comma-separated registries or repository prefixes the images of every SpecialResource "+
+			"must belong to. All registries are allowed if empty.")
 
 	return &cl, fs.Parse(args)
 }
+
+// RenderCommand is the subcommand rendering a chart offline instead of running the operator.
+const RenderCommand = "render"
+
+type RenderCommandLine struct {
+	Chart           string
+	Cluster         string
+	Output          string
+	SpecialResource string
+}
+
+// ParseRenderCommandLine parses the arguments following the render subcommand.
+func ParseRenderCommandLine(programName string, args []string) (*RenderCommandLine, error) {
+	cl := RenderCommandLine{}
+
+	fs := flag.NewFlagSet(programName+" "+RenderCommand, flag.ContinueOnError)
+
+	fs.StringVar(&cl.SpecialResource, "specialresource", "",
+		"The YAML file of the SpecialResource whose chart is rendered.")
+	fs.StringVar(&cl.Chart, "chart", "",
+		"The directory of the chart, instead of the chart the SpecialResource refers to.")
+	fs.StringVar(&cl.Cluster, "cluster", "",
+		"The YAML file describing the nodes, kernels, operating systems, driver toolkit images "+
+			"and proxy of the simulated cluster.")
+	fs.StringVar(&cl.Output, "output", "-",
+		"The file the manifests are written to, - for the standard output.")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	switch "" {
+	case cl.SpecialResource:
+		return nil, errors.New("--specialresource is required")
+	case cl.Chart:
+		return nil, errors.New("--chart is required")
+	case cl.Cluster:
+		return nil, errors.New("--cluster is required")
+	}
+
+	return &cl, nil
+}
+
+// ImagePolicy returns the operator-wide image policy set by the command line, or nil.
+func (cl *CommandLine) ImagePolicy() (*srov1beta1.SpecialResourceImagePolicy, error) {
+
+	policy := &srov1beta1.SpecialResourceImagePolicy{PinDigests: cl.ImagePinDigests}
+
+	for _, rw := range split(cl.ImageRegistryRewrites) {
+		parts := strings.SplitN(rw, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid registry rewrite %q, should be FROM=TO", rw)
+		}
+		policy.RegistryRewrites = append(policy.RegistryRewrites, srov1beta1.SpecialResourceRegistryRewrite{From: parts[0], To: parts[1]})
+	}
+
+	policy.AllowedRegistries = split(cl.ImageAllowedRegistries)
+
+	if !policy.PinDigests && len(policy.RegistryRewrites) == 0 && len(policy.AllowedRegistries) == 0 {
+		return nil, nil
+	}
+
+	return policy, nil
+}
+
+func split(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
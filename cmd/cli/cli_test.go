@@ -5,6 +5,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/cmd/cli"
 )
 
@@ -19,20 +20,42 @@ var _ = Describe("Cli", func() {
 			cl, err := cli.ParseCommandLine("test", nil)
 			Expect(err).NotTo(HaveOccurred())
 
+			Expect(cl.ChartVerifyProvider).To(Equal("helm"))
+			Expect(cl.ChartVerifySecret).To(BeEmpty())
 			Expect(cl.EnableLeaderElection).To(BeFalse())
+			Expect(cl.EnableWebhooks).To(BeFalse())
+			Expect(cl.ImageAllowedRegistries).To(BeEmpty())
+			Expect(cl.ImagePinDigests).To(BeFalse())
+			Expect(cl.ImageRegistryRewrites).To(BeEmpty())
 			Expect(cl.MetricsAddr).To(Equal(":8080"))
+
+			policy, err := cl.ImagePolicy()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(BeNil())
 		})
 
 		It("should set all flags correctly", func() {
 			const metricsAddr = "1.2.3.4:5678"
 
 			expected := &cli.CommandLine{
-				EnableLeaderElection: true,
-				MetricsAddr:          metricsAddr,
+				ChartVerifyProvider:    "cosign",
+				ChartVerifySecret:      "chart-keys",
+				EnableLeaderElection:   true,
+				EnableWebhooks:         true,
+				ImageAllowedRegistries: "mirror.corp",
+				ImagePinDigests:        true,
+				ImageRegistryRewrites:  "quay.io=mirror.corp/quay",
+				MetricsAddr:            metricsAddr,
 			}
 
 			args := []string{
+				"--chart-verify-provider", "cosign",
+				"--chart-verify-secret", "chart-keys",
 				"--enable-leader-election",
+				"--enable-webhooks",
+				"--image-allowed-registries", "mirror.corp",
+				"--image-pin-digests",
+				"--image-registry-rewrites", "quay.io=mirror.corp/quay",
 				"--metrics-addr", metricsAddr,
 			}
 
@@ -42,4 +65,60 @@ var _ = Describe("Cli", func() {
 			Expect(cl).To(Equal(expected))
 		})
 	})
+
+	Context("ParseRenderCommandLine", func() {
+		It("should set all flags correctly", func() {
+			args := []string{
+				"--specialresource", "simple-kmod.yaml",
+				"--chart", "charts/example/simple-kmod-0.0.1",
+				"--cluster", "cluster.yaml",
+				"--output", "simple-kmod.golden.yaml",
+			}
+
+			cl, err := cli.ParseRenderCommandLine("test", args)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cl).To(Equal(&cli.RenderCommandLine{
+				Chart:           "charts/example/simple-kmod-0.0.1",
+				Cluster:         "cluster.yaml",
+				Output:          "simple-kmod.golden.yaml",
+				SpecialResource: "simple-kmod.yaml",
+			}))
+		})
+
+		It("should write to the standard output by default", func() {
+			cl, err := cli.ParseRenderCommandLine("test", []string{"--specialresource", "sr.yaml", "--chart", "chart", "--cluster", "cluster.yaml"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cl.Output).To(Equal("-"))
+		})
+
+		It("should return an error if a file is missing", func() {
+			_, err := cli.ParseRenderCommandLine("test", []string{"--specialresource", "sr.yaml", "--chart", "chart"})
+			Expect(err).To(MatchError("--cluster is required"))
+		})
+	})
+
+	Context("ImagePolicy", func() {
+		It("should parse the registry rewrites and allowed registries", func() {
+			cl := &cli.CommandLine{
+				ImageAllowedRegistries: "mirror.corp, registry.redhat.io",
+				ImageRegistryRewrites:  "quay.io=mirror.corp/quay,docker.io=mirror.corp/docker",
+			}
+
+			policy, err := cl.ImagePolicy()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(&srov1beta1.SpecialResourceImagePolicy{
+				RegistryRewrites: []srov1beta1.SpecialResourceRegistryRewrite{
+					{From: "quay.io", To: "mirror.corp/quay"},
+					{From: "docker.io", To: "mirror.corp/docker"},
+				},
+				AllowedRegistries: []string{"mirror.corp", "registry.redhat.io"},
+			}))
+		})
+
+		It("should return an error for an invalid rewrite", func() {
+			_, err := (&cli.CommandLine{ImageRegistryRewrites: "quay.io"}).ImagePolicy()
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/url"
 	"os"
+	"path"
 	"strings"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
 )
 
 func getLogger() *log.Logger {
@@ -25,9 +30,27 @@ func getLogger() *log.Logger {
 	return log.New(w, os.Getenv("HELM_PLUGIN_NAME"), log.LstdFlags)
 }
 
+var (
+	// ErrNotFound is wrapped by the errors of missing objects and elements
+	ErrNotFound = errors.New("not found")
+
+	// ErrForbidden is wrapped by the errors of objects the getter may not read
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrIntegrity is wrapped by the errors of charts not matching their index.yaml digest
+	ErrIntegrity = errors.New("integrity check failed")
+)
+
+// strictEnv enables the strict integrity checks when set to true: charts missing from
+// the index.yaml, or without a digest in it, are refused as well.
+const strictEnv = "HELM_CM_GETTER_STRICT"
+
 type ConfigMapGetter struct {
 	kubeClient kubernetes.Interface
 	logger     *log.Logger
+
+	// strict refuses the charts whose digest is not recorded in the index.yaml
+	strict bool
 }
 
 func NewConfigMapProvider(logger *log.Logger, kubeConfigPath string) (*ConfigMapGetter, error) {
@@ -44,8 +67,19 @@ func NewConfigMapProvider(logger *log.Logger, kubeConfigPath string) (*ConfigMap
 	return &ConfigMapGetter{kubeClient: clientSet, logger: logger}, nil
 }
 
+// Get returns the element of the ConfigMap (cm://) or Secret (secret://) named by u,
+// NAMESPACE/NAME/ELEMENT. The namespace defaults to the operator namespace when the
+// URL has no host, e.g. secret:///NAME/ELEMENT. Charts are returned only if their
+// digest matches the one recorded in the index.yaml of the same object, charts
+// without a recorded digest only if the getter is not strict.
 func (cmg *ConfigMapGetter) Get(ctx context.Context, u *url.URL) ([]byte, error) {
 	namespace := u.Host
+	if namespace == "" {
+		namespace = os.Getenv("OPERATOR_NAMESPACE")
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("%s: no namespace in the URL and OPERATOR_NAMESPACE is not set", u)
+	}
 
 	cmg.logger.Printf("Namespace: %v", namespace)
 
@@ -55,30 +89,146 @@ func (cmg *ConfigMapGetter) Get(ctx context.Context, u *url.URL) ([]byte, error)
 	// i.e. /cm-name/index.yaml (if Helm is trying to find the index file)
 	// len(pathElements) should be 3 because of the leading slash
 	if len(pathElements) != 3 {
-		return nil, fmt.Errorf("%s: invalid path, should be NAMESPACE/NAME/ELEMENT", u.Path)
+		return nil, fmt.Errorf("%s: invalid path, should be [NAMESPACE]/NAME/ELEMENT", u.Path)
 	}
 
 	resourceName := pathElements[1]
 
-	cmg.logger.Printf("CM name: %v", resourceName)
+	cmg.logger.Printf("Resource name: %v", resourceName)
 
 	elem := pathElements[2]
 
 	cmg.logger.Printf("Element: %v", elem)
 
-	cm, err := cmg.kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, resourceName, metav1.GetOptions{})
+	s, err := newStore(cmg.kubeClient, u.Scheme, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.get(ctx, resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if elem == indexKey {
+		return data[elem], nil
+	}
+
+	output, err := cmg.element(ctx, s, namespace, data, elem)
 	if err != nil {
-		return nil, fmt.Errorf("could not GET ConfigMap %s/%s: %v", namespace, resourceName, err)
+		return nil, fmt.Errorf("%s %s/%s: %w", s.kind(), namespace, resourceName, err)
+	}
+
+	// Provenance files are checked against the chart by Helm itself
+	if strings.HasSuffix(elem, ".prov") {
+		return output, nil
+	}
+
+	if err = verifyDigest(data[indexKey], elem, output, cmg.strict); err != nil {
+		return nil, fmt.Errorf("%s %s/%s: %w", s.kind(), namespace, resourceName, err)
+	}
+
+	return output, nil
+}
+
+// element returns elem from data, reassembling it when it was split across
+// several objects by push.
+func (cmg *ConfigMapGetter) element(ctx context.Context, s store, namespace string, data map[string][]byte, elem string) ([]byte, error) {
+
+	chunks, found := data[elem+chunksSuffix]
+	if !found {
+		output, found := data[elem]
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, elem)
+		}
+		return output, nil
+	}
+
+	var output []byte
+
+	for _, chunkName := range strings.Fields(string(chunks)) {
+		cmg.logger.Printf("Chunk: %v", chunkName)
+
+		chunk, err := s.get(ctx, chunkName)
+		if err != nil {
+			return nil, fmt.Errorf("could not get chunk of %s: %w", elem, err)
+		}
+
+		part, found := chunk[elem]
+		if !found {
+			return nil, fmt.Errorf("%w: %s in chunk %s %s/%s", ErrNotFound, elem, s.kind(), namespace, chunkName)
+		}
+
+		output = append(output, part...)
+	}
+
+	return output, nil
+}
+
+// verifyDigest checks data against the digest of the chart version whose URL is file in index.
+// A file that is not listed in index, or has no digest, passes unless strict is set,
+// e.g. the charts of hand-written indexes.
+func verifyDigest(index []byte, file string, data []byte, strict bool) error {
+
+	idx := &repo.IndexFile{}
+	if err := yaml.Unmarshal(index, idx); err != nil {
+		return fmt.Errorf("%w: could not parse %s: %v", ErrIntegrity, indexKey, err)
 	}
 
-	if elem == "index.yaml" {
-		return []byte(cm.Data[elem]), nil
+	for _, versions := range idx.Entries {
+		for _, cv := range versions {
+			for _, u := range cv.URLs {
+				if path.Base(u) != file {
+					continue
+				}
+
+				if cv.Digest == "" {
+					if !strict {
+						return nil
+					}
+					return fmt.Errorf("%w: %s has no digest in %s", ErrIntegrity, file, indexKey)
+				}
+
+				digest, err := provenance.Digest(bytes.NewReader(data))
+				if err != nil {
+					return err
+				}
+
+				if digest != cv.Digest {
+					return fmt.Errorf("%w: %s has digest %s, %s records %s", ErrIntegrity, file, digest, indexKey, cv.Digest)
+				}
+
+				return nil
+			}
+		}
+	}
+
+	if !strict {
+		return nil
 	}
 
-	return cm.BinaryData[elem], nil
+	return fmt.Errorf("%w: %s is not listed in %s", ErrIntegrity, file, indexKey)
+}
+
+// exitCode distinguishes the failures of Get for the callers of the plugin.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return 2
+	case errors.Is(err, ErrForbidden):
+		return 3
+	case errors.Is(err, ErrIntegrity):
+		return 4
+	}
+	return 1
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "push" {
+		push(os.Args[2:])
+		return
+	}
+
 	debugLogger := getLogger()
 
 	debugLogger.Print("Environment:")
@@ -105,9 +255,12 @@ func main() {
 		log.Fatalf("Could not create a ConfigMap getter: %v", err)
 	}
 
+	g.strict = os.Getenv(strictEnv) == "true"
+
 	output, err := g.Get(context.Background(), u)
 	if err != nil {
-		log.Fatalf("Could not get %s: %v", u, err)
+		log.Printf("Could not get %s: %v", u, err)
+		os.Exit(exitCode(err))
 	}
 
 	if _, err = os.Stdout.Write(output); err != nil {
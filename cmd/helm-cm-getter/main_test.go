@@ -1,17 +1,29 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"log"
 	"net/url"
+	"os"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
 )
 
 func TestHelmCmGetter(t *testing.T) {
@@ -32,13 +44,47 @@ var _ = Describe("HelmCmGetter", func() {
 
 		discardLogger := log.New(io.Discard, "", 0)
 
-		It("should return an error when the ConfigMap does not exist", func() {
+		// indexFor returns an index.yaml listing file with the digest of contents
+		indexFor := func(file string, contents []byte) string {
+			digest, err := provenance.Digest(bytes.NewReader(contents))
+			Expect(err).NotTo(HaveOccurred())
+
+			index := repo.NewIndexFile()
+			Expect(index.MustAdd(&chart.Metadata{APIVersion: "v2", Name: "chart", Version: "0.1.0"}, file, "", digest)).To(Succeed())
+
+			out, err := yaml.Marshal(index)
+			Expect(err).NotTo(HaveOccurred())
+			return string(out)
+		}
+
+		It("should return a not found error when the ConfigMap does not exist", func() {
 			cmg := ConfigMapGetter{
 				kubeClient: fake.NewSimpleClientset(),
 				logger:     discardLogger,
 			}
 
 			_, err := cmg.Get(ctx, parseURL("cm://some-ns/some-chart/index.yaml"))
+			Expect(err).To(MatchError(ErrNotFound))
+			Expect(exitCode(err)).To(Equal(2))
+		})
+
+		It("should return a forbidden error when the getter may not read the object", func() {
+			kubeClient := fake.NewSimpleClientset()
+			kubeClient.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, k8serrors.NewForbidden(corev1.Resource("secrets"), "charts", errors.New("denied"))
+			})
+
+			cmg := ConfigMapGetter{kubeClient: kubeClient, logger: discardLogger}
+
+			_, err := cmg.Get(ctx, parseURL("secret://ns/charts/index.yaml"))
+			Expect(err).To(MatchError(ErrForbidden))
+			Expect(exitCode(err)).To(Equal(3))
+		})
+
+		It("should return an error for an unknown scheme", func() {
+			cmg := ConfigMapGetter{kubeClient: fake.NewSimpleClientset(), logger: discardLogger}
+
+			_, err := cmg.Get(ctx, parseURL("http://ns/charts/index.yaml"))
 			Expect(err).To(HaveOccurred())
 		})
 
@@ -85,6 +131,7 @@ var _ = Describe("HelmCmGetter", func() {
 				contents := []byte("test data")
 
 				cm := baseCM()
+				cm.Data = map[string]string{"index.yaml": indexFor("chart.tgz", contents)}
 				cm.BinaryData = map[string][]byte{"chart.tgz": contents}
 
 				cmg := ConfigMapGetter{
@@ -96,6 +143,317 @@ var _ = Describe("HelmCmGetter", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(b).To(Equal(contents))
 			})
+
+			It("should reassemble a chart split across chunk ConfigMaps", func() {
+				cm := baseCM()
+				cm.Data = map[string]string{
+					"chart.tgz.chunks": "chunk-0\nchunk-1",
+					"index.yaml":       indexFor("chart.tgz", []byte("test data")),
+				}
+
+				chunk := func(name, contents string) *corev1.ConfigMap {
+					return &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+						BinaryData: map[string][]byte{"chart.tgz": []byte(contents)},
+					}
+				}
+
+				cmg := ConfigMapGetter{
+					kubeClient: fake.NewSimpleClientset(cm, chunk("chunk-0", "test "), chunk("chunk-1", "data")),
+					logger:     discardLogger,
+				}
+
+				b, err := cmg.Get(ctx, parseURL("cm://ns/cm/chart.tgz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(b).To(Equal([]byte("test data")))
+			})
+
+			It("should return an error when a chunk ConfigMap is missing", func() {
+				cm := baseCM()
+				cm.Data = map[string]string{"chart.tgz.chunks": "chunk-0"}
+
+				cmg := ConfigMapGetter{
+					kubeClient: fake.NewSimpleClientset(cm),
+					logger:     discardLogger,
+				}
+
+				_, err := cmg.Get(ctx, parseURL("cm://ns/cm/chart.tgz"))
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should return a not found error when the chart is missing", func() {
+				cmg := ConfigMapGetter{
+					kubeClient: fake.NewSimpleClientset(baseCM()),
+					logger:     discardLogger,
+				}
+
+				_, err := cmg.Get(ctx, parseURL("cm://ns/cm/chart.tgz"))
+				Expect(err).To(MatchError(ErrNotFound))
+			})
+
+			It("should refuse a chart that does not match its digest", func() {
+				cm := baseCM()
+				cm.Data = map[string]string{"index.yaml": indexFor("chart.tgz", []byte("test data"))}
+				cm.BinaryData = map[string][]byte{"chart.tgz": []byte("tampered")}
+
+				cmg := ConfigMapGetter{
+					kubeClient: fake.NewSimpleClientset(cm),
+					logger:     discardLogger,
+				}
+
+				_, err := cmg.Get(ctx, parseURL("cm://ns/cm/chart.tgz"))
+				Expect(err).To(MatchError(ErrIntegrity))
+				Expect(exitCode(err)).To(Equal(4))
+			})
+
+			It("should return a chart without a recorded digest unless strict", func() {
+				cm := baseCM()
+				cm.Data = map[string]string{"index.yaml": `
+apiVersion: v1
+entries:
+  chart:
+  - name: chart
+    version: 0.1.0
+    urls:
+    - cm://ns/cm/chart.tgz
+`}
+				cm.BinaryData = map[string][]byte{"chart.tgz": []byte("test data")}
+
+				cmg := ConfigMapGetter{
+					kubeClient: fake.NewSimpleClientset(cm),
+					logger:     discardLogger,
+				}
+
+				b, err := cmg.Get(ctx, parseURL("cm://ns/cm/chart.tgz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(b).To(Equal([]byte("test data")))
+
+				cmg.strict = true
+
+				_, err = cmg.Get(ctx, parseURL("cm://ns/cm/chart.tgz"))
+				Expect(err).To(MatchError(ErrIntegrity))
+			})
+
+			It("should return a chart that is not listed in the index unless strict", func() {
+				cm := baseCM()
+				cm.BinaryData = map[string][]byte{"chart.tgz": []byte("test data")}
+
+				cmg := ConfigMapGetter{
+					kubeClient: fake.NewSimpleClientset(cm),
+					logger:     discardLogger,
+				}
+
+				b, err := cmg.Get(ctx, parseURL("cm://ns/cm/chart.tgz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(b).To(Equal([]byte("test data")))
+
+				cmg.strict = true
+
+				_, err = cmg.Get(ctx, parseURL("cm://ns/cm/chart.tgz"))
+				Expect(err).To(MatchError(ErrIntegrity))
+			})
+		})
+
+		When("Secret exists", func() {
+			contents := []byte("test data")
+
+			secret := func() *corev1.Secret {
+				return &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "charts", Namespace: "operator-ns"},
+					Data: map[string][]byte{
+						"index.yaml": []byte(indexFor("chart.tgz", contents)),
+						"chart.tgz":  contents,
+					},
+				}
+			}
+
+			It("should return the chart", func() {
+				cmg := ConfigMapGetter{kubeClient: fake.NewSimpleClientset(secret()), logger: discardLogger}
+
+				b, err := cmg.Get(ctx, parseURL("secret://operator-ns/charts/chart.tgz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(b).To(Equal(contents))
+			})
+
+			It("should default to the operator namespace", func() {
+				os.Setenv("OPERATOR_NAMESPACE", "operator-ns")
+				defer os.Unsetenv("OPERATOR_NAMESPACE")
+
+				cmg := ConfigMapGetter{kubeClient: fake.NewSimpleClientset(secret()), logger: discardLogger}
+
+				b, err := cmg.Get(ctx, parseURL("secret:///charts/chart.tgz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(b).To(Equal(contents))
+			})
+
+			It("should return an error without a namespace", func() {
+				os.Unsetenv("OPERATOR_NAMESPACE")
+
+				cmg := ConfigMapGetter{kubeClient: fake.NewSimpleClientset(secret()), logger: discardLogger}
+
+				_, err := cmg.Get(ctx, parseURL("secret:///charts/chart.tgz"))
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("ConfigMapPusher_Push", func() {
+		const (
+			chartDir = "../../pkg/helmer/testdata/test-chart"
+			chartTgz = "../../pkg/helmer/testdata/test-chart-0.1.0.tgz"
+			name     = "charts"
+			ns       = "ns"
+		)
+
+		ctx := context.Background()
+
+		discardLogger := log.New(io.Discard, "", 0)
+
+		parseURL := func(s string) *url.URL {
+			u, err := url.Parse(s)
+			Expect(err).NotTo(HaveOccurred())
+
+			return u
+		}
+
+		var kubeClient *fake.Clientset
+
+		BeforeEach(func() {
+			kubeClient = fake.NewSimpleClientset()
+		})
+
+		index := func() *repo.IndexFile {
+			cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			index := &repo.IndexFile{}
+			Expect(yaml.Unmarshal([]byte(cm.Data["index.yaml"]), index)).To(Succeed())
+			return index
+		}
+
+		get := func(file string) []byte {
+			cmg := ConfigMapGetter{kubeClient: kubeClient, logger: discardLogger}
+
+			b, err := cmg.Get(ctx, parseURL("cm://"+ns+"/"+name+"/"+file))
+			Expect(err).NotTo(HaveOccurred())
+			return b
+		}
+
+		// expectSourceChart checks that data is the archive of the chart in chartDir and
+		// that it matches the digest recorded in idx
+		expectSourceChart := func(data []byte, idx *repo.IndexFile) {
+			expected, err := loader.LoadDir(chartDir)
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+			ch, err := loader.LoadArchive(bytes.NewReader(data))
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			ExpectWithOffset(1, ch.Metadata).To(Equal(expected.Metadata))
+			ExpectWithOffset(1, ch.Values).To(Equal(expected.Values))
+			ExpectWithOffset(1, ch.Templates).To(Equal(expected.Templates))
+			ExpectWithOffset(1, ch.Files).To(Equal(expected.Files))
+
+			cv, err := idx.Get("test-chart", "0.1.0")
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+			digest, err := provenance.Digest(bytes.NewReader(data))
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			ExpectWithOffset(1, cv.Digest).To(Equal(digest))
+		}
+
+		It("should store a small chart and its index in one ConfigMap", func() {
+			p := ConfigMapPusher{kubeClient: kubeClient, logger: discardLogger, chunkSize: DefaultChunkSize}
+
+			Expect(p.Push(ctx, ns, name, []string{chartTgz})).To(Succeed())
+
+			cms, err := kubeClient.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cms.Items).To(HaveLen(1))
+
+			cv, err := index().Get("test-chart", "0.1.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cv.URLs).To(Equal([]string{"test-chart-0.1.0.tgz"}))
+
+			expected, err := os.ReadFile(chartTgz)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(get("test-chart-0.1.0.tgz")).To(Equal(expected))
+
+			digest, err := provenance.Digest(bytes.NewReader(expected))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cv.Digest).To(Equal(digest))
+		})
+
+		It("should package a chart directory and shard it across ConfigMaps", func() {
+			p := ConfigMapPusher{kubeClient: kubeClient, logger: discardLogger, chunkSize: 1024}
+
+			Expect(p.Push(ctx, ns, name, []string{chartDir})).To(Succeed())
+
+			data := get("test-chart-0.1.0.tgz")
+			expectSourceChart(data, index())
+
+			cms, err := kubeClient.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(cms.Items)).To(BeNumerically(">", 2))
+
+			// Pushing the chart again with larger chunks removes the chunks no longer used
+			p.chunkSize = len(data)
+			Expect(p.Push(ctx, ns, name, []string{chartDir})).To(Succeed())
+
+			cms, err = kubeClient.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cms.Items).To(HaveLen(2))
+
+			// Helm stamps the archive entries with the packaging time, the bytes differ
+			expectSourceChart(get("test-chart-0.1.0.tgz"), index())
+		})
+
+		It("should merge the index of the existing ConfigMap", func() {
+			p := ConfigMapPusher{kubeClient: kubeClient, logger: discardLogger, chunkSize: DefaultChunkSize}
+
+			Expect(p.Push(ctx, ns, name, []string{chartTgz})).To(Succeed())
+
+			ch, err := loader.LoadDir(chartDir)
+			Expect(err).NotTo(HaveOccurred())
+			ch.Metadata.Version = "0.2.0"
+
+			dir := GinkgoT().TempDir()
+			newer, err := chartutil.Save(ch, dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(p.Push(ctx, ns, name, []string{newer})).To(Succeed())
+
+			versions := index().Entries["test-chart"]
+			Expect(versions).To(HaveLen(2))
+			Expect(versions[0].Version).To(Equal("0.2.0"))
+			Expect(versions[1].Version).To(Equal("0.1.0"))
+		})
+
+		It("should store the repository in Secrets", func() {
+			p := ConfigMapPusher{kubeClient: kubeClient, logger: discardLogger, chunkSize: 1024, scheme: "secret"}
+
+			Expect(p.Push(ctx, ns, name, []string{chartDir})).To(Succeed())
+
+			cms, err := kubeClient.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cms.Items).To(BeEmpty())
+
+			cmg := ConfigMapGetter{kubeClient: kubeClient, logger: discardLogger}
+
+			data, err := cmg.Get(ctx, parseURL("secret://"+ns+"/"+name+"/test-chart-0.1.0.tgz"))
+			Expect(err).NotTo(HaveOccurred())
+
+			indexData, err := cmg.Get(ctx, parseURL("secret://"+ns+"/"+name+"/index.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+
+			idx := &repo.IndexFile{}
+			Expect(yaml.Unmarshal(indexData, idx)).To(Succeed())
+
+			expectSourceChart(data, idx)
+		})
+
+		It("should return an error for a path that is not a chart", func() {
+			p := ConfigMapPusher{kubeClient: kubeClient, logger: discardLogger, chunkSize: DefaultChunkSize}
+
+			Expect(p.Push(ctx, ns, name, []string{"main.go"})).NotTo(Succeed())
 		})
 	})
 })
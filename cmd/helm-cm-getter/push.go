@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	indexKey = "index.yaml"
+
+	// chunksSuffix is appended to the key of a chart stored across several objects,
+	// the value lists the objects holding the chunks in order, one per line.
+	chunksSuffix = ".chunks"
+
+	// DefaultChunkSize keeps ConfigMaps and Secrets below the 1 MiB limit of the API server
+	DefaultChunkSize = 900 * 1024
+
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedBy      = "helm-cm-getter"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+type ConfigMapPusher struct {
+	kubeClient kubernetes.Interface
+	logger     *log.Logger
+	chunkSize  int
+
+	// scheme selects the objects holding the repository, cm (default) or secret
+	scheme string
+}
+
+func NewConfigMapPusher(logger *log.Logger, kubeConfigPath string, chunkSize int, scheme string) (*ConfigMapPusher, error) {
+	kubeClient, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not create a Kubernetes client config: %v. Try setting the KUBECONFIG environment variable", err)
+	}
+
+	clientSet, err := kubernetes.NewForConfig(kubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("could not create a new ClientSet: %v", err)
+	}
+
+	return &ConfigMapPusher{kubeClient: clientSet, logger: logger, chunkSize: chunkSize, scheme: scheme}, nil
+}
+
+// packageChart returns the packaged chart at path, either a chart directory or a .tgz file.
+func packageChart(path string) (*chart.Chart, string, []byte, error) {
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if !fi.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		ch, err := loader.LoadArchive(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("%s is not a chart archive: %v", path, err)
+		}
+		return ch, filepath.Base(path), data, nil
+	}
+
+	ch, err := loader.LoadDir(path)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%s is not a chart directory: %v", path, err)
+	}
+
+	dir, err := os.MkdirTemp("", "helm-cm-getter-")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	archive, err := chartutil.Save(ch, dir)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("could not package %s: %v", path, err)
+	}
+
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return ch, filepath.Base(archive), data, nil
+}
+
+func chunkName(name, file string, i int) string {
+	return fmt.Sprintf("%s-%s-%d", name, invalidNameChars.ReplaceAllString(strings.ToLower(strings.TrimSuffix(file, ".tgz")), "-"), i)
+}
+
+func dataSize(data map[string][]byte) int {
+	size := 0
+	for k, v := range data {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// Push packages the charts at paths and adds them to the repository held by the
+// ConfigMap (or Secret) namespace/name, merging them into its index.yaml. A chart
+// that does not fit in the object is split across chunk objects, which Get reassembles.
+func (p *ConfigMapPusher) Push(ctx context.Context, namespace, name string, paths []string) error {
+
+	scheme := p.scheme
+	if scheme == "" {
+		scheme = "cm"
+	}
+
+	s, err := newStore(p.kubeClient, scheme, namespace)
+	if err != nil {
+		return err
+	}
+
+	data, err := s.get(ctx, name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	if data == nil {
+		data = make(map[string][]byte)
+	}
+
+	index := repo.NewIndexFile()
+	if len(data[indexKey]) > 0 {
+		if err = yaml.Unmarshal(data[indexKey], index); err != nil {
+			return fmt.Errorf("could not parse %s of %s %s/%s: %v", indexKey, s.kind(), namespace, name, err)
+		}
+	}
+
+	for _, path := range paths {
+		ch, file, archive, err := packageChart(path)
+		if err != nil {
+			return err
+		}
+
+		digest, err := provenance.Digest(bytes.NewReader(archive))
+		if err != nil {
+			return err
+		}
+
+		// Replace a previously pushed chart of the same version
+		delete(data, file)
+		stale := strings.Fields(string(data[file+chunksSuffix]))
+		delete(data, file+chunksSuffix)
+
+		var chunks []string
+		if dataSize(data)+len(file)+len(archive) <= p.chunkSize {
+			data[file] = archive
+		} else {
+			for i := 0; i*p.chunkSize < len(archive); i++ {
+				end := (i + 1) * p.chunkSize
+				if end > len(archive) {
+					end = len(archive)
+				}
+
+				chunk := chunkName(name, file, i)
+				labels := map[string]string{managedByLabel: managedBy}
+
+				if err = s.put(ctx, chunk, map[string][]byte{file: archive[i*p.chunkSize : end]}, labels); err != nil {
+					return err
+				}
+				chunks = append(chunks, chunk)
+			}
+			data[file+chunksSuffix] = []byte(strings.Join(chunks, "\n"))
+		}
+
+		for _, old := range stale[min(len(chunks), len(stale)):] {
+			if err = s.delete(ctx, old); err != nil {
+				return err
+			}
+		}
+
+		removeVersion(index, ch.Metadata.Name, ch.Metadata.Version)
+		if err = index.MustAdd(ch.Metadata, file, "", digest); err != nil {
+			return fmt.Errorf("could not add %s to %s: %v", file, indexKey, err)
+		}
+
+		p.logger.Printf("Pushed %s in %d chunk(s)", file, max(len(chunks), 1))
+	}
+
+	index.SortEntries()
+
+	out, err := yaml.Marshal(index)
+	if err != nil {
+		return err
+	}
+	data[indexKey] = out
+
+	return s.put(ctx, name, data, nil)
+}
+
+func removeVersion(index *repo.IndexFile, name, version string) {
+	versions := index.Entries[name]
+	for i, cv := range versions {
+		if cv.Version == version {
+			index.Entries[name] = append(versions[:i], versions[i+1:]...)
+			return
+		}
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// push implements the push command: helm-cm-getter push -namespace NS -name NAME CHART...
+func push(args []string) {
+
+	fs := flag.NewFlagSet("push", flag.ExitOnError)
+
+	namespace := fs.String("namespace", "", "The namespace of the repository ConfigMap.")
+	name := fs.String("name", "", "The name of the repository ConfigMap.")
+	chunkSize := fs.Int("chunk-size", DefaultChunkSize, "The maximum size in bytes of the charts stored in one ConfigMap.")
+	secret := fs.Bool("secret", false, "Store the repository in Secrets instead of ConfigMaps, for secret:// URLs.")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s push [-secret] -namespace NAMESPACE -name NAME CHART_DIR|CHART.tgz...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	if *namespace == "" || *name == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	scheme := "cm"
+	if *secret {
+		scheme = "secret"
+	}
+
+	p, err := NewConfigMapPusher(log.New(os.Stderr, "", log.LstdFlags), os.Getenv("KUBECONFIG"), *chunkSize, scheme)
+	if err != nil {
+		log.Fatalf("Could not create a ConfigMap pusher: %v", err)
+	}
+
+	if err = p.Push(context.Background(), *namespace, *name, fs.Args()); err != nil {
+		log.Fatalf("Could not push charts: %v", err)
+	}
+
+	fmt.Printf("Charts available at %s://%s/%s\n", scheme, *namespace, *name)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// store reads and writes the keys of the objects holding a repository,
+// ConfigMaps for cm:// and Secrets for secret://.
+type store interface {
+	kind() string
+	get(ctx context.Context, name string) (map[string][]byte, error)
+	put(ctx context.Context, name string, data map[string][]byte, labels map[string]string) error
+	delete(ctx context.Context, name string) error
+}
+
+func newStore(kubeClient kubernetes.Interface, scheme, namespace string) (store, error) {
+	switch scheme {
+	case "cm", "configmap":
+		return &configMapStore{kubeClient: kubeClient, namespace: namespace}, nil
+	case "secret":
+		return &secretStore{kubeClient: kubeClient, namespace: namespace}, nil
+	}
+	return nil, fmt.Errorf("unsupported scheme %q", scheme)
+}
+
+func apiError(err error, kind, namespace, name string) error {
+	switch {
+	case k8serrors.IsNotFound(err):
+		return fmt.Errorf("%w: %s %s/%s", ErrNotFound, kind, namespace, name)
+	case k8serrors.IsForbidden(err):
+		return fmt.Errorf("%w: %s %s/%s: %v", ErrForbidden, kind, namespace, name, err)
+	}
+	return fmt.Errorf("could not GET %s %s/%s: %v", kind, namespace, name, err)
+}
+
+func mergeLabels(existing, labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return existing
+	}
+	if existing == nil {
+		existing = make(map[string]string, len(labels))
+	}
+	for k, v := range labels {
+		existing[k] = v
+	}
+	return existing
+}
+
+type configMapStore struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+}
+
+func (s *configMapStore) kind() string { return "ConfigMap" }
+
+func (s *configMapStore) get(ctx context.Context, name string) (map[string][]byte, error) {
+
+	cm, err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, apiError(err, s.kind(), s.namespace, name)
+	}
+
+	data := make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+	for k, v := range cm.Data {
+		data[k] = []byte(v)
+	}
+	for k, v := range cm.BinaryData {
+		data[k] = v
+	}
+
+	return data, nil
+}
+
+// put stores the index and chunk lists as text and the charts as binary data.
+func (s *configMapStore) put(ctx context.Context, name string, data map[string][]byte, labels map[string]string) error {
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.namespace, Labels: labels},
+		Data:       make(map[string]string),
+		BinaryData: make(map[string][]byte),
+	}
+
+	for k, v := range data {
+		if k == indexKey || strings.HasSuffix(k, chunksSuffix) {
+			cm.Data[k] = string(v)
+		} else {
+			cm.BinaryData[k] = v
+		}
+	}
+
+	cms := s.kubeClient.CoreV1().ConfigMaps(s.namespace)
+
+	_, err := cms.Create(ctx, cm, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		var existing *corev1.ConfigMap
+		if existing, err = cms.Get(ctx, name, metav1.GetOptions{}); err == nil {
+			existing.Labels = mergeLabels(existing.Labels, labels)
+			existing.Data = cm.Data
+			existing.BinaryData = cm.BinaryData
+			_, err = cms.Update(ctx, existing, metav1.UpdateOptions{})
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not write ConfigMap %s/%s: %v", s.namespace, name, err)
+	}
+
+	return nil
+}
+
+func (s *configMapStore) delete(ctx context.Context, name string) error {
+	err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("could not DELETE ConfigMap %s/%s: %v", s.namespace, name, err)
+	}
+	return nil
+}
+
+type secretStore struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+}
+
+func (s *secretStore) kind() string { return "Secret" }
+
+func (s *secretStore) get(ctx context.Context, name string) (map[string][]byte, error) {
+
+	secret, err := s.kubeClient.CoreV1().Secrets(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, apiError(err, s.kind(), s.namespace, name)
+	}
+
+	return secret.Data, nil
+}
+
+func (s *secretStore) put(ctx context.Context, name string, data map[string][]byte, labels map[string]string) error {
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.namespace, Labels: labels},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       data,
+	}
+
+	secrets := s.kubeClient.CoreV1().Secrets(s.namespace)
+
+	_, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		var existing *corev1.Secret
+		if existing, err = secrets.Get(ctx, name, metav1.GetOptions{}); err == nil {
+			existing.Labels = mergeLabels(existing.Labels, labels)
+			existing.Data = data
+			_, err = secrets.Update(ctx, existing, metav1.UpdateOptions{})
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not write Secret %s/%s: %v", s.namespace, name, err)
+	}
+
+	return nil
+}
+
+func (s *secretStore) delete(ctx context.Context, name string) error {
+	err := s.kubeClient.CoreV1().Secrets(s.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("could not DELETE Secret %s/%s: %v", s.namespace, name, err)
+	}
+	return nil
+}
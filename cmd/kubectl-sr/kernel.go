@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift-psap/special-resource-operator/pkg/kernel"
+	"github.com/openshift-psap/special-resource-operator/pkg/upgrade"
+	v1 "k8s.io/api/core/v1"
+)
+
+// affineKernel is a kernel and the operating system version the kernel-affine objects
+// are rendered for.
+type affineKernel struct {
+	KernelFullVersion string
+	OSVersion         string
+}
+
+// nodeKernels returns the kernels of the nodes labeled by NFD, by node name.
+func nodeKernels(nodes *v1.NodeList) map[string]affineKernel {
+
+	kernels := make(map[string]affineKernel)
+
+	for _, node := range nodes.Items {
+		// The nodes not labeled by NFD have no kernel-affine objects
+		versions, err := upgrade.NodeVersions(&v1.NodeList{Items: []v1.Node{node}})
+		if err != nil {
+			continue
+		}
+		for kernelFullVersion, version := range versions {
+			kernels[node.Name] = affineKernel{KernelFullVersion: kernelFullVersion, OSVersion: version.OSVersion}
+		}
+	}
+
+	return kernels
+}
+
+// affineKernels returns the kernels by the suffix of the names of their kernel-affine
+// objects, as set by the operator.
+func affineKernels(byNode map[string]affineKernel) (map[string]affineKernel, error) {
+
+	kernels := make(map[string]affineKernel)
+
+	for _, k := range byNode {
+		suffix, err := kernel.AffineSuffix(k.KernelFullVersion, k.OSVersion)
+		if err != nil {
+			return nil, err
+		}
+		kernels[suffix] = k
+	}
+
+	return kernels, nil
+}
+
+// kernelOf returns the kernel the object name was renamed for, name may also be the
+// suffix alone.
+func kernelOf(kernels map[string]affineKernel, name string) (affineKernel, bool) {
+	k, found := kernels[name[strings.LastIndex(name, "-")+1:]]
+	return k, found
+}
+
+// kernel prints the kernel of the kernel-affine objects names, among the kernels of
+// the nodes of the cluster.
+func (p *plugin) kernel(ctx context.Context, names []string) error {
+
+	nodes := &v1.NodeList{}
+	if err := p.kubeClient.List(ctx, nodes); err != nil {
+		return fmt.Errorf("cannot list nodes: %w", err)
+	}
+
+	kernels, err := affineKernels(nodeKernels(nodes))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKERNEL\tOS")
+
+	for _, name := range names {
+		k, found := kernelOf(kernels, name)
+		if !found {
+			k = affineKernel{KernelFullVersion: "<unknown>", OSVersion: "<unknown>"}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, k.KernelFullVersion, k.OSVersion)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// driverPods returns the Pods on node of the DaemonSets of sr annotated with the state
+// stateName, sorted by name.
+func (p *plugin) driverPods(ctx context.Context, sr *srov1beta1.SpecialResource, node, stateName string) ([]v1.Pod, error) {
+
+	daemonSets, err := p.daemonSets(ctx, sr, stateName)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[types.UID]bool, len(daemonSets))
+	for _, ds := range daemonSets {
+		owners[ds.UID] = true
+	}
+
+	list := &v1.PodList{}
+	if err = p.kubeClient.List(ctx, list, client.InNamespace(namespace(sr))); err != nil {
+		return nil, fmt.Errorf("cannot list the Pods of SpecialResource %s: %w", sr.Name, err)
+	}
+
+	var pods []v1.Pod
+	for _, pod := range list.Items {
+		if owner := metav1.GetControllerOf(&pod); owner != nil && owners[owner.UID] && pod.Spec.NodeName == node {
+			pods = append(pods, pod)
+		}
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+
+	return pods, nil
+}
+
+// logs prints the logs of the driver Pods of the SpecialResource name on node.
+func (p *plugin) logs(ctx context.Context, name, node, stateName, container string, follow bool, tail int64) error {
+
+	sr, err := p.specialResource(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	pods, err := p.driverPods(ctx, sr, node, stateName)
+	if err != nil {
+		return err
+	}
+
+	if len(pods) == 0 {
+		return fmt.Errorf("SpecialResource %s has no %s Pod on node %s", name, stateName, node)
+	}
+
+	if follow && len(pods) > 1 {
+		return fmt.Errorf("SpecialResource %s has %d %s Pods on node %s, set -state to follow one", name, len(pods), stateName, node)
+	}
+
+	opts := &v1.PodLogOptions{Container: container, Follow: follow}
+	if tail >= 0 {
+		opts.TailLines = &tail
+	}
+
+	for _, pod := range pods {
+		if len(pods) > 1 {
+			fmt.Fprintf(p.out, "==> %s/%s <==\n", pod.Namespace, pod.Name)
+		}
+
+		stream, err := p.kubeClient.GetPodLogs(pod.Namespace, pod.Name, opts).Stream(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot get the logs of Pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+
+		_, err = io.Copy(p.out, stream)
+		stream.Close()
+		if err != nil {
+			return fmt.Errorf("cannot read the logs of Pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	return nil
+}
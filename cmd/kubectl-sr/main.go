@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	"github.com/openshift-psap/special-resource-operator/pkg/nodeupgrade"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(srov1beta1.AddToScheme(scheme))
+}
+
+const usage = `Usage: kubectl sr [--kubeconfig FILE] COMMAND [ARGS]
+
+Commands:
+  status [NAME]                 Summarise the states, kernels and nodes of the SpecialResources
+  kernel OBJECT...              Map the names of kernel-affine objects back to their kernel versions
+  logs NAME -node NODE          Print the logs of the driver Pods of NAME on NODE
+  rebuild NAME                  Rebuild and restart the drivers of NAME with a new rollout token
+  force-upgrade NAME [-node N]  Delete the outdated driver Pods of NAME without draining the nodes
+`
+
+// plugin runs the commands against the cluster with the clients of the operator.
+type plugin struct {
+	kubeClient   clients.ClientsInterface
+	nodeUpgrader nodeupgrade.NodeUpgrader
+	out          io.Writer
+}
+
+func newPlugin(kubeClient clients.ClientsInterface, out io.Writer) *plugin {
+	return &plugin{
+		kubeClient:   kubeClient,
+		nodeUpgrader: nodeupgrade.NewNodeUpgrader(kubeClient),
+		out:          out,
+	}
+}
+
+// run runs the command of args, the name of the command first.
+func (p *plugin) run(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return fmt.Errorf("no command given")
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(p.out)
+
+	switch args[0] {
+	case "status":
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return p.status(ctx, fs.Arg(0))
+
+	case "kernel":
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			return fmt.Errorf("kernel: no object name given")
+		}
+		return p.kernel(ctx, fs.Args())
+
+	case "logs":
+		node := fs.String("node", "", "The node running the driver Pods.")
+		follow := fs.Bool("follow", false, "Stream the logs of the driver Pod.")
+		tail := fs.Int64("tail", -1, "The number of lines to print from the end of the logs, all if negative.")
+		container := fs.String("container", "", "The container of the driver Pods, the first one if empty.")
+		state := fs.String("state", "driver-container", "The specialresource.openshift.io/state annotation of the DaemonSets of the driver Pods.")
+		name, err := parse(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if *node == "" {
+			return fmt.Errorf("logs: -node is required")
+		}
+		return p.logs(ctx, name, *node, *state, *container, *follow, *tail)
+
+	case "rebuild":
+		name, err := parse(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		return p.rebuild(ctx, name)
+
+	case "force-upgrade":
+		node := fs.String("node", "", "The node to upgrade, all the nodes running an outdated driver Pod if empty.")
+		name, err := parse(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		return p.forceUpgrade(ctx, name, *node)
+	}
+
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// parse parses the flags of a command taking the name of a SpecialResource and returns
+// the name, which may come before the flags like in kubectl.
+func parse(fs *flag.FlagSet, args []string) (string, error) {
+
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+
+	if name == "" && fs.NArg() == 1 {
+		return fs.Arg(0), nil
+	}
+
+	if name == "" || fs.NArg() > 0 {
+		return "", fmt.Errorf("%s: exactly one SpecialResource name is required", fs.Name())
+	}
+
+	return name, nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Honors --kubeconfig, KUBECONFIG and ~/.kube/config like kubectl
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Fatalf("Could not get a Kubernetes client config: %v", err)
+	}
+
+	runtimeClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		log.Fatalf("Could not create a Kubernetes client: %v", err)
+	}
+
+	kubeClient, err := clients.NewClients(runtimeClient, cfg, nil)
+	if err != nil {
+		log.Fatalf("Could not create the clients: %v", err)
+	}
+
+	if err = newPlugin(kubeClient, os.Stdout).run(context.Background(), flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestKubectlSr(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "KubectlSr Suite")
+}
+
+const (
+	kernel0 = "4.18.0-305.25.1.el8_4.x86_64"
+	kernel1 = "4.18.0-305.28.1.el8_4.x86_64"
+
+	// The name of the driver DaemonSet rendered by the operator for kernel0 on RHCOS 4.9
+	driver0 = "simple-kmod-driver-container-efb0a5d31af5b3fd"
+)
+
+func node(name, kernelFullVersion string, labels map[string]string) *v1.Node {
+	n := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+		"node-role.kubernetes.io/worker":                            "",
+		"feature.node.kubernetes.io/kernel-version.full":            kernelFullVersion,
+		"feature.node.kubernetes.io/system-os_release.ID":           "rhcos",
+		"feature.node.kubernetes.io/system-os_release.VERSION_ID":   "4.9",
+		"feature.node.kubernetes.io/system-os_release.RHEL_VERSION": "8.4",
+	}}}
+	for key, value := range labels {
+		n.Labels[key] = value
+	}
+	return n
+}
+
+var _ = Describe("parse", func() {
+	DescribeTable("should return the name of the SpecialResource",
+		func(args []string) {
+			fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+			node := fs.String("node", "", "")
+
+			name, err := parse(fs, args)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("simple-kmod"))
+			Expect(*node).To(Equal("worker-0"))
+		},
+		Entry("before the flags", []string{"simple-kmod", "-node", "worker-0"}),
+		Entry("after the flags", []string{"-node", "worker-0", "simple-kmod"}),
+	)
+
+	It("should require exactly one name", func() {
+		_, err := parse(flag.NewFlagSet("rebuild", flag.ContinueOnError), nil)
+		Expect(err).To(HaveOccurred())
+
+		_, err = parse(flag.NewFlagSet("rebuild", flag.ContinueOnError), []string{"a", "b"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("plugin", func() {
+	var (
+		kubeClient *clients.OfflineClients
+		out        *bytes.Buffer
+		p          *plugin
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		sr := &srov1beta1.SpecialResource{
+			ObjectMeta: metav1.ObjectMeta{Name: "simple-kmod", UID: "sr-uid"},
+			Spec: srov1beta1.SpecialResourceSpec{
+				Namespace:    "simple-kmod",
+				NodeSelector: map[string]string{"node-role.kubernetes.io/worker": ""},
+				Set:          unstructured.Unstructured{Object: map[string]interface{}{"kind": "Values", "apiVersion": "sro.openshift.io/v1beta1"}},
+			},
+			Status: srov1beta1.SpecialResourceStatus{
+				State:      "Ready",
+				Conditions: []metav1.Condition{{Type: srov1beta1.ConditionDrifted, Status: metav1.ConditionFalse}},
+			},
+		}
+		srRef := *metav1.NewControllerRef(sr, srov1beta1.GroupVersion.WithKind("SpecialResource"))
+
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      driver0,
+				Namespace: "simple-kmod",
+				UID:       "ds-uid",
+				Annotations: map[string]string{
+					"specialresource.openshift.io/state":         "driver-container",
+					"specialresource.openshift.io/kernel-affine": "true",
+				},
+				OwnerReferences: []metav1.OwnerReference{srRef},
+			},
+			Spec:   appsv1.DaemonSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": driver0}}},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 1, UpdatedNumberScheduled: 1},
+		}
+		dsRef := *metav1.NewControllerRef(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet"))
+
+		revision := &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            driver0 + "-new",
+				Namespace:       "simple-kmod",
+				Labels:          map[string]string{"app": driver0, appsv1.DefaultDaemonSetUniqueLabelKey: "new"},
+				OwnerReferences: []metav1.OwnerReference{dsRef},
+			},
+			Revision: 2,
+		}
+
+		pod := func(name, node, revision string) *v1.Pod {
+			return &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            name,
+					Namespace:       "simple-kmod",
+					Labels:          map[string]string{"app": driver0, appsv1.DefaultDaemonSetUniqueLabelKey: revision},
+					OwnerReferences: []metav1.OwnerReference{dsRef},
+				},
+				Spec: v1.PodSpec{NodeName: node},
+			}
+		}
+
+		kubeClient = clients.NewOfflineClients(scheme, "OCP",
+			sr,
+			ds,
+			revision,
+			pod("driver-a", "worker-0", "old"),
+			pod("driver-b", "worker-1", "new"),
+			node("worker-0", kernel0, map[string]string{
+				"specialresource.openshift.io/state-simple-kmod-0000": "Ready",
+				"specialresource.openshift.io/state-simple-kmod-1000": "Ready",
+				"specialresource.openshift.io/upgrade-simple-kmod":    "Failed",
+			}),
+			node("worker-1", kernel1, nil),
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master-0"}},
+		)
+
+		out = &bytes.Buffer{}
+		p = newPlugin(kubeClient, out)
+	})
+
+	It("should map kernel-affine names back to their kernel", func() {
+		Expect(p.run(ctx, []string{"kernel", driver0, "efb0a5d31af5b3fd", "simple-kmod-driver-build"})).To(Succeed())
+
+		Expect(out.String()).To(MatchRegexp(`(?m)^` + driver0 + ` +` + kernel0 + ` +8\.4$`))
+		Expect(out.String()).To(MatchRegexp(`(?m)^efb0a5d31af5b3fd +` + kernel0 + ` +8\.4$`))
+		Expect(out.String()).To(MatchRegexp(`(?m)^simple-kmod-driver-build +<unknown> +<unknown>$`))
+	})
+
+	It("should summarise the states, kernels and nodes", func() {
+		Expect(p.run(ctx, []string{"status"})).To(Succeed())
+
+		Expect(out.String()).To(MatchRegexp(`(?m)^Name: +simple-kmod$`))
+		Expect(out.String()).To(MatchRegexp(`(?m)^Conditions: +Drifted=False$`))
+		Expect(out.String()).To(MatchRegexp(`(?m)^` + driver0 + ` +driver-container +` + kernel0 + ` +8\.4 +1/2 +1$`))
+		Expect(out.String()).To(MatchRegexp(`(?m)^worker-0 +` + kernel0 + ` +0000,1000 +Failed$`))
+		Expect(out.String()).To(MatchRegexp(`(?m)^worker-1 +` + kernel1 + ` +- +-$`))
+		Expect(out.String()).NotTo(ContainSubstring("master-0"))
+	})
+
+	It("should find the driver Pods of a node", func() {
+		sr, err := p.specialResource(ctx, "simple-kmod")
+		Expect(err).NotTo(HaveOccurred())
+
+		pods, err := p.driverPods(ctx, sr, "worker-1", "driver-container")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods).To(HaveLen(1))
+		Expect(pods[0].Name).To(Equal("driver-b"))
+
+		Expect(p.run(ctx, []string{"logs", "simple-kmod", "-node", "master-0"})).To(MatchError(ContainSubstring("no driver-container Pod on node master-0")))
+	})
+
+	It("should set a new rollout token", func() {
+		Expect(p.run(ctx, []string{"rebuild", "simple-kmod"})).To(Succeed())
+
+		sr := &srov1beta1.SpecialResource{}
+		Expect(kubeClient.Get(ctx, types.NamespacedName{Name: "simple-kmod"}, sr)).To(Succeed())
+		Expect(sr.Spec.RolloutToken).NotTo(BeEmpty())
+		Expect(out.String()).To(Equal("specialresource/simple-kmod rollout token set to " + sr.Spec.RolloutToken + "\n"))
+	})
+
+	It("should delete the outdated driver Pods only", func() {
+		Expect(p.run(ctx, []string{"force-upgrade", "simple-kmod", "-node", "worker-1"})).To(Succeed())
+		Expect(out.String()).To(Equal("specialresource/simple-kmod has no outdated driver Pod\n"))
+
+		out.Reset()
+		Expect(p.run(ctx, []string{"force-upgrade", "simple-kmod"})).To(Succeed())
+		Expect(out.String()).To(Equal("pod/driver-a deleted on node worker-0\n"))
+
+		err := kubeClient.Get(ctx, client.ObjectKey{Namespace: "simple-kmod", Name: "driver-a"}, &v1.Pod{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(kubeClient.Get(ctx, client.ObjectKey{Namespace: "simple-kmod", Name: "driver-b"}, &v1.Pod{})).To(Succeed())
+	})
+
+	It("should reject an unknown command", func() {
+		Expect(p.run(ctx, []string{"delete"})).To(MatchError(`unknown command "delete"`))
+	})
+})
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"k8s.io/client-go/util/retry"
+)
+
+// rebuild sets a new rollout token on the SpecialResource name, the operator rebuilds
+// and restarts its drivers.
+func (p *plugin) rebuild(ctx context.Context, name string) error {
+
+	token := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sr, err := p.specialResource(ctx, name)
+		if err != nil {
+			return err
+		}
+		sr.Spec.RolloutToken = token
+		return p.kubeClient.Update(ctx, sr)
+	}); err != nil {
+		return fmt.Errorf("cannot set the rollout token of SpecialResource %s: %w", name, err)
+	}
+
+	fmt.Fprintf(p.out, "specialresource/%s rollout token set to %s\n", name, token)
+
+	return nil
+}
+
+// forceUpgrade deletes the driver Pods of the SpecialResource name that do not run the
+// latest revision of their DaemonSet, on node or on all the nodes if empty, so that
+// they are replaced right away. Unlike the upgrades of the operator the nodes are
+// neither cordoned nor drained.
+func (p *plugin) forceUpgrade(ctx context.Context, name, node string) error {
+
+	sr, err := p.specialResource(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	daemonSets, err := p.daemonSets(ctx, sr, "driver-container")
+	if err != nil {
+		return err
+	}
+
+	deleted := 0
+
+	for i := range daemonSets {
+		pods, err := p.nodeUpgrader.OutdatedPods(ctx, &daemonSets[i])
+		if err != nil {
+			return err
+		}
+
+		nodes := make([]string, 0, len(pods))
+		for n := range pods {
+			if node == "" || n == node {
+				nodes = append(nodes, n)
+			}
+		}
+		sort.Strings(nodes)
+
+		for _, n := range nodes {
+			pod := pods[n]
+			if err = p.kubeClient.Delete(ctx, pod); err != nil {
+				return fmt.Errorf("cannot delete Pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+			fmt.Fprintf(p.out, "pod/%s deleted on node %s\n", pod.Name, n)
+			deleted++
+		}
+	}
+
+	if deleted == 0 {
+		fmt.Fprintf(p.out, "specialresource/%s has no outdated driver Pod\n", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/nodeupgrade"
+	"github.com/openshift-psap/special-resource-operator/pkg/state"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// specialResource returns the SpecialResource name.
+func (p *plugin) specialResource(ctx context.Context, name string) (*srov1beta1.SpecialResource, error) {
+
+	sr := &srov1beta1.SpecialResource{}
+	if err := p.kubeClient.Get(ctx, types.NamespacedName{Name: name}, sr); err != nil {
+		return nil, fmt.Errorf("cannot get SpecialResource %s: %w", name, err)
+	}
+
+	return sr, nil
+}
+
+// namespace returns the namespace of the objects of sr, its name if not set.
+func namespace(sr *srov1beta1.SpecialResource) string {
+	if sr.Spec.Namespace != "" {
+		return sr.Spec.Namespace
+	}
+	return sr.Name
+}
+
+// daemonSets returns the DaemonSets of sr sorted by name, only those annotated with the
+// state stateName if not empty.
+func (p *plugin) daemonSets(ctx context.Context, sr *srov1beta1.SpecialResource, stateName string) ([]appsv1.DaemonSet, error) {
+
+	list := &appsv1.DaemonSetList{}
+	if err := p.kubeClient.List(ctx, list, client.InNamespace(namespace(sr))); err != nil {
+		return nil, fmt.Errorf("cannot list the DaemonSets of SpecialResource %s: %w", sr.Name, err)
+	}
+
+	var daemonSets []appsv1.DaemonSet
+	for _, ds := range list.Items {
+		if !metav1.IsControlledBy(&ds, sr) {
+			continue
+		}
+		if stateName != "" && ds.Annotations["specialresource.openshift.io/state"] != stateName {
+			continue
+		}
+		daemonSets = append(daemonSets, ds)
+	}
+
+	sort.Slice(daemonSets, func(i, j int) bool {
+		return daemonSets[i].Name < daemonSets[j].Name
+	})
+
+	return daemonSets, nil
+}
+
+// readyStates returns the sequence numbers of the states of sr ready on node.
+func readyStates(node *v1.Node, sr string) []string {
+
+	var states []string
+	for key, value := range node.Labels {
+		seq := strings.TrimPrefix(key, state.Prefix(sr))
+		if seq == key || strings.Contains(seq, "-") || value != "Ready" {
+			continue
+		}
+		states = append(states, seq)
+	}
+	sort.Strings(states)
+
+	return states
+}
+
+// status prints the states, kernels and nodes of the SpecialResource name, or of all
+// the SpecialResources if empty.
+func (p *plugin) status(ctx context.Context, name string) error {
+
+	var srs []srov1beta1.SpecialResource
+
+	if name != "" {
+		sr, err := p.specialResource(ctx, name)
+		if err != nil {
+			return err
+		}
+		srs = append(srs, *sr)
+	} else {
+		list := &srov1beta1.SpecialResourceList{}
+		if err := p.kubeClient.List(ctx, list); err != nil {
+			return fmt.Errorf("cannot list SpecialResources: %w", err)
+		}
+		srs = list.Items
+	}
+
+	nodes := &v1.NodeList{}
+	if err := p.kubeClient.List(ctx, nodes); err != nil {
+		return fmt.Errorf("cannot list nodes: %w", err)
+	}
+
+	for i := range srs {
+		if i > 0 {
+			fmt.Fprintln(p.out)
+		}
+		if err := p.printStatus(ctx, &srs[i], nodes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *plugin) printStatus(ctx context.Context, sr *srov1beta1.SpecialResource, nodes *v1.NodeList) error {
+
+	byNode := nodeKernels(nodes)
+
+	kernels, err := affineKernels(byNode)
+	if err != nil {
+		return err
+	}
+
+	daemonSets, err := p.daemonSets(ctx, sr, "")
+	if err != nil {
+		return err
+	}
+
+	conditions := make([]string, 0, len(sr.Status.Conditions))
+	for _, c := range sr.Status.Conditions {
+		conditions = append(conditions, c.Type+"="+string(c.Status))
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Name:\t%s\n", sr.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", namespace(sr))
+	fmt.Fprintf(w, "State:\t%s\n", sr.Status.State)
+	fmt.Fprintf(w, "Conditions:\t%s\n", strings.Join(conditions, ", "))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "DAEMONSET\tSTATE\tKERNEL\tOS\tREADY\tUP-TO-DATE")
+	for _, ds := range daemonSets {
+		k, found := kernelOf(kernels, ds.Name)
+		if ds.Annotations["specialresource.openshift.io/kernel-affine"] != "true" || !found {
+			k = affineKernel{KernelFullVersion: "-", OSVersion: "-"}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/%d\t%d\n", ds.Name, ds.Annotations["specialresource.openshift.io/state"],
+			k.KernelFullVersion, k.OSVersion, ds.Status.NumberReady, ds.Status.DesiredNumberScheduled, ds.Status.UpdatedNumberScheduled)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "NODE\tKERNEL\tREADY STATES\tUPGRADE")
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if !labels.SelectorFromSet(sr.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+			continue
+		}
+
+		kernelFullVersion := "-"
+		if k, found := byNode[node.Name]; found {
+			kernelFullVersion = k.KernelFullVersion
+		}
+
+		states := strings.Join(readyStates(node, sr.Name), ",")
+		if states == "" {
+			states = "-"
+		}
+
+		upgrade := node.Labels[nodeupgrade.LabelPrefix+sr.Name]
+		if upgrade == "" {
+			upgrade = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", node.Name, kernelFullVersion, states, upgrade)
+	}
+
+	return w.Flush()
+}
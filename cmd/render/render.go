@@ -0,0 +1,127 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openshift-psap/special-resource-operator/cmd/cli"
+	"github.com/openshift-psap/special-resource-operator/controllers"
+	"github.com/openshift-psap/special-resource-operator/internal/controllers/state"
+	"github.com/openshift-psap/special-resource-operator/internal/resourcehelper"
+	"github.com/openshift-psap/special-resource-operator/pkg/assets"
+	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	"github.com/openshift-psap/special-resource-operator/pkg/cluster"
+	"github.com/openshift-psap/special-resource-operator/pkg/helmer"
+	"github.com/openshift-psap/special-resource-operator/pkg/kernel"
+	"github.com/openshift-psap/special-resource-operator/pkg/lifecycle"
+	"github.com/openshift-psap/special-resource-operator/pkg/metrics"
+	"github.com/openshift-psap/special-resource-operator/pkg/poll"
+	"github.com/openshift-psap/special-resource-operator/pkg/proxy"
+	srrender "github.com/openshift-psap/special-resource-operator/pkg/render"
+	"github.com/openshift-psap/special-resource-operator/pkg/resource"
+	"github.com/openshift-psap/special-resource-operator/pkg/storage"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+)
+
+// Run writes the manifests the operator would apply for a SpecialResource and a
+// local chart on a simulated cluster, without contacting any cluster. args are the
+// arguments following the render subcommand.
+func Run(programName string, args []string, scheme *runtime.Scheme) error {
+	cl, err := cli.ParseRenderCommandLine(programName, args)
+	if err != nil {
+		return err
+	}
+
+	// The manifests go to the standard output, the logs to the standard error
+	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	sr, err := srrender.LoadSpecialResource(cl.SpecialResource)
+	if err != nil {
+		return err
+	}
+
+	simulated, err := srrender.Load(cl.Cluster)
+	if err != nil {
+		return err
+	}
+
+	capabilities, err := simulated.Capabilities()
+	if err != nil {
+		return err
+	}
+
+	ch, err := loader.Load(cl.Chart)
+	if err != nil {
+		return fmt.Errorf("cannot load chart %s: %w", cl.Chart, err)
+	}
+
+	// Nothing is applied, the objects are recorded by the clients
+	sr.Spec.DryRun = true
+
+	kubeClient := clients.NewOfflineClients(scheme, simulated.Platform, sr)
+	clusterCluster := cluster.NewCluster(kubeClient)
+
+	metricsClient := metrics.New()
+
+	st := storage.NewStorage(kubeClient)
+	lc := lifecycle.New(kubeClient, st)
+	pollActions := poll.New(kubeClient, lc, st)
+	kernelData := kernel.NewKernelData()
+	proxyAPI := proxy.NewStaticProxyAPI(simulated.Proxy)
+
+	creator := resource.NewCreator(
+		kubeClient,
+		metricsClient,
+		pollActions,
+		kernelData,
+		scheme,
+		lc,
+		proxyAPI,
+		resourcehelper.New(),
+		srrender.NewRegistry(),
+		nil)
+
+	r := &controllers.SpecialResourceReconciler{Cluster: clusterCluster,
+		Creator:       creator,
+		PollActions:   pollActions,
+		StatusUpdater: state.NewStatusUpdater(kubeClient),
+		Storage:       st,
+		Helmer:        helmer.NewOfflineHelmer(creator, helmer.DefaultSettings(), kubeClient, capabilities),
+		Assets:        assets.NewAssets(),
+		KernelData:    kernelData,
+		Log:           ctrl.Log,
+		Metrics:       metricsClient,
+		Scheme:        scheme,
+		ProxyAPI:      proxyAPI,
+		KubeClient:    kubeClient,
+	}
+
+	if err = controllers.Render(context.Background(), r, *sr, ch, simulated); err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if cl.Output != "-" {
+		if out, err = os.Create(cl.Output); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	for _, obj := range kubeClient.Applied() {
+		manifest, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("cannot marshal %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if _, err = fmt.Fprintf(out, "---\n%s", manifest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
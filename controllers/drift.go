@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultDriftInterval is how often the live objects are compared to the chart
+// if the SpecialResource does not set an interval.
+const defaultDriftInterval = 5 * time.Minute
+
+// updateDrift records the drift found while reconciling the states in the status
+// of the SpecialResource, keeping the time it was first detected, and returns a
+// requeueError for the next check unless drift is ignored.
+func (r *SpecialResourceReconciler) updateDrift(ctx context.Context) error {
+
+	spec := r.specialresource.Spec.Drift
+	ignored := spec == nil || spec.Policy == "" || spec.Policy == srov1beta1.DriftPolicyIgnore
+
+	detected := make(map[string]metav1.Time)
+	for _, d := range r.specialresource.Status.Drift {
+		detected[driftKey(d)] = d.DetectedTime
+	}
+
+	drift := r.drift
+	for i := range drift {
+		if t, ok := detected[driftKey(drift[i])]; ok {
+			drift[i].DetectedTime = t
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    srov1beta1.ConditionDrifted,
+		Status:  metav1.ConditionFalse,
+		Reason:  "InSync",
+		Message: "the live objects match the chart",
+	}
+
+	switch {
+	case ignored:
+		condition.Reason = "Ignored"
+		condition.Message = "drift is not detected"
+	case len(drift) > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Drifted"
+		condition.Message = fmt.Sprintf("%d objects differ from the chart", len(drift))
+		if spec.Policy == srov1beta1.DriftPolicyCorrect {
+			condition.Reason = "Corrected"
+			condition.Message = fmt.Sprintf("%d objects differed from the chart and were corrected", len(drift))
+		}
+	}
+
+	current := meta.FindStatusCondition(r.specialresource.Status.Conditions, srov1beta1.ConditionDrifted)
+
+	changed := (len(drift) > 0 || len(r.specialresource.Status.Drift) > 0) &&
+		!reflect.DeepEqual(drift, r.specialresource.Status.Drift)
+
+	// Without a policy the condition is only set if drift was detected before
+	if ignored && current == nil && !changed {
+		return nil
+	}
+
+	if changed || current == nil || current.Status != condition.Status || current.Reason != condition.Reason || current.Message != condition.Message {
+		r.StatusUpdater.UpdateDrift(ctx, &r.specialresource, drift, condition)
+	}
+
+	if ignored {
+		return nil
+	}
+
+	interval := defaultDriftInterval
+	if spec.Interval != nil && spec.Interval.Duration > 0 {
+		interval = spec.Interval.Duration
+	}
+
+	return &requeueError{reason: "drift detection", after: interval}
+}
+
+func driftKey(d srov1beta1.DriftStatus) string {
+	return fmt.Sprintf("%s/%s/%s/%v", d.Kind, d.Namespace, d.Name, d.Fields)
+}
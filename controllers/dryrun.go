@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// updateDryRun writes the diffs of the objects the dry run of the parent and its
+// dependencies would change to the <name>-dry-run ConfigMap in the namespace of the
+// operator, and counts them in the DryRun condition. Once the dry run is turned off
+// the ConfigMap is deleted.
+func (r *SpecialResourceReconciler) updateDryRun(ctx context.Context) error {
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.parent.Name + "-dry-run",
+			Namespace: os.Getenv("OPERATOR_NAMESPACE"),
+		},
+	}
+
+	current := meta.FindStatusCondition(r.parent.Status.Conditions, srov1beta1.ConditionDryRun)
+
+	if !r.parent.Spec.DryRun {
+		if current == nil || current.Status == metav1.ConditionFalse {
+			return nil
+		}
+
+		if err := r.KubeClient.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete ConfigMap %s/%s: %w", cm.Namespace, cm.Name, err)
+		}
+
+		r.StatusUpdater.SetCondition(ctx, &r.parent, metav1.Condition{
+			Type:    srov1beta1.ConditionDryRun,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Applied",
+			Message: "the changes are applied",
+		})
+		return nil
+	}
+
+	data := make(map[string]string, len(r.dryRunChanges))
+	rejected := 0
+	for _, change := range r.dryRunChanges {
+		data[change.Key()] = change.String()
+		if change.Error != "" {
+			rejected++
+		}
+	}
+
+	if _, err := r.KubeClient.CreateOrUpdate(ctx, cm, func() error {
+		cm.Data = data
+		return controllerutil.SetControllerReference(&r.parent, cm, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("cannot write ConfigMap %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+
+	condition := metav1.Condition{
+		Type:    srov1beta1.ConditionDryRun,
+		Status:  metav1.ConditionTrue,
+		Reason:  "DryRun",
+		Message: fmt.Sprintf("%d objects would change, %d were rejected, see ConfigMap %s/%s", len(data)-rejected, rejected, cm.Namespace, cm.Name),
+	}
+
+	if current == nil || current.Status != condition.Status || current.Message != condition.Message {
+		r.StatusUpdater.SetCondition(ctx, &r.parent, condition)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/maintenance"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// requeueError is returned once the states are reconciled but the SpecialResource
+// has to be reconciled again later, it is requeued without error.
+type requeueError struct {
+	reason string
+	after  time.Duration
+}
+
+func (e *requeueError) Error() string {
+	return e.reason
+}
+
+// minRequeue returns the requeueError of a and b requeued first.
+func minRequeue(a, b error) error {
+	afterA, okA := requeueAfter(a)
+	afterB, okB := requeueAfter(b)
+	if !okA || (okB && afterB < afterA) {
+		return b
+	}
+	return a
+}
+
+// requeueAfter returns the delay after which the SpecialResource is reconciled
+// again if err is a requeueError.
+func requeueAfter(err error) (time.Duration, bool) {
+	var re *requeueError
+	if errors.As(err, &re) {
+		return re.after, true
+	}
+	return 0, false
+}
+
+// nextMaintenanceWindow returns when the next maintenance window of the
+// SpecialResource opens, or zero if one is open or it has none.
+func (r *SpecialResourceReconciler) nextMaintenanceWindow() (time.Time, error) {
+	open, next, err := maintenance.Open(r.specialresource.Spec.MaintenanceWindows, time.Now())
+	if err != nil || open {
+		return time.Time{}, err
+	}
+	return next, nil
+}
+
+// updatePendingChanges records the changes deferred while reconciling the states
+// in the status of the SpecialResource, keeping the time they were first deferred,
+// and returns a requeueError for the next maintenance window if there are any.
+func (r *SpecialResourceReconciler) updatePendingChanges(ctx context.Context) error {
+
+	next, err := r.nextMaintenanceWindow()
+	if err != nil {
+		return err
+	}
+
+	since := make(map[srov1beta1.PendingChangeStatus]metav1.Time)
+	for _, change := range r.specialresource.Status.PendingChanges {
+		since[pendingChangeKey(change)] = change.Since
+	}
+
+	var changes []srov1beta1.PendingChangeStatus
+	seen := make(map[srov1beta1.PendingChangeStatus]bool)
+
+	for _, change := range r.pendingChanges {
+		key := pendingChangeKey(change)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if t, ok := since[key]; ok {
+			change.Since = t
+		}
+		changes = append(changes, change)
+	}
+
+	var nextWindow *metav1.Time
+	if len(changes) > 0 && !next.IsZero() {
+		t := metav1.NewTime(next)
+		nextWindow = &t
+	}
+
+	if !reflect.DeepEqual(changes, r.specialresource.Status.PendingChanges) ||
+		!reflect.DeepEqual(nextWindow, r.specialresource.Status.NextMaintenanceWindow) {
+		r.StatusUpdater.UpdatePendingChanges(ctx, &r.specialresource, changes, nextWindow)
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// A window opened while the states were reconciled, apply the changes now
+	after := time.Until(next)
+	if after <= 0 {
+		after = time.Second
+	}
+
+	return &requeueError{reason: "changes pending until the next maintenance window", after: after}
+}
+
+func pendingChangeKey(change srov1beta1.PendingChangeStatus) srov1beta1.PendingChangeStatus {
+	change.Since = metav1.Time{}
+	return change
+}
@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-psap/special-resource-operator/pkg/poll"
+	"github.com/openshift-psap/special-resource-operator/pkg/state"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const kernelFullVersionLabel = "feature.node.kubernetes.io/kernel-version.full"
+
+// taintNodes taints the nodes matched by the NodeSelector of the SpecialResource
+// with its not ready taint if NotReadyTaint is set, and removes the taint otherwise.
+// A node is ready once every DaemonSet of the SpecialResource selecting it runs a
+// ready Pod on it, it is labeled with all the states of the chart and, if some
+// DaemonSets are kernel affine, one of them selects its kernel. The taint is only
+// removed once all the states were reconciled. Build pods cannot tolerate the
+// taint, while a driver container of the SpecialResource is built the taints are
+// left as they are: no node is newly tainted and no taint is removed.
+func (r *SpecialResourceReconciler) taintNodes(ctx context.Context, statesReady bool) error {
+
+	// Tainted and cordoned nodes are filtered out by GetNodesByLabels
+	nodes := &v1.NodeList{}
+	if err := r.KubeClient.List(ctx, nodes, client.MatchingLabels(r.specialresource.Spec.NodeSelector)); err != nil {
+		return fmt.Errorf("cannot list nodes: %w", err)
+	}
+
+	var states []string
+	var daemonSets []appsv1.DaemonSet
+	var readyPods map[string]map[string]bool
+	var building bool
+	kernels := make(map[string]bool)
+
+	if r.specialresource.Spec.NotReadyTaint {
+		for _, template := range r.chart.Templates {
+			if r.Assets.ValidStateName(template.Name) {
+				states = append(states, state.Name(template, r.specialresource.Name))
+			}
+		}
+
+		dsList := &appsv1.DaemonSetList{}
+		if err := r.KubeClient.List(ctx, dsList, client.InNamespace(r.specialresource.Spec.Namespace)); err != nil {
+			return fmt.Errorf("cannot list DaemonSets: %w", err)
+		}
+
+		for _, ds := range dsList.Items {
+			if !metav1.IsControlledBy(&ds, &r.specialresource) {
+				continue
+			}
+			daemonSets = append(daemonSets, ds)
+			if kernel, ok := ds.Spec.Template.Spec.NodeSelector[kernelFullVersionLabel]; ok {
+				kernels[kernel] = true
+			}
+		}
+
+		var err error
+		if readyPods, err = r.readyDaemonSetPods(ctx); err != nil {
+			return err
+		}
+
+		if building, err = r.buildsInProgress(ctx); err != nil {
+			return err
+		}
+	}
+
+	taint := state.NotReadyTaint(r.specialresource.Name)
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+
+		tainted := hasTaint(node, taint)
+
+		ready := true
+		if r.specialresource.Spec.NotReadyTaint {
+			ready = nodeReady(node, states, daemonSets, readyPods[node.Name], kernels) && (statesReady || !tainted)
+		}
+
+		if ready != tainted {
+			continue
+		}
+
+		if building {
+			log.Info("NODE", "Not changing the taint while building on", node.Name)
+			continue
+		}
+
+		if ready {
+			log.Info("NODE", "Removing taint", taint.Key, "from", node.Name)
+			node.Spec.Taints = removeTaint(node.Spec.Taints, taint)
+		} else {
+			log.Info("NODE", "Adding taint", taint.Key, "to", node.Name)
+			node.Spec.Taints = append(node.Spec.Taints, taint)
+		}
+
+		if err := r.KubeClient.Update(ctx, node); err != nil {
+			return fmt.Errorf("cannot update the taints of node %s: %w", node.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// buildsInProgress returns true if a Build or a BuildRun of the SpecialResource is
+// not finished, a BuildRun it controls or a Build of a BuildConfig it controls.
+// Neither BuildConfigs nor BuildRuns can set the tolerations of their build pods.
+func (r *SpecialResourceReconciler) buildsInProgress(ctx context.Context) (bool, error) {
+
+	buildConfigs, err := r.listBuilds(ctx, "BuildConfigList")
+	if err != nil {
+		return false, err
+	}
+
+	owned := make(map[string]bool)
+	for i := range buildConfigs {
+		if metav1.IsControlledBy(&buildConfigs[i], &r.specialresource) {
+			owned[buildConfigs[i].GetName()] = true
+		}
+	}
+
+	builds, err := r.listBuilds(ctx, "BuildList")
+	if err != nil {
+		return false, err
+	}
+
+	buildRuns, err := r.listBuilds(ctx, "BuildRunList")
+	if err != nil {
+		return false, err
+	}
+
+	objs := append(builds, buildRuns...)
+	for i := range objs {
+		obj := &objs[i]
+
+		if obj.GetKind() == "BuildRun" && !metav1.IsControlledBy(obj, &r.specialresource) {
+			continue
+		}
+
+		if obj.GetKind() == "Build" {
+			owner := metav1.GetControllerOf(obj)
+			if owner == nil || owner.Kind != "BuildConfig" || !owned[owner.Name] {
+				continue
+			}
+		}
+
+		if !buildFinished(obj) {
+			log.Info("NODE", "Not changing the taints while building", obj.GetName())
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// listBuilds returns the objects of the build list kind, BuildConfigList, BuildList
+// or BuildRunList, in the namespace of the SpecialResource, none if Shipwright or
+// the OpenShift builds are not installed.
+func (r *SpecialResourceReconciler) listBuilds(ctx context.Context, kind string) ([]unstructured.Unstructured, error) {
+
+	gvk := schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: kind}
+	if kind == "BuildRunList" {
+		gvk = schema.GroupVersionKind{Group: "shipwright.io", Version: "v1alpha1", Kind: kind}
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+
+	if err := r.KubeClient.List(ctx, list, client.InNamespace(r.specialresource.Spec.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot list %s: %w", gvk.Kind, err)
+	}
+
+	return list.Items, nil
+}
+
+// buildFinished returns true if the Build or BuildRun obj succeeded or failed.
+func buildFinished(obj *unstructured.Unstructured) bool {
+
+	if obj.GetKind() == "BuildRun" {
+		status, _, _ := poll.BuildRunCondition(obj)
+		return status == "True" || status == "False"
+	}
+
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Complete", "Failed", "Error", "Cancelled":
+		return true
+	}
+
+	return false
+}
+
+// nodeReady returns true if node is labeled with states, if every DaemonSet selecting
+// it runs a ready Pod on it, and if its kernel is one of the kernels of the kernel
+// affine DaemonSets.
+func nodeReady(node *v1.Node, states []string, daemonSets []appsv1.DaemonSet, readyPods map[string]bool, kernels map[string]bool) bool {
+
+	for _, name := range states {
+		if node.Labels[name] != "Ready" {
+			return false
+		}
+	}
+
+	if len(kernels) > 0 && !kernels[node.Labels[kernelFullVersionLabel]] {
+		return false
+	}
+
+	return daemonSetsReady(node, daemonSets, readyPods)
+}
+
+// daemonSetsReady returns true if every DaemonSet whose node selector matches node
+// runs a ready Pod on it.
+func daemonSetsReady(node *v1.Node, daemonSets []appsv1.DaemonSet, readyPods map[string]bool) bool {
+
+	for _, ds := range daemonSets {
+		selector := labels.SelectorFromSet(ds.Spec.Template.Spec.NodeSelector)
+		if selector.Matches(labels.Set(node.Labels)) && !readyPods[ds.Name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readyDaemonSetPods returns the names of the DaemonSets running a ready Pod in
+// the namespace of the SpecialResource, by node.
+func (r *SpecialResourceReconciler) readyDaemonSetPods(ctx context.Context) (map[string]map[string]bool, error) {
+
+	pods := &v1.PodList{}
+	if err := r.KubeClient.List(ctx, pods, client.InNamespace(r.specialresource.Spec.Namespace)); err != nil {
+		return nil, fmt.Errorf("cannot list Pods: %w", err)
+	}
+
+	readyPods := make(map[string]map[string]bool)
+
+	for _, pod := range pods.Items {
+		owner := metav1.GetControllerOf(&pod)
+		if owner == nil || owner.Kind != "DaemonSet" || !isPodReady(&pod) {
+			continue
+		}
+		if readyPods[pod.Spec.NodeName] == nil {
+			readyPods[pod.Spec.NodeName] = make(map[string]bool)
+		}
+		readyPods[pod.Spec.NodeName][owner.Name] = true
+	}
+
+	return readyPods, nil
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func hasTaint(node *v1.Node, taint v1.Taint) bool {
+	for _, t := range node.Spec.Taints {
+		if t.MatchTaint(&taint) {
+			return true
+		}
+	}
+	return false
+}
+
+func removeTaint(taints []v1.Taint, taint v1.Taint) []v1.Taint {
+	kept := taints[:0:0]
+	for _, t := range taints {
+		if !t.MatchTaint(&taint) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
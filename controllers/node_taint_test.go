@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/assets"
+	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	"github.com/openshift-psap/special-resource-operator/pkg/state"
+	"helm.sh/helm/v3/pkg/chart"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func TestControllers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Controllers Suite")
+}
+
+var _ = Describe("SpecialResourceReconciler_taintNodes", func() {
+	var (
+		kubeClient *clients.OfflineClients
+		r          *SpecialResourceReconciler
+		build      *unstructured.Unstructured
+	)
+
+	ctx := context.Background()
+
+	taint := state.NotReadyTaint("simple-kmod")
+
+	node := func(name string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"node-role.kubernetes.io/worker": ""}},
+			Spec:       v1.NodeSpec{Taints: []v1.Taint{taint}},
+		}
+	}
+
+	tainted := func(name string) bool {
+		n := &v1.Node{}
+		Expect(kubeClient.Get(ctx, types.NamespacedName{Name: name}, n)).To(Succeed())
+		return hasTaint(n, taint)
+	}
+
+	BeforeEach(func() {
+		log = zap.New(zap.WriteTo(GinkgoWriter))
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+		controller := true
+
+		buildConfig := &unstructured.Unstructured{}
+		buildConfig.SetAPIVersion("build.openshift.io/v1")
+		buildConfig.SetKind("BuildConfig")
+		buildConfig.SetName("simple-kmod-driver-build")
+		buildConfig.SetNamespace("simple-kmod")
+		buildConfig.SetOwnerReferences([]metav1.OwnerReference{
+			{APIVersion: "sro.openshift.io/v1beta1", Kind: "SpecialResource", Name: "simple-kmod", UID: "sr-uid", Controller: &controller},
+		})
+
+		build = &unstructured.Unstructured{}
+		build.SetAPIVersion("build.openshift.io/v1")
+		build.SetKind("Build")
+		build.SetName("simple-kmod-driver-build-1")
+		build.SetNamespace("simple-kmod")
+		build.SetOwnerReferences([]metav1.OwnerReference{
+			{APIVersion: "build.openshift.io/v1", Kind: "BuildConfig", Name: "simple-kmod-driver-build", UID: "bc-uid", Controller: &controller},
+		})
+		Expect(unstructured.SetNestedField(build.Object, "Running", "status", "phase")).To(Succeed())
+
+		// worker-0 is tainted, e.g. after a kernel upgrade, worker-1 just joined
+		untainted := node("worker-1")
+		untainted.Spec.Taints = nil
+		kubeClient = clients.NewOfflineClients(scheme, "OCP", node("worker-0"), untainted, buildConfig, build)
+
+		r = &SpecialResourceReconciler{
+			Assets:     assets.NewAssets(),
+			KubeClient: kubeClient,
+			specialresource: srov1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: "simple-kmod", UID: "sr-uid"},
+				Spec: srov1beta1.SpecialResourceSpec{
+					Namespace:     "simple-kmod",
+					NodeSelector:  map[string]string{"node-role.kubernetes.io/worker": ""},
+					NotReadyTaint: true,
+				},
+			},
+			chart: chart.Chart{Templates: []*chart.File{{Name: "templates/0000-buildconfig.yaml"}}},
+		}
+	})
+
+	It("should leave the taints as they are while a driver container is built", func() {
+		Expect(r.taintNodes(ctx, false)).To(Succeed())
+		Expect(tainted("worker-0")).To(BeTrue())
+		Expect(tainted("worker-1")).To(BeFalse())
+
+		Expect(unstructured.SetNestedField(build.Object, "Complete", "status", "phase")).To(Succeed())
+		Expect(kubeClient.Update(ctx, build)).To(Succeed())
+
+		// The states are not ready yet
+		Expect(r.taintNodes(ctx, false)).To(Succeed())
+		Expect(tainted("worker-0")).To(BeTrue())
+		Expect(tainted("worker-1")).To(BeTrue())
+	})
+
+	It("should ignore the builds of other BuildConfigs", func() {
+		controller := true
+		build.SetOwnerReferences([]metav1.OwnerReference{
+			{APIVersion: "build.openshift.io/v1", Kind: "BuildConfig", Name: "other-driver-build", UID: "other-uid", Controller: &controller},
+		})
+		Expect(kubeClient.Update(ctx, build)).To(Succeed())
+
+		Expect(r.taintNodes(ctx, false)).To(Succeed())
+		Expect(tainted("worker-0")).To(BeTrue())
+		Expect(tainted("worker-1")).To(BeTrue())
+	})
+})
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/nodeupgrade"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeUpgradePollInterval is how often the upgrades of the nodes are advanced,
+// the Pods of the driver DaemonSets are not watched.
+const nodeUpgradePollInterval = 10 * time.Second
+
+// upgradeNodes advances the upgrades of the nodes running an outdated Pod of one
+// of the driver DaemonSets of the SpecialResource, with the OnDelete upgrade strategy.
+func (r *SpecialResourceReconciler) upgradeNodes(ctx context.Context) error {
+
+	strategy := r.specialresource.Spec.UpgradeStrategy
+	if strategy == nil || strategy.Type != srov1beta1.UpgradeStrategyOnDelete {
+		return nil
+	}
+
+	list := &appsv1.DaemonSetList{}
+	if err := r.KubeClient.List(ctx, list, client.InNamespace(r.specialresource.Spec.Namespace)); err != nil {
+		return fmt.Errorf("cannot list DaemonSets: %w", err)
+	}
+
+	upgrades := r.specialresource.Status.NodeUpgrades
+	existing := make(map[string]bool)
+	done := true
+
+	var err error
+
+	for i := range list.Items {
+		ds := &list.Items[i]
+
+		if !metav1.IsControlledBy(ds, &r.specialresource) || ds.Annotations["specialresource.openshift.io/state"] != "driver-container" {
+			continue
+		}
+
+		name := ds.Namespace + "/" + ds.Name
+		existing[name] = true
+
+		var dsDone bool
+
+		// Keep the progress made before an error, e.g. the nodes cordoned
+		if upgrades, dsDone, err = r.NodeUpgrader.Upgrade(ctx, &r.specialresource, ds, upgrades); err != nil {
+			err = fmt.Errorf("cannot upgrade the nodes of DaemonSet %s: %w", name, err)
+			break
+		}
+
+		done = done && dsDone
+	}
+
+	// Forget the upgrades of the DaemonSets removed from the chart
+	if err == nil {
+		kept := upgrades[:0:0]
+		for _, upgrade := range upgrades {
+			if existing[upgrade.DaemonSet] {
+				kept = append(kept, upgrade)
+			}
+		}
+		upgrades = kept
+	}
+
+	if !reflect.DeepEqual(upgrades, r.specialresource.Status.NodeUpgrades) {
+		r.StatusUpdater.UpdateNodeUpgrades(ctx, &r.specialresource, upgrades)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if done {
+		return nil
+	}
+
+	// Nodes are only cordoned during the maintenance windows, wait for the next
+	// one unless an upgrade is still in progress
+	for _, upgrade := range upgrades {
+		if nodeupgrade.InProgress(upgrade) {
+			return &requeueError{reason: "node upgrade in progress", after: nodeUpgradePollInterval}
+		}
+	}
+
+	next, err := r.nextMaintenanceWindow()
+	if err != nil {
+		return err
+	}
+	if !next.IsZero() {
+		return &requeueError{reason: "node upgrade pending until the next maintenance window", after: time.Until(next)}
+	}
+
+	return &requeueError{reason: "node upgrade in progress", after: nodeUpgradePollInterval}
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/render"
+	"github.com/openshift-psap/special-resource-operator/pkg/utils"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Render reconciles the chart of sr in dry-run mode for the simulated cluster, the
+// runtime information comes from cluster instead of the API server. The reconciler
+// is expected to use offline clients recording the objects that would be applied,
+// holding sr in dry-run mode as the status updates read it back.
+func Render(ctx context.Context, r *SpecialResourceReconciler, sr srov1beta1.SpecialResource, ch *chart.Chart, cluster *render.Cluster) error {
+
+	if !sr.Spec.DryRun {
+		return fmt.Errorf("SpecialResource %s is not in dry-run mode", sr.Name)
+	}
+
+	r.parent = sr
+	r.specialresource = sr
+	r.chart = *ch
+	r.values = sr.Spec.Set
+
+	log = r.Log.WithName(utils.Print(r.specialresource.Name, utils.Green))
+	log.Info("Rendering Chart")
+
+	nodeList := cluster.NodeList(r.specialresource.Spec.NodeSelector)
+	if len(nodeList.Items) == 0 {
+		return fmt.Errorf("no node of the cluster matches the node selector %v", r.specialresource.Spec.NodeSelector)
+	}
+
+	var err error
+
+	RunInfo.OperatingSystemMajor, RunInfo.OperatingSystemMajorMinor, RunInfo.OperatingSystemDecimal, err = r.Cluster.OperatingSystem(nodeList)
+	if err != nil {
+		return fmt.Errorf("failed to get operating system: %w", err)
+	}
+
+	RunInfo.KernelFullVersion, err = r.KernelData.FullVersion(nodeList)
+	if err != nil {
+		return fmt.Errorf("failed to get kernel version: %w", err)
+	}
+
+	RunInfo.KernelPatchVersion, err = r.KernelData.PatchVersion(RunInfo.KernelFullVersion)
+	if err != nil {
+		return fmt.Errorf("failed to get kernel patch version: %w", err)
+	}
+
+	RunInfo.ClusterUpgradeInfo, err = cluster.ClusterUpgradeInfo(nodeList)
+	if err != nil {
+		return fmt.Errorf("failed to get upgrade info: %w", err)
+	}
+
+	RunInfo.Proxy, err = r.ProxyAPI.ClusterConfiguration(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get Proxy Configuration: %w", err)
+	}
+
+	RunInfo.Platform = cluster.Platform
+	RunInfo.ClusterVersion = cluster.ClusterVersion
+	RunInfo.ClusterVersionMajorMinor = cluster.ClusterVersionMajorMinor()
+	RunInfo.PushSecretName = cluster.PushSecretName
+	RunInfo.OSImageURL = cluster.OSImageURL
+
+	setRuntimeSpecialResource(&r.specialresource)
+
+	logRuntimeInformation()
+
+	if err = templateSpecialResource(r); err != nil {
+		return err
+	}
+
+	return ReconcileChart(ctx, r)
+}
@@ -6,14 +6,20 @@ import (
 	"sort"
 	"strings"
 
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/postrender"
+	"github.com/openshift-psap/special-resource-operator/pkg/resource"
+	"github.com/openshift-psap/special-resource-operator/pkg/signing"
 	"github.com/openshift-psap/special-resource-operator/pkg/state"
-	"github.com/openshift-psap/special-resource-operator/pkg/upgrade"
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
+	"github.com/openshift-psap/special-resource-operator/pkg/values"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	helmpostrender "helm.sh/helm/v3/pkg/postrender"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -125,6 +131,12 @@ func createImagePullerRoleBinding(ctx context.Context, r *SpecialResourceReconci
 // ReconcileChartStates Reconcile Hardware States
 func ReconcileChartStates(ctx context.Context, r *SpecialResourceReconciler) error {
 
+	// The same patches apply to the states and the rest of the chart
+	postRenderer, err := postrender.NewPostRenderer(r.specialresource.Spec.PostRender)
+	if err != nil {
+		return err
+	}
+
 	nostate := r.chart
 	nostate.Templates = []*chart.File{}
 
@@ -144,6 +156,28 @@ func ReconcileChartStates(ctx context.Context, r *SpecialResourceReconciler) err
 		return stateYAMLS[i].Name < stateYAMLS[j].Name
 	})
 
+	// The cluster has more then one kernel version running
+	// we're replicating the driver-container DaemonSet to
+	// the number of kernel versions running in the cluster
+	if len(RunInfo.ClusterUpgradeInfo) == 0 {
+		return errors.New("no KernelVersion detected, something is wrong")
+	}
+
+	// The kernels are replicated in order, the same cluster renders the
+	// same objects
+	kernels := make([]string, 0, len(RunInfo.ClusterUpgradeInfo))
+	for kernel := range RunInfo.ClusterUpgradeInfo {
+		kernels = append(kernels, kernel)
+	}
+	sort.Strings(kernels)
+
+	// The build states come before the states of the DaemonSets using
+	// their images, the driver containers are prepared before any state is run
+	drivers, err := r.prepareDrivers(ctx, nostate, stateYAMLS, kernels, postRenderer)
+	if err != nil {
+		return err
+	}
+
 	for _, stateYAML := range stateYAMLS {
 
 		log.Info("Executing", "State", stateYAML.Name)
@@ -158,32 +192,18 @@ func ReconcileChartStates(ctx context.Context, r *SpecialResourceReconciler) err
 		// affinity, anti-affinity
 		state.GenerateName(stateYAML, r.specialresource.Name)
 
-		step := nostate
-		step.Templates = append(nostate.Templates, stateYAML)
+		step := r.stateChart(nostate, stateYAML)
 
-		// We are kernel-affine if the yamlSpec uses {{.Values.kernelFullVersion}}
-		// then we need to replicate the object and set a name + os + kernel version
-		kernelAffine := strings.Contains(string(stateYAML.Data), ".Values.kernelFullVersion")
+		kernelAffine := isKernelAffine(stateYAML)
 
 		var replicas int
-		var version upgrade.NodeVersion
-
-		// The cluster has more then one kernel version running
-		// we're replicating the driver-container DaemonSet to
-		// the number of kernel versions running in the cluster
-		if len(RunInfo.ClusterUpgradeInfo) == 0 {
-			return errors.New("no KernelVersion detected, something is wrong")
-		}
+		var daemonSets []types.NamespacedName
 
 		//var replicas is to keep track of the number of replicas
 		// and either to break or continue the for looop
-		for RunInfo.KernelFullVersion, version = range RunInfo.ClusterUpgradeInfo {
+		for _, kernelFullVersion := range kernels {
 
-			RunInfo.ClusterVersionMajorMinor = version.ClusterVersion
-			RunInfo.OperatingSystemDecimal = version.OSVersion
-			RunInfo.OperatingSystemMajorMinor = version.OSMajorMinor
-			RunInfo.OperatingSystemMajor = version.OSMajor
-			RunInfo.DriverToolkitImage = version.DriverToolkit.ImageURL
+			setRunInfoKernel(kernelFullVersion)
 
 			if kernelAffine {
 				log.Info("KernelAffine: ClusterUpgradeInfo",
@@ -193,20 +213,7 @@ func ReconcileChartStates(ctx context.Context, r *SpecialResourceReconciler) err
 					"driverToolkitImage", RunInfo.DriverToolkitImage)
 			}
 
-			var err error
-
-			step.Values, err = chartutil.CoalesceValues(&step, r.values.Object)
-			if err != nil {
-				return err
-			}
-
-			rinfo, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&RunInfo)
-			if err != nil {
-				return err
-			}
-
-			step.Values, err = chartutil.CoalesceValues(&step, rinfo)
-			if err != nil {
+			if err := r.coalesceValues(&step); err != nil {
 				return err
 			}
 
@@ -215,7 +222,7 @@ func ReconcileChartStates(ctx context.Context, r *SpecialResourceReconciler) err
 				log.Info("Debug active. Showing YAML values", "values", d)
 			}
 
-			err = r.Helmer.Run(
+			res, err := r.Helmer.Run(
 				ctx,
 				step,
 				step.Values,
@@ -225,11 +232,19 @@ func ReconcileChartStates(ctx context.Context, r *SpecialResourceReconciler) err
 				r.specialresource.Spec.NodeSelector,
 				RunInfo.KernelFullVersion,
 				RunInfo.OperatingSystemDecimal,
-				r.specialresource.Spec.Debug)
+				r.specialresource.Spec.Debug,
+				r.specialresource.Spec.DryRun,
+				postRenderer,
+				drivers)
 			//if err != nil {
 			//	return err
 			//}
 
+			r.addResult(ctx, res)
+			if res != nil {
+				daemonSets = append(daemonSets, res.DaemonSets...)
+			}
+
 			replicas += 1
 
 			// If the first replica fails we want to create all remaining
@@ -237,6 +252,12 @@ func ReconcileChartStates(ctx context.Context, r *SpecialResourceReconciler) err
 			// then for the second etc.
 			if err != nil && replicas == len(RunInfo.ClusterUpgradeInfo) {
 				r.Metrics.SetCompletedState(r.specialresource.Name, stateYAML.Name, 0)
+				// The nodes whose Pods of the state are not ready lose its label
+				if !r.specialresource.Spec.DryRun {
+					if lerr := r.labelNodesAccordingToState(ctx, daemonSets); lerr != nil {
+						log.Error(lerr, "Cannot label nodes according to the state", "State", stateYAML.Name)
+					}
+				}
 				return fmt.Errorf("failed to create state %s: %w ", stateYAML.Name, err)
 			}
 
@@ -247,19 +268,190 @@ func ReconcileChartStates(ctx context.Context, r *SpecialResourceReconciler) err
 		}
 
 		r.Metrics.SetCompletedState(r.specialresource.Name, stateYAML.Name, 1)
+
+		// Nothing was created, the nodes keep their labels
+		if r.specialresource.Spec.DryRun {
+			continue
+		}
+
 		// If resource available, label the nodes according to the current state
 		// if e.g driver-container ready -> specialresource.openshift.io/driver-container:ready
 		r.StatusUpdater.UpdateWithState(ctx, &r.specialresource, state.CurrentName)
 
-		if err := r.labelNodesAccordingToState(ctx, r.specialresource.Spec.NodeSelector); err != nil {
+		if err := r.labelNodesAccordingToState(ctx, daemonSets); err != nil {
 			return err
 		}
 	}
 
 	// We're done with states now execute the part of the chart without
 	// states we need to reconcile the nostate Chart
+	if err = r.coalesceValues(&nostate); err != nil {
+		return err
+	}
+
+	res, err := r.Helmer.Run(
+		ctx,
+		nostate,
+		nostate.Values,
+		&r.specialresource,
+		r.specialresource.Name,
+		r.specialresource.Spec.Namespace,
+		r.specialresource.Spec.NodeSelector,
+		RunInfo.KernelFullVersion,
+		RunInfo.OperatingSystemDecimal,
+		false,
+		r.specialresource.Spec.DryRun,
+		postRenderer,
+		drivers)
+
+	r.addResult(ctx, res)
+
+	return err
+}
+
+// addResult records the results of a run of the chart: the images resolved by the
+// image policy and the driver images the DaemonSets could pull or not in the
+// status, and the pending changes, drift and dry-run changes until the
+// reconciliation is done.
+func (r *SpecialResourceReconciler) addResult(ctx context.Context, res *resource.Result) {
+	if res == nil {
+		return
+	}
+	if len(res.ResolvedImages) > 0 && !r.specialresource.Spec.DryRun {
+		r.StatusUpdater.UpdateResolvedImages(ctx, &r.specialresource, res.ResolvedImages)
+	}
+	if len(res.DriverImages) > 0 && !r.specialresource.Spec.DryRun {
+		r.StatusUpdater.UpdateDriverImages(ctx, &r.specialresource, res.DriverImages)
+	}
+	r.pendingChanges = append(r.pendingChanges, res.PendingChanges...)
+	r.drift = append(r.drift, res.Drift...)
+	r.dryRunChanges = append(r.dryRunChanges, res.DryRunChanges...)
+}
+
+// stateChart returns the chart of the state stateYAML, the templates of nostate and
+// the state's template.
+func (r *SpecialResourceReconciler) stateChart(nostate chart.Chart, stateYAML *chart.File) chart.Chart {
+
+	step := nostate
+	step.Templates = append(nostate.Templates, stateYAML)
+
+	// The signing BuildConfig is rendered with every state so that it signs
+	// the driver containers built by the state for the same kernel version
+	if r.specialresource.Spec.Signing != nil {
+		step.Templates = append(step.Templates, signing.TemplateFile())
+	}
+
+	return step
+}
+
+// isKernelAffine returns true if the state stateYAML uses {{.Values.kernelFullVersion}},
+// then we need to replicate the object and set a name + os + kernel version.
+func isKernelAffine(stateYAML *chart.File) bool {
+	return strings.Contains(string(stateYAML.Data), ".Values.kernelFullVersion")
+}
+
+// setRunInfoKernel sets the runtime information of the nodes running kernelFullVersion.
+func setRunInfoKernel(kernelFullVersion string) {
+
+	version := RunInfo.ClusterUpgradeInfo[kernelFullVersion]
+
+	RunInfo.KernelFullVersion = kernelFullVersion
+	RunInfo.ClusterVersionMajorMinor = version.ClusterVersion
+	RunInfo.OperatingSystemDecimal = version.OSVersion
+	RunInfo.OperatingSystemMajorMinor = version.OSMajorMinor
+	RunInfo.OperatingSystemMajor = version.OSMajor
+	RunInfo.DriverToolkitImage = version.DriverToolkit.ImageURL
+}
+
+// coalesceValues merges the values of the SpecialResource, then the runtime
+// information, into the values of ch.
+func (r *SpecialResourceReconciler) coalesceValues(ch *chart.Chart) error {
+
 	var err error
-	nostate.Values, err = chartutil.CoalesceValues(&nostate, r.values.Object)
+
+	if ch.Values, err = chartutil.CoalesceValues(ch, r.values.Object); err != nil {
+		return err
+	}
+
+	rinfo, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&RunInfo)
+	if err != nil {
+		return err
+	}
+
+	ch.Values, err = chartutil.CoalesceValues(ch, rinfo)
+
+	return err
+}
+
+// prepareDrivers renders the states without applying them, the kernel affine ones
+// for every kernel, and returns the decisions about their driver containers. The
+// images of the driver DaemonSets checked are recorded in the status.
+func (r *SpecialResourceReconciler) prepareDrivers(ctx context.Context, nostate chart.Chart, stateYAMLS []*chart.File, kernels []string, postRenderer helmpostrender.PostRenderer) (*resource.Drivers, error) {
+
+	// Only the DaemonSets annotated with check-image have their images
+	// checked, charts without any are not rendered twice unless signed
+	check := r.specialresource.Spec.Signing != nil
+	for _, template := range r.chart.Templates {
+		check = check || strings.Contains(string(template.Data), "specialresource.openshift.io/check-image")
+	}
+	if !check {
+		return nil, nil
+	}
+
+	var manifests []resource.Manifest
+
+	for _, stateYAML := range stateYAMLS {
+
+		step := r.stateChart(nostate, stateYAML)
+
+		for _, kernelFullVersion := range kernels {
+
+			setRunInfoKernel(kernelFullVersion)
+
+			if err := r.coalesceValues(&step); err != nil {
+				return nil, err
+			}
+
+			// The state fails again when it is run, its builds fall back
+			// to the ImagePullBackOff of the DaemonSets
+			manifest, err := r.Helmer.Template(step, step.Values, r.specialresource.Spec.Namespace, postRenderer)
+			if err != nil {
+				log.Error(err, "Cannot render state to prepare its driver containers", "State", stateYAML.Name, "kernel", kernelFullVersion)
+			} else {
+				manifests = append(manifests, resource.Manifest{
+					State:             state.Name(stateYAML, r.specialresource.Name),
+					KernelFullVersion: kernelFullVersion,
+					YAML:              []byte(manifest),
+				})
+			}
+
+			// A state that is not kernel affine is rendered once
+			if !isKernelAffine(stateYAML) {
+				break
+			}
+		}
+	}
+
+	drivers, images, err := r.Creator.PrepareDrivers(ctx, &r.specialresource, manifests)
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare the driver containers: %w", err)
+	}
+
+	// Record whether the driver images were found in the registry and the
+	// build objects are run or skipped, a dry run leaves the status alone
+	if len(images) > 0 && !r.specialresource.Spec.DryRun {
+		r.StatusUpdater.UpdateDriverImages(ctx, &r.specialresource, images)
+	}
+
+	return drivers, nil
+}
+
+// validateValues validates the values of the chart merged with the runtime
+// information against their schemas and records the outcome in the ValuesInvalid
+// condition, the chart is not reconciled if they are invalid.
+func (r *SpecialResourceReconciler) validateValues(ctx context.Context) error {
+
+	merged, err := chartutil.CoalesceValues(&r.chart, r.values.Object)
 	if err != nil {
 		return err
 	}
@@ -269,22 +461,36 @@ func ReconcileChartStates(ctx context.Context, r *SpecialResourceReconciler) err
 		return err
 	}
 
-	nostate.Values, err = chartutil.CoalesceValues(&nostate, rinfo)
+	merged, err = chartutil.CoalesceValues(&chart.Chart{Values: merged}, rinfo)
 	if err != nil {
 		return err
 	}
 
-	return r.Helmer.Run(
-		ctx,
-		nostate,
-		nostate.Values,
-		&r.specialresource,
-		r.specialresource.Name,
-		r.specialresource.Spec.Namespace,
-		r.specialresource.Spec.NodeSelector,
-		RunInfo.KernelFullVersion,
-		RunInfo.OperatingSystemDecimal,
-		false)
+	err = values.Validate(&r.chart, merged)
+
+	var verr *values.ValidationError
+	if errors.As(err, &verr) {
+		r.StatusUpdater.SetCondition(ctx, &r.specialresource, metav1.Condition{
+			Type:    srov1beta1.ConditionValuesInvalid,
+			Status:  metav1.ConditionTrue,
+			Reason:  "SchemaViolation",
+			Message: "invalid values at " + strings.Join(verr.Pointers(), ", "),
+		})
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	r.StatusUpdater.SetCondition(ctx, &r.specialresource, metav1.Condition{
+		Type:    srov1beta1.ConditionValuesInvalid,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Valid",
+		Message: "values match their schemas",
+	})
+
+	return nil
 }
 
 func createSpecialResourceNamespace(ctx context.Context, r *SpecialResourceReconciler) error {
@@ -306,7 +512,9 @@ metadata:
 		ns = append(ns, add...)
 	}
 
-	if err := r.Creator.CreateFromYAML(ctx, ns, false, &r.specialresource, r.specialresource.Name, "", nil, "", ""); err != nil {
+	res, err := r.Creator.CreateFromYAML(ctx, ns, false, &r.specialresource, r.specialresource.Name, "", nil, "", "", nil)
+	r.addResult(ctx, res)
+	if err != nil {
 		log.Info("Cannot reconcile specialresource namespace, something went horribly wrong")
 		return err
 	}
@@ -316,6 +524,19 @@ metadata:
 
 // ReconcileChart Reconcile Hardware Configurations
 func ReconcileChart(ctx context.Context, r *SpecialResourceReconciler) error {
+	// Forget the changes found by a previous reconciliation that failed
+	r.pendingChanges = nil
+	r.drift = nil
+
+	// Catch mistakes in the values before anything is created
+	if err := r.validateValues(ctx); err != nil {
+		return fmt.Errorf("invalid values: %w", err)
+	}
+
+	if _, err := r.nextMaintenanceWindow(); err != nil {
+		return fmt.Errorf("invalid maintenance windows: %w", err)
+	}
+
 	// Leave this here, this is crucial for all following work
 	// Creating and setting the working namespace for the specialresource
 	// specialresource name == namespace if not metadata.namespace is set
@@ -323,13 +544,57 @@ func ReconcileChart(ctx context.Context, r *SpecialResourceReconciler) error {
 		return fmt.Errorf("could not create the SpecialResource's namespace: %w", err)
 	}
 
-	if err := createImagePullerRoleBinding(ctx, r); err != nil {
-		return fmt.Errorf("could not create ImagePuller RoleBinding: %w", err)
+	// A dry run only applies the chart in server dry-run mode, the nodes and
+	// the status are left as they are
+	dryRun := r.specialresource.Spec.DryRun
+
+	if !dryRun {
+		if err := createImagePullerRoleBinding(ctx, r); err != nil {
+			return fmt.Errorf("could not create ImagePuller RoleBinding: %w", err)
+		}
+
+		// Taint the nodes whose kernel changed or whose Pods failed before the
+		// states are reconciled again
+		if err := r.taintNodes(ctx, false); err != nil {
+			return fmt.Errorf("cannot taint nodes: %w", err)
+		}
 	}
 
-	if err := ReconcileChartStates(ctx, r); err != nil {
+	err := ReconcileChartStates(ctx, r)
+	if err != nil {
 		return fmt.Errorf("cannot reconcile hardware states: %w", err)
 	}
 
-	return nil
+	if dryRun {
+		log.Info("Dry run done", "changes", len(r.dryRunChanges))
+		return nil
+	}
+
+	if err := r.taintNodes(ctx, true); err != nil {
+		return fmt.Errorf("cannot taint nodes: %w", err)
+	}
+
+	pending := r.updatePendingChanges(ctx)
+	if _, ok := requeueAfter(pending); pending != nil && !ok {
+		return fmt.Errorf("cannot update pending changes: %w", pending)
+	}
+
+	// All states were reconciled with the rollout token, the next
+	// reconciliations do not force the builds anymore. A rebuild deferred
+	// to the next maintenance window keeps the token unobserved.
+	if token := r.specialresource.Spec.RolloutToken; pending == nil && token != r.specialresource.Status.ObservedRolloutToken {
+		r.StatusUpdater.UpdateObservedRolloutToken(ctx, &r.specialresource, token)
+	}
+
+	// Compare the live objects with the chart again later
+	pending = minRequeue(pending, r.updateDrift(ctx))
+
+	if err := r.upgradeNodes(ctx); err != nil {
+		if _, ok := requeueAfter(err); !ok {
+			return fmt.Errorf("cannot upgrade nodes: %w", err)
+		}
+		pending = minRequeue(pending, err)
+	}
+
+	return pending
 }
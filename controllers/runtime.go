@@ -7,6 +7,7 @@ import (
 	"time"
 
 	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/helmer"
 	"github.com/openshift-psap/special-resource-operator/pkg/proxy"
 	"github.com/openshift-psap/special-resource-operator/pkg/upgrade"
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
@@ -135,11 +136,22 @@ func getRuntimeInformation(ctx context.Context, r *SpecialResourceReconciler) er
 		return fmt.Errorf("failed to get Proxy Configuration: %w", err)
 	}
 
-	r.specialresource.DeepCopyInto(&RunInfo.SpecialResource)
+	setRuntimeSpecialResource(&r.specialresource)
 
 	return nil
 }
 
+// setRuntimeSpecialResource copies sr to RunInfo without the credentials of its
+// repositories, RunInfo ends up in the values of every template and in debug output.
+func setRuntimeSpecialResource(sr *srov1beta1.SpecialResource) {
+	sr.DeepCopyInto(&RunInfo.SpecialResource)
+
+	helmer.Redact(&RunInfo.SpecialResource.Spec.Chart.Repository)
+	for i := range RunInfo.SpecialResource.Spec.Dependencies {
+		helmer.Redact(&RunInfo.SpecialResource.Spec.Dependencies[i].Repository)
+	}
+}
+
 func retryGetPushSecretName(ctx context.Context, r *SpecialResourceReconciler) (string, error) {
 	for i := 0; i < 3; i++ {
 		time.Sleep(2 * time.Second)
@@ -5,29 +5,69 @@ import (
 	"fmt"
 
 	"github.com/openshift-psap/special-resource-operator/pkg/state"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // If resource available, label the nodes according to the current state
 // if e.g driver-container ready -> specialresource.openshift.io/driver-container:ready
-func (r *SpecialResourceReconciler) labelNodesAccordingToState(ctx context.Context, nodeSelector map[string]string) error {
+// A node is only labeled if the DaemonSets of the state selecting it, e.g. the
+// driver container of its kernel, run a ready Pod on it, and the label is removed
+// once one of them is not ready anymore.
+func (r *SpecialResourceReconciler) labelNodesAccordingToState(ctx context.Context, names []types.NamespacedName) error {
 
-	nodeList, err := r.KubeClient.GetNodesByLabels(ctx, r.specialresource.Spec.NodeSelector)
-	if err != nil {
+	// Unlike GetNodesByLabels, keep the tainted and cordoned nodes whose Pods fail
+	nodeList := &v1.NodeList{}
+	if err := r.KubeClient.List(ctx, nodeList, client.MatchingLabels(r.specialresource.Spec.NodeSelector)); err != nil {
 		return fmt.Errorf("failed to get nodes with labels in labelNodesAccordingToState: %w", err)
 	}
 
+	daemonSets := make([]appsv1.DaemonSet, 0, len(names))
+	for _, name := range names {
+		ds := appsv1.DaemonSet{}
+		if err := r.KubeClient.Get(ctx, name, &ds); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("cannot get DaemonSet %s: %w", name, err)
+		}
+		daemonSets = append(daemonSets, ds)
+	}
+
+	var readyPods map[string]map[string]bool
+	if len(daemonSets) > 0 {
+		var err error
+		if readyPods, err = r.readyDaemonSetPods(ctx); err != nil {
+			return err
+		}
+	}
+
 	for _, node := range nodeList.Items {
 		labels := node.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+
+		ready := daemonSetsReady(&node, daemonSets, readyPods[node.Name])
+		if ready == (labels[state.CurrentName] == "Ready") {
+			continue
+		}
 
 		// Label missing update the Node to advance to the next state
 		updated := node.DeepCopy()
 
-		labels[state.CurrentName] = "Ready"
+		if ready {
+			labels[state.CurrentName] = "Ready"
+		} else {
+			delete(labels, state.CurrentName)
+		}
 
 		updated.SetLabels(labels)
 
-		if err = r.KubeClient.Update(ctx, updated); err != nil {
+		if err := r.KubeClient.Update(ctx, updated); err != nil {
 			if apierrors.IsForbidden(err) {
 				return fmt.Errorf("forbidden - check Role, ClusterRole and Bindings: %w", err)
 			}
@@ -40,8 +80,11 @@ func (r *SpecialResourceReconciler) labelNodesAccordingToState(ctx context.Conte
 			return fmt.Errorf("couldn't Update Node: %w", err)
 		}
 
-		log.Info("NODE", "Setting Label ", state.CurrentName, "on ", updated.GetName())
-
+		if ready {
+			log.Info("NODE", "Setting Label ", state.CurrentName, "on ", updated.GetName())
+		} else {
+			log.Info("NODE", "Removing Label ", state.CurrentName, "from ", updated.GetName())
+		}
 	}
 
 	return nil
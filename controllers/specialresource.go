@@ -10,10 +10,13 @@ import (
 
 	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/internal/controllers/finalizers"
+	"github.com/openshift-psap/special-resource-operator/pkg/dryrun"
+	"github.com/openshift-psap/special-resource-operator/pkg/helmer"
 	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/chart"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -66,33 +69,47 @@ func SpecialResourcesReconcile(ctx context.Context, r *SpecialResourceReconciler
 	}
 
 	r.parent = specialresources.Items[request]
+	r.dryRunChanges = nil
 
 	// Execute finalization logic if CR is being deleted
 	isMarkedToBeDeleted := r.parent.GetDeletionTimestamp() != nil
 	if isMarkedToBeDeleted {
 		r.specialresource = r.parent
 		log.Info("Marked to be deleted, reconciling finalizer")
+		r.Creator.Forget(&r.specialresource)
 		err = r.Finalizer.Finalize(ctx, &r.specialresource)
 		return reconcile.Result{}, err
 	}
 
 	log = r.Log.WithName(utils.Print(r.parent.Name, utils.Green))
 
+	// Leave the objects of a suspended SpecialResource untouched, e.g. while
+	// one of its DaemonSets is patched by hand
+	by := r.suspendedBy(specialresources)
+	r.updateSuspended(ctx, &r.parent, by)
+	if by != "" {
+		log.Info("RECONCILE SKIPPED: Suspended", "by", by)
+		return reconcile.Result{}, nil
+	}
+
 	log.Info("Resolving Dependencies")
 
-	pchart, err := r.Helmer.Load(r.parent.Spec.Chart)
+	pchart, err := r.loadChart(ctx, r.parent.Spec.Chart)
 	if err != nil {
 		r.StatusUpdater.UpdateWithState(ctx, &r.parent, fmt.Sprintf("%v", err))
 		return reconcile.Result{}, err
 	}
 
+	// Set when the parent or one of its dependencies has to be reconciled again later
+	var requeue error
+
 	// Only one level dependency support for now
 	for _, r.dependency = range r.parent.Spec.Dependencies {
 
 		log = r.Log.WithName(utils.Print(r.dependency.Name, utils.Purple))
 		log.Info("Getting Dependency")
 
-		cchart, err := r.Helmer.Load(r.dependency.HelmChart)
+		cchart, err := r.loadChart(ctx, r.dependency.HelmChart)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -113,6 +130,14 @@ func SpecialResourcesReconcile(ctx context.Context, r *SpecialResourceReconciler
 		// Assign the specialresource to the reconciler object
 		if child, err = getDependencyFrom(specialresources, r.dependency.Name); err != nil {
 			log.Error(err, "Could not get SpecialResource dependency")
+			if r.parent.Spec.DryRun {
+				r.dryRunChanges = append(r.dryRunChanges, dryrun.Change{
+					Kind:  "SpecialResource",
+					Name:  r.dependency.Name,
+					Error: "the dependency does not exist, it is created before the chart is reconciled",
+				})
+				continue
+			}
 			if err = createSpecialResourceFrom(ctx, r, cchart, r.dependency.HelmChart); err != nil {
 				log.Error(err, "RECONCILE REQUEUE: Dependency creation failed ")
 				return reconcile.Result{Requeue: true}, nil
@@ -120,7 +145,26 @@ func SpecialResourcesReconcile(ctx context.Context, r *SpecialResourceReconciler
 			// We need to fetch the newly created SpecialResources, reconciling
 			return reconcile.Result{}, nil
 		}
-		if err := ReconcileSpecialResourceChart(ctx, r, child, cchart, r.dependency.Set); err != nil {
+		if child.IsSuspended() {
+			log.Info("Skipping suspended dependency")
+			r.updateSuspended(ctx, &child, child.Name)
+			continue
+		}
+
+		// The dependencies are reconciled with the values of the parent, its
+		// dry run covers them too
+		if r.parent.Spec.DryRun {
+			child.Spec.DryRun = true
+		}
+
+		err = ReconcileSpecialResourceChart(ctx, r, child, cchart, r.dependency.Set)
+		if _, ok := requeueAfter(err); ok {
+			// Nodes upgraded or changes deferred for the dependency do not
+			// block the parent, the earliest requeue wins
+			requeue = minRequeue(requeue, err)
+			continue
+		}
+		if err != nil {
 			// We do not want a stacktrace here, errors.Wrap already created
 			// breadcrumb of errors to follow. Just sprintf with %v rather than %+v
 			r.StatusUpdater.UpdateWithState(ctx, &child, fmt.Sprintf("%v", err))
@@ -132,7 +176,12 @@ func SpecialResourcesReconcile(ctx context.Context, r *SpecialResourceReconciler
 	}
 
 	log.Info("Reconciling Parent")
-	if err := ReconcileSpecialResourceChart(ctx, r, r.parent, pchart, r.parent.Spec.Set); err != nil {
+	err = ReconcileSpecialResourceChart(ctx, r, r.parent, pchart, r.parent.Spec.Set)
+	if _, ok := requeueAfter(err); ok {
+		requeue = minRequeue(requeue, err)
+		err = nil
+	}
+	if err != nil {
 		// We do not want a stacktrace here, errors.Wrap already created
 		// breadcrumb of errors to follow. Just sprintf with %v rather than %+v
 		r.StatusUpdater.UpdateWithState(ctx, &r.parent, fmt.Sprintf("%v", err))
@@ -141,10 +190,67 @@ func SpecialResourcesReconcile(ctx context.Context, r *SpecialResourceReconciler
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	if err = r.updateDryRun(ctx); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if after, ok := requeueAfter(requeue); ok {
+		log.Info("RECONCILE REQUEUE: "+requeue.Error(), "after", after)
+		return reconcile.Result{RequeueAfter: after}, nil
+	}
+
 	log.Info("RECONCILE SUCCESS: All resources done")
+
+	// A moved branch or tag is a new chart revision, fetch it again
+	if helmer.TracksGitRef(r.parent.Spec.Chart) {
+		return reconcile.Result{RequeueAfter: helmer.GitPollInterval}, nil
+	}
+
 	return reconcile.Result{}, nil
 }
 
+// loadChart loads the chart of the parent or of one of its dependencies with
+// the default verification policy, if the chart has none. The outcome of the
+// verification is recorded in the ChartVerified condition of the parent, a
+// chart failing verification is never installed.
+func (r *SpecialResourceReconciler) loadChart(ctx context.Context, spec helmerv1beta1.HelmChart) (*chart.Chart, error) {
+
+	if spec.Verify == nil {
+		spec.Verify = r.ChartVerify
+	}
+
+	// The references of the dependencies are checked against the namespace
+	// of the parent, which declares them
+	namespace := r.parent.Spec.Namespace
+	if namespace == "" {
+		namespace = r.parent.Name
+	}
+
+	loaded, err := r.Helmer.Load(ctx, spec, namespace)
+
+	if errors.Is(err, helmer.ErrVerification) {
+		r.StatusUpdater.SetCondition(ctx, &r.parent, metav1.Condition{
+			Type:    srov1beta1.ConditionChartVerified,
+			Status:  metav1.ConditionFalse,
+			Reason:  "VerificationFailed",
+			Message: err.Error(),
+		})
+		log.Error(err, "Refusing to install chart", "chart", spec.Name)
+		return nil, err
+	}
+
+	if err == nil && spec.Verify != nil {
+		r.StatusUpdater.SetCondition(ctx, &r.parent, metav1.Condition{
+			Type:    srov1beta1.ConditionChartVerified,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Verified",
+			Message: fmt.Sprintf("chart %s verified", spec.Name),
+		})
+	}
+
+	return loaded, err
+}
+
 func TemplateFragment(sr interface{}) error {
 	spec, err := json.Marshal(sr)
 	if err != nil {
@@ -197,6 +303,26 @@ func ReconcileSpecialResourceChart(ctx context.Context, r *SpecialResourceReconc
 
 	logRuntimeInformation()
 
+	if err := templateSpecialResource(r); err != nil {
+		return err
+	}
+
+	// Add a finalizer to CR if it does not already have one
+	if !utils.StringSliceContains(r.specialresource.GetFinalizers(), finalizers.FinalizerString) {
+		if err := r.Finalizer.AddToSpecialResource(ctx, &r.specialresource); err != nil {
+			log.Error(err, "Failed to add finalizer")
+			return err
+		}
+	}
+
+	// Reconcile the special resource chart
+	return ReconcileChart(ctx, r)
+}
+
+// templateSpecialResource templates the spec of the SpecialResource and its values
+// with the runtime information.
+func templateSpecialResource(r *SpecialResourceReconciler) error {
+
 	for idx, dep := range r.specialresource.Spec.Dependencies {
 		if dep.Set.Object == nil {
 			dep.Set.Object = make(map[string]interface{})
@@ -242,20 +368,7 @@ func ReconcileSpecialResourceChart(ctx context.Context, r *SpecialResourceReconc
 		return err
 	}
 
-	if err := TemplateFragment(&r.values); err != nil {
-		return err
-	}
-
-	// Add a finalizer to CR if it does not already have one
-	if !utils.StringSliceContains(r.specialresource.GetFinalizers(), finalizers.FinalizerString) {
-		if err := r.Finalizer.AddToSpecialResource(ctx, &r.specialresource); err != nil {
-			log.Error(err, "Failed to add finalizer")
-			return err
-		}
-	}
-
-	// Reconcile the special resource chart
-	return ReconcileChart(ctx, r)
+	return TemplateFragment(&r.values)
 }
 
 func FindSR(a []srov1beta1.SpecialResource, x string, by string) (int, bool) {
@@ -314,7 +427,7 @@ func createSpecialResourceFrom(ctx context.Context, r *SpecialResourceReconciler
 
 	log.Info("Creating SpecialResource: " + ch.Files[idx].Name)
 
-	if err := r.Creator.CreateFromYAML(
+	if _, err := r.Creator.CreateFromYAML(
 		ctx,
 		ch.Files[idx].Data,
 		false,
@@ -322,7 +435,7 @@ func createSpecialResourceFrom(ctx context.Context, r *SpecialResourceReconciler
 		r.specialresource.Name,
 		r.specialresource.Namespace,
 		r.specialresource.Spec.NodeSelector,
-		"", ""); err != nil {
+		"", "", nil); err != nil {
 		log.Info("Cannot create, something went horribly wrong")
 		return err
 	}
@@ -41,10 +41,13 @@ import (
 	"github.com/openshift-psap/special-resource-operator/pkg/assets"
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
 	"github.com/openshift-psap/special-resource-operator/pkg/cluster"
+	"github.com/openshift-psap/special-resource-operator/pkg/dryrun"
 	"github.com/openshift-psap/special-resource-operator/pkg/filter"
 	"github.com/openshift-psap/special-resource-operator/pkg/helmer"
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/pkg/kernel"
 	"github.com/openshift-psap/special-resource-operator/pkg/metrics"
+	"github.com/openshift-psap/special-resource-operator/pkg/nodeupgrade"
 	"github.com/openshift-psap/special-resource-operator/pkg/poll"
 	"github.com/openshift-psap/special-resource-operator/pkg/proxy"
 	"github.com/openshift-psap/special-resource-operator/pkg/resource"
@@ -76,12 +79,19 @@ type SpecialResourceReconciler struct {
 	KernelData    kernel.KernelData
 	ProxyAPI      proxy.ProxyAPI
 	KubeClient    clients.ClientsInterface
+	NodeUpgrader  nodeupgrade.NodeUpgrader
+
+	// ChartVerify is the verification policy of charts that do not set their own
+	ChartVerify *helmerv1beta1.HelmVerify
 
 	specialresource srov1beta1.SpecialResource
 	parent          srov1beta1.SpecialResource
 	chart           chart.Chart
 	values          unstructured.Unstructured
 	dependency      srov1beta1.SpecialResourceDependency
+	pendingChanges  []srov1beta1.PendingChangeStatus
+	drift           []srov1beta1.DriftStatus
+	dryRunChanges   []dryrun.Change
 }
 
 // Reconcile Reconiliation entry point
@@ -99,7 +109,7 @@ func (r *SpecialResourceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	// Reconcile all specialresources
-	if res, err = SpecialResourcesReconcile(ctx, r, req); err != nil || res.Requeue {
+	if res, err = SpecialResourcesReconcile(ctx, r, req); err != nil || !res.IsZero() {
 		return res, errors.Wrap(err, "RECONCILE ERROR: Cannot reconcile special resource")
 	}
 
@@ -116,6 +126,11 @@ func (r *SpecialResourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	// The node upgrades drain the Pods of a node
+	if err = mgr.GetFieldIndexer().IndexField(context.Background(), &v1.Pod{}, nodeupgrade.PodNodeNameField, nodeupgrade.PodNodeName); err != nil {
+		return err
+	}
+
 	if platform == "OCP" {
 		return ctrl.NewControllerManagedBy(mgr).
 			For(&srov1beta1.SpecialResource{}).
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// suspendedBy returns the name of the SpecialResource suspending the reconciliation
+// of the parent, the parent itself or one of its dependencies suspending its
+// dependents, or an empty string if it is not suspended.
+func (r *SpecialResourceReconciler) suspendedBy(specialresources *srov1beta1.SpecialResourceList) string {
+
+	if r.parent.IsSuspended() {
+		return r.parent.Name
+	}
+
+	for _, dependency := range r.parent.Spec.Dependencies {
+		child, err := getDependencyFrom(specialresources, dependency.Name)
+		if err != nil {
+			continue
+		}
+		if child.IsSuspended() && child.Spec.SuspendDependents {
+			return child.Name
+		}
+	}
+
+	return ""
+}
+
+// updateSuspended records whether the reconciliation of sr is suspended by the
+// SpecialResource by in its Suspended condition, if it changed.
+func (r *SpecialResourceReconciler) updateSuspended(ctx context.Context, sr *srov1beta1.SpecialResource, by string) {
+
+	condition := metav1.Condition{
+		Type:    srov1beta1.ConditionSuspended,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Resumed",
+		Message: "the SpecialResource is reconciled",
+	}
+
+	switch by {
+	case "":
+		// Only resumed SpecialResources get the condition
+		if meta.FindStatusCondition(sr.Status.Conditions, srov1beta1.ConditionSuspended) == nil {
+			return
+		}
+	case sr.Name:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Suspended"
+		condition.Message = "the SpecialResource is suspended by spec.suspend or the " + srov1beta1.PausedAnnotation + " annotation"
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "DependencySuspended"
+		condition.Message = fmt.Sprintf("the dependency %s is suspended", by)
+	}
+
+	current := meta.FindStatusCondition(sr.Status.Conditions, srov1beta1.ConditionSuspended)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+		return
+	}
+
+	r.StatusUpdater.SetCondition(ctx, sr, condition)
+}
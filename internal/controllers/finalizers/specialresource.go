@@ -8,12 +8,15 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	"github.com/openshift-psap/special-resource-operator/pkg/nodeupgrade"
 	"github.com/openshift-psap/special-resource-operator/pkg/poll"
 	"github.com/openshift-psap/special-resource-operator/pkg/state"
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
 	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -74,7 +77,7 @@ func (srf *specialResourceFinalizer) Finalize(ctx context.Context, sr *v1beta1.S
 	return nil
 }
 
-func (srf *specialResourceFinalizer) finalizeNodes(ctx context.Context, sr *v1beta1.SpecialResource, remove string) error {
+func (srf *specialResourceFinalizer) finalizeNodes(ctx context.Context, sr *v1beta1.SpecialResource, remove ...string) error {
 	nodes, err := srf.kubeClient.GetNodesByLabels(ctx, sr.Spec.NodeSelector)
 	if err != nil {
 		return fmt.Errorf("could not fetch nodes: %v", err)
@@ -85,13 +88,23 @@ func (srf *specialResourceFinalizer) finalizeNodes(ctx context.Context, sr *v1be
 		update := make(map[string]string)
 		// Remove all specialresource labels
 		for k, v := range labels {
-			if strings.Contains(k, remove) {
+			if containsAny(k, remove) {
 				continue
 			}
 			update[k] = v
 		}
 
 		node.SetLabels(update)
+
+		// Remove the not ready taint of the SpecialResource
+		taints := node.Spec.Taints[:0:0]
+		for _, taint := range node.Spec.Taints {
+			if taint.Key != state.NotReadyTaint(sr.Name).Key {
+				taints = append(taints, taint)
+			}
+		}
+		node.Spec.Taints = taints
+
 		err := srf.kubeClient.Update(ctx, &node)
 		if apierrors.IsForbidden(err) {
 			return errors.Wrap(err, "forbidden check Role, ClusterRole and Bindings for operator %s")
@@ -104,13 +117,56 @@ func (srf *specialResourceFinalizer) finalizeNodes(ctx context.Context, sr *v1be
 	return nil
 }
 
+// uncordonNodes uncordons the nodes cordoned by the upgrades still in progress.
+func (srf *specialResourceFinalizer) uncordonNodes(ctx context.Context, sr *v1beta1.SpecialResource) error {
+
+	for _, upgrade := range sr.Status.NodeUpgrades {
+		if !upgrade.Cordoned || !nodeupgrade.InProgress(upgrade) {
+			continue
+		}
+
+		node := &v1.Node{}
+		err := srf.kubeClient.Get(ctx, types.NamespacedName{Name: upgrade.Node}, node)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not get node %s: %w", upgrade.Node, err)
+		}
+
+		srf.log.Info("Uncordoning node of an interrupted upgrade", "node", upgrade.Node)
+
+		node.Spec.Unschedulable = false
+		delete(node.Labels, nodeupgrade.LabelPrefix+sr.Name)
+
+		if err = srf.kubeClient.Update(ctx, node); err != nil {
+			return fmt.Errorf("could not uncordon node %s: %w", upgrade.Node, err)
+		}
+	}
+
+	return nil
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
+
 func (srf *specialResourceFinalizer) finalizeSpecialResource(ctx context.Context, sr *v1beta1.SpecialResource) error {
 	// TODO(user): Add the cleanup steps that the operator
 	// needs to do before the CR can be deleted. Examples
 	// of finalizers include performing backups and deleting
 	// resources that are not owned by this CR, like a PVC.
 
-	if err := srf.finalizeNodes(ctx, sr, "specialresource.openshift.io/state-"+sr.Name); err != nil {
+	if err := srf.uncordonNodes(ctx, sr); err != nil {
+		return err
+	}
+
+	if err := srf.finalizeNodes(ctx, sr, "specialresource.openshift.io/state-"+sr.Name, nodeupgrade.LabelPrefix+sr.Name); err != nil {
 		return err
 	}
 
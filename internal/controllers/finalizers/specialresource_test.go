@@ -12,6 +12,7 @@ import (
 	"github.com/openshift-psap/special-resource-operator/internal/controllers/finalizers"
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
 	"github.com/openshift-psap/special-resource-operator/pkg/poll"
+	"github.com/openshift-psap/special-resource-operator/pkg/state"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -97,6 +98,9 @@ var _ = Describe("specialResourceFinalizer_Finalize", func() {
 					ObjectMeta: metav1.ObjectMeta{
 						Labels: map[string]string{"specialresource.openshift.io/state-sr-name": "some-value"},
 					},
+					Spec: v1.NodeSpec{
+						Taints: []v1.Taint{state.NotReadyTaint(srName), {Key: "other", Effect: v1.TaintEffectNoSchedule}},
+					},
 				},
 			},
 		}
@@ -105,6 +109,9 @@ var _ = Describe("specialResourceFinalizer_Finalize", func() {
 			ObjectMeta: metav1.ObjectMeta{
 				Labels: make(map[string]string),
 			},
+			Spec: v1.NodeSpec{
+				Taints: []v1.Taint{{Key: "other", Effect: v1.TaintEffectNoSchedule}},
+			},
 		}
 
 		ns := unstructured.Unstructured{}
@@ -144,4 +151,45 @@ var _ = Describe("specialResourceFinalizer_Finalize", func() {
 		err := f.Finalize(context.TODO(), sr)
 		Expect(err).NotTo(HaveOccurred())
 	})
+	It("should uncordon the nodes of an interrupted upgrade", func() {
+		const srName = "sr-name"
+
+		sr := &v1beta1.SpecialResource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       srName,
+				Finalizers: []string{finalizers.FinalizerString},
+			},
+			Spec: v1beta1.SpecialResourceSpec{Namespace: srName},
+			Status: v1beta1.SpecialResourceStatus{
+				NodeUpgrades: []v1beta1.NodeUpgradeStatus{
+					{Node: "worker-0", State: v1beta1.NodeUpgradeDraining, Cordoned: true},
+					{Node: "worker-1", State: v1beta1.NodeUpgradeDone, Cordoned: true},
+				},
+			},
+		}
+
+		gomock.InOrder(
+			mockKubeClient.
+				EXPECT().
+				Get(context.TODO(), types.NamespacedName{Name: "worker-0"}, &v1.Node{}).
+				Do(func(_ context.Context, _ types.NamespacedName, node *v1.Node) {
+					node.Name = "worker-0"
+					node.Labels = map[string]string{"specialresource.openshift.io/upgrade-sr-name": v1beta1.NodeUpgradeDraining}
+					node.Spec.Unschedulable = true
+				}),
+			mockKubeClient.
+				EXPECT().
+				Update(context.TODO(), gomock.Any()).
+				Do(func(_ context.Context, node *v1.Node) {
+					Expect(node.Spec.Unschedulable).To(BeFalse())
+					Expect(node.Labels).To(BeEmpty())
+				}),
+			mockKubeClient.EXPECT().GetNodesByLabels(context.TODO(), gomock.Any()).Return(&v1.NodeList{}, nil),
+			mockKubeClient.EXPECT().Get(context.TODO(), types.NamespacedName{Name: srName}, gomock.Any()),
+			mockKubeClient.EXPECT().Update(context.TODO(), gomock.Any()),
+		)
+
+		err := finalizers.NewSpecialResourceFinalizer(mockKubeClient, mockPollActions).Finalize(context.TODO(), sr)
+		Expect(err).NotTo(HaveOccurred())
+	})
 })
@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	v1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // MockStatusUpdater is a mock of StatusUpdater interface.
@@ -35,6 +36,90 @@ func (m *MockStatusUpdater) EXPECT() *MockStatusUpdaterMockRecorder {
 	return m.recorder
 }
 
+// SetCondition mocks base method.
+func (m *MockStatusUpdater) SetCondition(arg0 context.Context, arg1 *v1beta1.SpecialResource, arg2 v1.Condition) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetCondition", arg0, arg1, arg2)
+}
+
+// SetCondition indicates an expected call of SetCondition.
+func (mr *MockStatusUpdaterMockRecorder) SetCondition(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCondition", reflect.TypeOf((*MockStatusUpdater)(nil).SetCondition), arg0, arg1, arg2)
+}
+
+// UpdateDrift mocks base method.
+func (m *MockStatusUpdater) UpdateDrift(arg0 context.Context, arg1 *v1beta1.SpecialResource, arg2 []v1beta1.DriftStatus, arg3 v1.Condition) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateDrift", arg0, arg1, arg2, arg3)
+}
+
+// UpdateDrift indicates an expected call of UpdateDrift.
+func (mr *MockStatusUpdaterMockRecorder) UpdateDrift(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDrift", reflect.TypeOf((*MockStatusUpdater)(nil).UpdateDrift), arg0, arg1, arg2, arg3)
+}
+
+// UpdateDriverImages mocks base method.
+func (m *MockStatusUpdater) UpdateDriverImages(arg0 context.Context, arg1 *v1beta1.SpecialResource, arg2 []v1beta1.DriverImageStatus) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateDriverImages", arg0, arg1, arg2)
+}
+
+// UpdateDriverImages indicates an expected call of UpdateDriverImages.
+func (mr *MockStatusUpdaterMockRecorder) UpdateDriverImages(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDriverImages", reflect.TypeOf((*MockStatusUpdater)(nil).UpdateDriverImages), arg0, arg1, arg2)
+}
+
+// UpdateNodeUpgrades mocks base method.
+func (m *MockStatusUpdater) UpdateNodeUpgrades(arg0 context.Context, arg1 *v1beta1.SpecialResource, arg2 []v1beta1.NodeUpgradeStatus) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateNodeUpgrades", arg0, arg1, arg2)
+}
+
+// UpdateNodeUpgrades indicates an expected call of UpdateNodeUpgrades.
+func (mr *MockStatusUpdaterMockRecorder) UpdateNodeUpgrades(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNodeUpgrades", reflect.TypeOf((*MockStatusUpdater)(nil).UpdateNodeUpgrades), arg0, arg1, arg2)
+}
+
+// UpdateObservedRolloutToken mocks base method.
+func (m *MockStatusUpdater) UpdateObservedRolloutToken(arg0 context.Context, arg1 *v1beta1.SpecialResource, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateObservedRolloutToken", arg0, arg1, arg2)
+}
+
+// UpdateObservedRolloutToken indicates an expected call of UpdateObservedRolloutToken.
+func (mr *MockStatusUpdaterMockRecorder) UpdateObservedRolloutToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateObservedRolloutToken", reflect.TypeOf((*MockStatusUpdater)(nil).UpdateObservedRolloutToken), arg0, arg1, arg2)
+}
+
+// UpdatePendingChanges mocks base method.
+func (m *MockStatusUpdater) UpdatePendingChanges(arg0 context.Context, arg1 *v1beta1.SpecialResource, arg2 []v1beta1.PendingChangeStatus, arg3 *v1.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatePendingChanges", arg0, arg1, arg2, arg3)
+}
+
+// UpdatePendingChanges indicates an expected call of UpdatePendingChanges.
+func (mr *MockStatusUpdaterMockRecorder) UpdatePendingChanges(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingChanges", reflect.TypeOf((*MockStatusUpdater)(nil).UpdatePendingChanges), arg0, arg1, arg2, arg3)
+}
+
+// UpdateResolvedImages mocks base method.
+func (m *MockStatusUpdater) UpdateResolvedImages(arg0 context.Context, arg1 *v1beta1.SpecialResource, arg2 []v1beta1.ResolvedImageStatus) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateResolvedImages", arg0, arg1, arg2)
+}
+
+// UpdateResolvedImages indicates an expected call of UpdateResolvedImages.
+func (mr *MockStatusUpdaterMockRecorder) UpdateResolvedImages(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResolvedImages", reflect.TypeOf((*MockStatusUpdater)(nil).UpdateResolvedImages), arg0, arg1, arg2)
+}
+
 // UpdateWithState mocks base method.
 func (m *MockStatusUpdater) UpdateWithState(arg0 context.Context, arg1 *v1beta1.SpecialResource, arg2 string) {
 	m.ctrl.T.Helper()
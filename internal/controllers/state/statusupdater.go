@@ -9,6 +9,8 @@ import (
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -17,6 +19,13 @@ import (
 
 type StatusUpdater interface {
 	UpdateWithState(context.Context, *v1beta1.SpecialResource, string)
+	UpdateDriverImages(context.Context, *v1beta1.SpecialResource, []v1beta1.DriverImageStatus)
+	UpdateResolvedImages(context.Context, *v1beta1.SpecialResource, []v1beta1.ResolvedImageStatus)
+	SetCondition(context.Context, *v1beta1.SpecialResource, metav1.Condition)
+	UpdateObservedRolloutToken(context.Context, *v1beta1.SpecialResource, string)
+	UpdateNodeUpgrades(context.Context, *v1beta1.SpecialResource, []v1beta1.NodeUpgradeStatus)
+	UpdatePendingChanges(context.Context, *v1beta1.SpecialResource, []v1beta1.PendingChangeStatus, *metav1.Time)
+	UpdateDrift(context.Context, *v1beta1.SpecialResource, []v1beta1.DriftStatus, metav1.Condition)
 }
 
 type statusUpdater struct {
@@ -34,6 +43,94 @@ func NewStatusUpdater(kubeClient clients.ClientsInterface) StatusUpdater {
 // UpdateWithState updates sr's Status.State property with state, and updates the object in Kubernetes.
 // TODO(qbarrand) make this function return an error
 func (su *statusUpdater) UpdateWithState(ctx context.Context, sr *v1beta1.SpecialResource, state string) {
+	su.update(ctx, sr, func(status *v1beta1.SpecialResourceStatus) {
+		status.State = state
+	})
+}
+
+// UpdateDriverImages merges images into sr's Status.DriverImages property, replacing
+// the entries of the same image, and updates the object in Kubernetes.
+func (su *statusUpdater) UpdateDriverImages(ctx context.Context, sr *v1beta1.SpecialResource, images []v1beta1.DriverImageStatus) {
+	su.update(ctx, sr, func(status *v1beta1.SpecialResourceStatus) {
+		for _, image := range images {
+			replaced := false
+			for i := range status.DriverImages {
+				if status.DriverImages[i].Image == image.Image {
+					status.DriverImages[i] = image
+					replaced = true
+				}
+			}
+			if !replaced {
+				status.DriverImages = append(status.DriverImages, image)
+			}
+		}
+	})
+}
+
+// UpdateResolvedImages merges images into sr's Status.Images property, replacing the
+// entries of the same container, and updates the object in Kubernetes.
+func (su *statusUpdater) UpdateResolvedImages(ctx context.Context, sr *v1beta1.SpecialResource, images []v1beta1.ResolvedImageStatus) {
+	su.update(ctx, sr, func(status *v1beta1.SpecialResourceStatus) {
+		for _, image := range images {
+			replaced := false
+			for i, s := range status.Images {
+				if s.Kind == image.Kind && s.Namespace == image.Namespace && s.Name == image.Name && s.Container == image.Container {
+					status.Images[i] = image
+					replaced = true
+				}
+			}
+			if !replaced {
+				status.Images = append(status.Images, image)
+			}
+		}
+	})
+}
+
+// SetCondition sets condition in sr's Status.Conditions property, replacing the
+// condition of the same type, and updates the object in Kubernetes.
+func (su *statusUpdater) SetCondition(ctx context.Context, sr *v1beta1.SpecialResource, condition metav1.Condition) {
+	su.update(ctx, sr, func(status *v1beta1.SpecialResourceStatus) {
+		condition.ObservedGeneration = sr.GetGeneration()
+		meta.SetStatusCondition(&status.Conditions, condition)
+	})
+}
+
+// UpdateObservedRolloutToken updates sr's Status.ObservedRolloutToken property with
+// token, and updates the object in Kubernetes.
+func (su *statusUpdater) UpdateObservedRolloutToken(ctx context.Context, sr *v1beta1.SpecialResource, token string) {
+	su.update(ctx, sr, func(status *v1beta1.SpecialResourceStatus) {
+		status.ObservedRolloutToken = token
+	})
+}
+
+// UpdateNodeUpgrades replaces sr's Status.NodeUpgrades property with upgrades, and
+// updates the object in Kubernetes.
+func (su *statusUpdater) UpdateNodeUpgrades(ctx context.Context, sr *v1beta1.SpecialResource, upgrades []v1beta1.NodeUpgradeStatus) {
+	su.update(ctx, sr, func(status *v1beta1.SpecialResourceStatus) {
+		status.NodeUpgrades = upgrades
+	})
+}
+
+// UpdatePendingChanges replaces sr's Status.PendingChanges property with changes and its
+// Status.NextMaintenanceWindow property with next, and updates the object in Kubernetes.
+func (su *statusUpdater) UpdatePendingChanges(ctx context.Context, sr *v1beta1.SpecialResource, changes []v1beta1.PendingChangeStatus, next *metav1.Time) {
+	su.update(ctx, sr, func(status *v1beta1.SpecialResourceStatus) {
+		status.PendingChanges = changes
+		status.NextMaintenanceWindow = next
+	})
+}
+
+// UpdateDrift replaces sr's Status.Drift property with drift, sets condition in its
+// Status.Conditions property, and updates the object in Kubernetes.
+func (su *statusUpdater) UpdateDrift(ctx context.Context, sr *v1beta1.SpecialResource, drift []v1beta1.DriftStatus, condition metav1.Condition) {
+	su.update(ctx, sr, func(status *v1beta1.SpecialResourceStatus) {
+		status.Drift = drift
+		condition.ObservedGeneration = sr.GetGeneration()
+		meta.SetStatusCondition(&status.Conditions, condition)
+	})
+}
+
+func (su *statusUpdater) update(ctx context.Context, sr *v1beta1.SpecialResource, mutate func(*v1beta1.SpecialResourceStatus)) {
 
 	update := v1beta1.SpecialResource{}
 
@@ -45,7 +142,7 @@ func (su *statusUpdater) UpdateWithState(ctx context.Context, sr *v1beta1.Specia
 		return
 	}
 
-	update.Status.State = state
+	mutate(&update.Status)
 	update.DeepCopyInto(sr)
 
 	err = su.kubeClient.StatusUpdate(ctx, sr)
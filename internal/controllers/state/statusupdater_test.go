@@ -2,9 +2,11 @@ package state_test
 
 import (
 	"context"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
 	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/internal/controllers/state"
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
@@ -70,4 +72,249 @@ var _ = Describe("StatusUpdater", func() {
 			state.NewStatusUpdater(mockKubeClient).UpdateWithState(context.TODO(), sr, newState)
 		})
 	})
+
+	Describe("UpdateDriverImages", func() {
+		const srName = "sr-name"
+
+		It("should replace the entries of the same image and keep the others", func() {
+			sr := &v1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: srName},
+			}
+
+			existing := []v1beta1.DriverImageStatus{
+				{Image: "registry/driver:a", Present: false, Build: true},
+				{Image: "registry/driver:b", Present: true},
+			}
+			checked := []v1beta1.DriverImageStatus{
+				{Image: "registry/driver:a", Present: true},
+				{Image: "registry/driver:c", Present: false, Build: true},
+			}
+
+			expected := sr.DeepCopy()
+			expected.Status.DriverImages = []v1beta1.DriverImageStatus{checked[0], existing[1], checked[1]}
+
+			gomock.InOrder(
+				mockKubeClient.
+					EXPECT().
+					Get(context.TODO(), types.NamespacedName{Name: srName}, &v1beta1.SpecialResource{}).
+					Do(func(_ context.Context, _ types.NamespacedName, update *v1beta1.SpecialResource) {
+						sr.DeepCopyInto(update)
+						update.Status.DriverImages = existing
+					}),
+				mockKubeClient.EXPECT().StatusUpdate(context.TODO(), expected),
+			)
+
+			state.NewStatusUpdater(mockKubeClient).UpdateDriverImages(context.TODO(), sr, checked)
+		})
+	})
+
+	Describe("UpdateResolvedImages", func() {
+		const srName = "sr-name"
+
+		It("should replace the entries of the same container and keep the others", func() {
+			sr := &v1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: srName},
+			}
+
+			existing := []v1beta1.ResolvedImageStatus{
+				{Kind: "DaemonSet", Name: "driver", Container: "driver", Image: "quay.io/driver:1.0", Resolved: "quay.io/driver:1.0"},
+				{Kind: "DaemonSet", Name: "driver", Container: "sidecar", Image: "quay.io/sidecar:1.0", Resolved: "quay.io/sidecar:1.0"},
+			}
+			resolved := []v1beta1.ResolvedImageStatus{
+				{Kind: "DaemonSet", Name: "driver", Container: "driver", Image: "quay.io/driver:1.0", Resolved: "mirror.corp/driver:1.0"},
+				{Kind: "Pod", Name: "job", Container: "job", Image: "quay.io/job:1.0", Resolved: "mirror.corp/job:1.0"},
+			}
+
+			expected := sr.DeepCopy()
+			expected.Status.Images = []v1beta1.ResolvedImageStatus{resolved[0], existing[1], resolved[1]}
+
+			gomock.InOrder(
+				mockKubeClient.
+					EXPECT().
+					Get(context.TODO(), types.NamespacedName{Name: srName}, &v1beta1.SpecialResource{}).
+					Do(func(_ context.Context, _ types.NamespacedName, update *v1beta1.SpecialResource) {
+						sr.DeepCopyInto(update)
+						update.Status.Images = existing
+					}),
+				mockKubeClient.EXPECT().StatusUpdate(context.TODO(), expected),
+			)
+
+			state.NewStatusUpdater(mockKubeClient).UpdateResolvedImages(context.TODO(), sr, resolved)
+		})
+	})
+
+	Describe("SetCondition", func() {
+		const srName = "sr-name"
+
+		It("should replace the condition of the same type and keep the others", func() {
+			sr := &v1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: srName, Generation: 2},
+			}
+
+			other := metav1.Condition{Type: "Other", Status: metav1.ConditionTrue, Reason: "Reason"}
+			verified := metav1.Condition{
+				Type:    v1beta1.ConditionChartVerified,
+				Status:  metav1.ConditionFalse,
+				Reason:  "VerificationFailed",
+				Message: "chart verification failed",
+			}
+
+			gomock.InOrder(
+				mockKubeClient.
+					EXPECT().
+					Get(context.TODO(), types.NamespacedName{Name: srName}, &v1beta1.SpecialResource{}).
+					Do(func(_ context.Context, _ types.NamespacedName, update *v1beta1.SpecialResource) {
+						sr.DeepCopyInto(update)
+						update.Status.Conditions = []metav1.Condition{
+							other,
+							{Type: v1beta1.ConditionChartVerified, Status: metav1.ConditionTrue, Reason: "Verified"},
+						}
+					}),
+				mockKubeClient.
+					EXPECT().
+					StatusUpdate(context.TODO(), gomock.Any()).
+					Do(func(_ context.Context, update *v1beta1.SpecialResource) {
+						Expect(update.Status.Conditions).To(HaveLen(2))
+						Expect(update.Status.Conditions[0]).To(Equal(other))
+
+						condition := update.Status.Conditions[1]
+						Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+						Expect(condition.Reason).To(Equal(verified.Reason))
+						Expect(condition.Message).To(Equal(verified.Message))
+						Expect(condition.ObservedGeneration).To(BeEquivalentTo(2))
+						Expect(condition.LastTransitionTime.IsZero()).To(BeFalse())
+					}),
+			)
+
+			state.NewStatusUpdater(mockKubeClient).SetCondition(context.TODO(), sr, verified)
+		})
+	})
+
+	Describe("UpdateObservedRolloutToken", func() {
+		const srName = "sr-name"
+
+		It("should update the observed rollout token", func() {
+			sr := &v1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: srName},
+				Spec:       v1beta1.SpecialResourceSpec{RolloutToken: "2"},
+				Status:     v1beta1.SpecialResourceStatus{ObservedRolloutToken: "1"},
+			}
+
+			gomock.InOrder(
+				mockKubeClient.
+					EXPECT().
+					Get(context.TODO(), types.NamespacedName{Name: srName}, &v1beta1.SpecialResource{}).
+					Do(func(_ context.Context, _ types.NamespacedName, update *v1beta1.SpecialResource) {
+						sr.DeepCopyInto(update)
+					}),
+				mockKubeClient.
+					EXPECT().
+					StatusUpdate(context.TODO(), gomock.Any()).
+					Do(func(_ context.Context, update *v1beta1.SpecialResource) {
+						Expect(update.Status.ObservedRolloutToken).To(Equal("2"))
+					}),
+			)
+
+			state.NewStatusUpdater(mockKubeClient).UpdateObservedRolloutToken(context.TODO(), sr, "2")
+			Expect(sr.Status.ObservedRolloutToken).To(Equal("2"))
+		})
+	})
+	Describe("UpdateNodeUpgrades", func() {
+		const srName = "sr-name"
+
+		It("should replace the node upgrades", func() {
+			sr := &v1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: srName},
+				Status: v1beta1.SpecialResourceStatus{
+					NodeUpgrades: []v1beta1.NodeUpgradeStatus{{Node: "worker-0", State: v1beta1.NodeUpgradeDraining}},
+				},
+			}
+
+			upgrades := []v1beta1.NodeUpgradeStatus{{Node: "worker-1", State: v1beta1.NodeUpgradeRestarting}}
+
+			gomock.InOrder(
+				mockKubeClient.
+					EXPECT().
+					Get(context.TODO(), types.NamespacedName{Name: srName}, &v1beta1.SpecialResource{}).
+					Do(func(_ context.Context, _ types.NamespacedName, update *v1beta1.SpecialResource) {
+						sr.DeepCopyInto(update)
+					}),
+				mockKubeClient.
+					EXPECT().
+					StatusUpdate(context.TODO(), gomock.Any()).
+					Do(func(_ context.Context, update *v1beta1.SpecialResource) {
+						Expect(update.Status.NodeUpgrades).To(Equal(upgrades))
+					}),
+			)
+
+			state.NewStatusUpdater(mockKubeClient).UpdateNodeUpgrades(context.TODO(), sr, upgrades)
+		})
+	})
+
+	Describe("UpdatePendingChanges", func() {
+		const srName = "sr-name"
+
+		It("should replace the pending changes and the next maintenance window", func() {
+			sr := &v1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: srName},
+				Status: v1beta1.SpecialResourceStatus{
+					PendingChanges: []v1beta1.PendingChangeStatus{{Kind: "DaemonSet", Name: "old", Change: v1beta1.PendingDaemonSetUpdate}},
+				},
+			}
+
+			changes := []v1beta1.PendingChangeStatus{{Kind: "DaemonSet", Name: "new", Change: v1beta1.PendingDaemonSetUpdate}}
+			next := metav1.NewTime(time.Date(2021, time.December, 1, 22, 0, 0, 0, time.UTC))
+
+			gomock.InOrder(
+				mockKubeClient.
+					EXPECT().
+					Get(context.TODO(), types.NamespacedName{Name: srName}, &v1beta1.SpecialResource{}).
+					Do(func(_ context.Context, _ types.NamespacedName, update *v1beta1.SpecialResource) {
+						sr.DeepCopyInto(update)
+					}),
+				mockKubeClient.
+					EXPECT().
+					StatusUpdate(context.TODO(), gomock.Any()).
+					Do(func(_ context.Context, update *v1beta1.SpecialResource) {
+						Expect(update.Status.PendingChanges).To(Equal(changes))
+						Expect(update.Status.NextMaintenanceWindow).To(Equal(&next))
+					}),
+			)
+
+			state.NewStatusUpdater(mockKubeClient).UpdatePendingChanges(context.TODO(), sr, changes, &next)
+		})
+	})
+
+	Describe("UpdateDrift", func() {
+		const srName = "sr-name"
+
+		It("should replace the drift and set the Drifted condition", func() {
+			sr := &v1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: srName, Generation: 2},
+			}
+
+			drift := []v1beta1.DriftStatus{{Kind: "DaemonSet", Name: "driver", Fields: []string{"spec.template.spec.hostNetwork"}}}
+			condition := metav1.Condition{Type: v1beta1.ConditionDrifted, Status: metav1.ConditionTrue, Reason: "Drifted"}
+
+			gomock.InOrder(
+				mockKubeClient.
+					EXPECT().
+					Get(context.TODO(), types.NamespacedName{Name: srName}, &v1beta1.SpecialResource{}).
+					Do(func(_ context.Context, _ types.NamespacedName, update *v1beta1.SpecialResource) {
+						sr.DeepCopyInto(update)
+					}),
+				mockKubeClient.
+					EXPECT().
+					StatusUpdate(context.TODO(), gomock.Any()).
+					Do(func(_ context.Context, update *v1beta1.SpecialResource) {
+						Expect(update.Status.Drift).To(Equal(drift))
+						Expect(update.Status.Conditions).To(HaveLen(1))
+						Expect(update.Status.Conditions[0].Type).To(Equal(v1beta1.ConditionDrifted))
+						Expect(update.Status.Conditions[0].ObservedGeneration).To(BeEquivalentTo(2))
+					}),
+			)
+
+			state.NewStatusUpdater(mockKubeClient).UpdateDrift(context.TODO(), sr, drift, condition)
+		})
+	})
 })
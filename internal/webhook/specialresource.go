@@ -0,0 +1,294 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	"github.com/openshift-psap/special-resource-operator/pkg/maintenance"
+	"github.com/openshift-psap/special-resource-operator/pkg/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/mutate-sro-openshift-io-v1beta1-specialresource,mutating=true,failurePolicy=fail,sideEffects=None,groups=sro.openshift.io,resources=specialresources,verbs=create;update,versions=v1beta1,name=mspecialresource.sro.openshift.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-sro-openshift-io-v1beta1-specialresource,mutating=false,failurePolicy=fail,sideEffects=None,groups=sro.openshift.io,resources=specialresources,verbs=create;update,versions=v1beta1,name=vspecialresource.sro.openshift.io,admissionReviewVersions=v1
+
+const (
+	// DefaultingPath is the path the SpecialResourceDefaulter is served at.
+	DefaultingPath = "/mutate-sro-openshift-io-v1beta1-specialresource"
+	// ValidatingPath is the path the SpecialResourceValidator is served at.
+	ValidatingPath = "/validate-sro-openshift-io-v1beta1-specialresource"
+)
+
+// SpecialResourceDefaulter sets the defaults of the SpecialResources the
+// controller would otherwise assume implicitly.
+type SpecialResourceDefaulter struct {
+	decoder *admission.Decoder
+}
+
+func NewSpecialResourceDefaulter() *SpecialResourceDefaulter {
+	return &SpecialResourceDefaulter{}
+}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (d *SpecialResourceDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (d *SpecialResourceDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	sr := &srov1beta1.SpecialResource{}
+	if err := d.decoder.Decode(req, sr); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	SetDefaults(sr)
+
+	defaulted, err := json.Marshal(sr)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, defaulted)
+}
+
+// SetDefaults sets the defaults of sr: the namespace of the SpecialResource is its name.
+func SetDefaults(sr *srov1beta1.SpecialResource) {
+	if sr.Spec.Namespace == "" {
+		sr.Spec.Namespace = sr.Name
+	}
+}
+
+// SpecialResourceValidator rejects invalid SpecialResources, and the ones
+// conflicting with the SpecialResources already in the cluster.
+type SpecialResourceValidator struct {
+	kubeClient clients.ClientsInterface
+	decoder    *admission.Decoder
+	log        logr.Logger
+}
+
+func NewSpecialResourceValidator(kubeClient clients.ClientsInterface) *SpecialResourceValidator {
+	return &SpecialResourceValidator{
+		kubeClient: kubeClient,
+		log:        ctrl.Log.WithName(utils.Print("specialresource-webhook", utils.Blue)),
+	}
+}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (v *SpecialResourceValidator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (v *SpecialResourceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	sr := &srov1beta1.SpecialResource{}
+	if err := v.decoder.Decode(req, sr); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var old *srov1beta1.SpecialResource
+	if req.Operation == admissionv1.Update {
+		// The finalizers of a SpecialResource being deleted must be removable
+		// whatever the state of the other SpecialResources
+		if sr.DeletionTimestamp != nil {
+			return admission.Allowed("")
+		}
+
+		old = &srov1beta1.SpecialResource{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	errs, err := v.validate(ctx, sr, old)
+	if err != nil {
+		v.log.Error(err, "Cannot validate SpecialResource", "name", sr.Name)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if len(errs) > 0 {
+		return deny(apierrors.NewInvalid(srov1beta1.GroupVersion.WithKind("SpecialResource").GroupKind(), sr.Name, errs))
+	}
+
+	return admission.Allowed("")
+}
+
+// validate returns the violations of sr, old is the SpecialResource sr updates, if any.
+func (v *SpecialResourceValidator) validate(ctx context.Context, sr, old *srov1beta1.SpecialResource) (field.ErrorList, error) {
+
+	spec := field.NewPath("spec")
+
+	errs := validateSpec(sr)
+
+	if old != nil {
+		errs = append(errs, validateUpdate(sr, old)...)
+	}
+
+	// Nodes may have been relabeled since the selector was set, only a new
+	// selector must match nodes
+	if len(sr.Spec.NodeSelector) > 0 && (old == nil || !reflect.DeepEqual(sr.Spec.NodeSelector, old.Spec.NodeSelector)) {
+		nodes, err := v.kubeClient.GetNodesByLabels(ctx, sr.Spec.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list the nodes matching the node selector: %w", err)
+		}
+		if len(nodes.Items) == 0 {
+			errs = append(errs, field.Invalid(spec.Child("nodeSelector"), sr.Spec.NodeSelector, "does not match any node"))
+		}
+	}
+
+	list := &srov1beta1.SpecialResourceList{}
+	if err := v.kubeClient.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("cannot list SpecialResources: %w", err)
+	}
+
+	errs = append(errs, validateConflicts(sr, old, list.Items)...)
+
+	return errs, nil
+}
+
+// validateSpec returns the violations of sr on its own.
+func validateSpec(sr *srov1beta1.SpecialResource) field.ErrorList {
+
+	var errs field.ErrorList
+
+	spec := field.NewPath("spec")
+
+	if sr.Spec.Chart.Name == "" {
+		errs = append(errs, field.Required(spec.Child("chart", "name"), "the chart to install is required"))
+	}
+
+	for i, dep := range sr.Spec.Dependencies {
+		path := spec.Child("dependencies").Index(i)
+
+		if dep.Name == "" {
+			errs = append(errs, field.Required(path.Child("chart", "name"), "the chart of the dependency is required"))
+		}
+
+		if dep.Name == sr.Name {
+			errs = append(errs, field.Invalid(path.Child("chart", "name"), dep.Name, "a SpecialResource cannot depend on itself"))
+		}
+	}
+
+	for i, window := range sr.Spec.MaintenanceWindows {
+		if _, err := maintenance.Parse(window); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("maintenanceWindows").Index(i), window, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// validateUpdate returns the changes of fields that must not change after creation.
+func validateUpdate(sr, old *srov1beta1.SpecialResource) field.ErrorList {
+
+	var errs field.ErrorList
+
+	spec := field.NewPath("spec")
+
+	// The namespace of SpecialResources created before defaulting is their name
+	if namespaceOf(sr) != namespaceOf(old) {
+		errs = append(errs, field.Forbidden(spec.Child("namespace"), "field is immutable"))
+	}
+
+	if sr.Spec.Chart.Name != old.Spec.Chart.Name {
+		errs = append(errs, field.Forbidden(spec.Child("chart", "name"), "field is immutable"))
+	}
+
+	return errs
+}
+
+// validateConflicts returns the conflicts of sr with the existing SpecialResources,
+// old is the SpecialResource sr updates, if any. The namespace is only checked when
+// it is claimed, two SpecialResources sharing one already must stay updatable.
+func validateConflicts(sr, old *srov1beta1.SpecialResource, existing []srov1beta1.SpecialResource) field.ErrorList {
+
+	var errs field.ErrorList
+
+	spec := field.NewPath("spec")
+
+	// Dependencies are SpecialResources named after their chart
+	graph := map[string][]string{sr.Name: dependencyNames(sr)}
+
+	for i := range existing {
+		other := &existing[i]
+
+		if other.Name == sr.Name {
+			continue
+		}
+
+		graph[other.Name] = dependencyNames(other)
+
+		if (old == nil || namespaceOf(old) != namespaceOf(sr)) && namespaceOf(other) == namespaceOf(sr) {
+			errs = append(errs, field.Invalid(spec.Child("namespace"), namespaceOf(sr), "already claimed by SpecialResource "+other.Name))
+		}
+	}
+
+	for i, dep := range sr.Spec.Dependencies {
+		if dep.Name == sr.Name {
+			// Already rejected as a dependency on itself
+			continue
+		}
+
+		if cycle := findCycle(graph, sr.Name, []string{sr.Name, dep.Name}); cycle != nil {
+			errs = append(errs, field.Invalid(spec.Child("dependencies").Index(i).Child("chart", "name"), dep.Name,
+				"cyclic dependency "+strings.Join(cycle, " -> ")))
+		}
+	}
+
+	return errs
+}
+
+// findCycle returns path extended to the first path back to root in graph, or nil.
+func findCycle(graph map[string][]string, root string, path []string) []string {
+
+	last := path[len(path)-1]
+	if last == root {
+		return path
+	}
+
+	for _, next := range graph[last] {
+		if utils.StringSliceContains(path[1:], next) {
+			// A cycle not going through root is none of root's business
+			continue
+		}
+		if cycle := findCycle(graph, root, append(path[:len(path):len(path)], next)); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+func dependencyNames(sr *srov1beta1.SpecialResource) []string {
+	names := make([]string, 0, len(sr.Spec.Dependencies))
+	for _, dep := range sr.Spec.Dependencies {
+		names = append(names, dep.Name)
+	}
+	return names
+}
+
+// namespaceOf returns the namespace sr installs its chart in.
+func namespaceOf(sr *srov1beta1.SpecialResource) string {
+	if sr.Spec.Namespace == "" {
+		return sr.Name
+	}
+	return sr.Spec.Namespace
+}
@@ -0,0 +1,270 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newSpecialResource(name, namespace string, deps ...string) *srov1beta1.SpecialResource {
+
+	sr := &srov1beta1.SpecialResource{
+		TypeMeta:   metav1.TypeMeta{APIVersion: srov1beta1.GroupVersion.String(), Kind: "SpecialResource"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: srov1beta1.SpecialResourceSpec{
+			Chart:     helmerv1beta1.HelmChart{Name: name, Version: "0.0.1"},
+			Namespace: namespace,
+			Set:       unstructured.Unstructured{Object: map[string]interface{}{"kind": "Values", "apiVersion": "sro.openshift.io/v1beta1"}},
+		},
+	}
+
+	for _, dep := range deps {
+		sr.Spec.Dependencies = append(sr.Spec.Dependencies, srov1beta1.SpecialResourceDependency{
+			HelmChart: helmerv1beta1.HelmChart{Name: dep, Version: "0.0.1"},
+			Set:       *sr.Spec.Set.DeepCopy(),
+		})
+	}
+
+	return sr
+}
+
+func rawOf(sr *srov1beta1.SpecialResource) runtime.RawExtension {
+	raw, err := json.Marshal(sr)
+	Expect(err).NotTo(HaveOccurred())
+	return runtime.RawExtension{Raw: raw}
+}
+
+func newDecoder() *admission.Decoder {
+	scheme := runtime.NewScheme()
+	Expect(srov1beta1.AddToScheme(scheme)).To(Succeed())
+
+	decoder, err := admission.NewDecoder(scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	return decoder
+}
+
+var _ = Describe("SpecialResourceDefaulter_Handle", func() {
+	It("should default the namespace to the name", func() {
+		d := NewSpecialResourceDefaulter()
+		Expect(d.InjectDecoder(newDecoder())).To(Succeed())
+
+		res := d.Handle(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    rawOf(newSpecialResource("simple-kmod", "")),
+			},
+		})
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Patches).To(HaveLen(1))
+		Expect(res.Patches[0].Path).To(Equal("/spec/namespace"))
+		Expect(res.Patches[0].Value).To(Equal("simple-kmod"))
+	})
+
+	It("should keep the namespace set", func() {
+		d := NewSpecialResourceDefaulter()
+		Expect(d.InjectDecoder(newDecoder())).To(Succeed())
+
+		res := d.Handle(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    rawOf(newSpecialResource("simple-kmod", "kmods")),
+			},
+		})
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Patches).To(BeEmpty())
+	})
+})
+
+var _ = Describe("SpecialResourceValidator_Handle", func() {
+	var (
+		ctrl           *gomock.Controller
+		mockKubeClient *clients.MockClientsInterface
+		validator      *SpecialResourceValidator
+		existing       []srov1beta1.SpecialResource
+	)
+
+	ctx := context.Background()
+
+	handle := func(op admissionv1.Operation, sr, old *srov1beta1.SpecialResource) admission.Response {
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: op,
+				Object:    rawOf(sr),
+			},
+		}
+		if old != nil {
+			req.OldObject = rawOf(old)
+		}
+		return validator.Handle(ctx, req)
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockKubeClient = clients.NewMockClientsInterface(ctrl)
+		validator = NewSpecialResourceValidator(mockKubeClient)
+		Expect(validator.InjectDecoder(newDecoder())).To(Succeed())
+
+		existing = nil
+		mockKubeClient.EXPECT().
+			List(ctx, gomock.AssignableToTypeOf(&srov1beta1.SpecialResourceList{})).
+			DoAndReturn(func(_ context.Context, list *srov1beta1.SpecialResourceList, _ ...client.ListOption) error {
+				list.Items = existing
+				return nil
+			}).
+			AnyTimes()
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should allow a valid SpecialResource", func() {
+		existing = []srov1beta1.SpecialResource{*newSpecialResource("driver-container-base", "")}
+
+		res := handle(admissionv1.Create, newSpecialResource("simple-kmod", "simple-kmod", "driver-container-base"), nil)
+		Expect(res.Allowed).To(BeTrue())
+	})
+
+	It("should reject an empty chart name and a dependency on itself", func() {
+		sr := newSpecialResource("simple-kmod", "", "simple-kmod", "")
+		sr.Spec.Chart.Name = ""
+
+		res := handle(admissionv1.Create, sr, nil)
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).To(ContainSubstring("spec.chart.name: Required value"))
+		Expect(res.Result.Message).To(ContainSubstring("spec.dependencies[0].chart.name: Invalid value"))
+		Expect(res.Result.Message).To(ContainSubstring("spec.dependencies[1].chart.name: Required value"))
+	})
+
+	It("should reject invalid maintenance windows", func() {
+		sr := newSpecialResource("simple-kmod", "")
+		sr.Spec.MaintenanceWindows = []srov1beta1.SpecialResourceMaintenanceWindow{
+			{Schedule: "0 22 * * 6", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+			{Schedule: "0 22 * *", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+			{Schedule: "0 22 * * 6", Duration: metav1.Duration{Duration: 4 * time.Hour}, TimeZone: "Mars/Olympus"},
+		}
+
+		res := handle(admissionv1.Create, sr, nil)
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).NotTo(ContainSubstring("spec.maintenanceWindows[0]"))
+		Expect(res.Result.Message).To(ContainSubstring("spec.maintenanceWindows[1]: Invalid value"))
+		Expect(res.Result.Message).To(ContainSubstring("spec.maintenanceWindows[2]: Invalid value"))
+	})
+
+	It("should reject a node selector matching no node", func() {
+		selector := map[string]string{"feature.node.kubernetes.io/pci-10de.present": "true"}
+		mockKubeClient.EXPECT().GetNodesByLabels(ctx, selector).Return(&v1.NodeList{}, nil)
+
+		sr := newSpecialResource("simple-kmod", "")
+		sr.Spec.NodeSelector = selector
+
+		res := handle(admissionv1.Create, sr, nil)
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).To(ContainSubstring("does not match any node"))
+	})
+
+	It("should not list nodes for an unchanged node selector", func() {
+		sr := newSpecialResource("simple-kmod", "")
+		sr.Spec.NodeSelector = map[string]string{"a": "b"}
+		sr.Spec.Debug = true
+
+		mockKubeClient.EXPECT().GetNodesByLabels(ctx, sr.Spec.NodeSelector).Return(&v1.NodeList{}, nil)
+
+		res := handle(admissionv1.Update, sr, newSpecialResource("simple-kmod", ""))
+		Expect(res.Allowed).To(BeFalse())
+
+		old := sr.DeepCopy()
+		old.Spec.Debug = false
+
+		res = handle(admissionv1.Update, sr, old)
+		Expect(res.Allowed).To(BeTrue())
+	})
+
+	It("should return an error if nodes cannot be listed", func() {
+		mockKubeClient.EXPECT().GetNodesByLabels(ctx, gomock.Any()).Return(nil, errors.New("random error"))
+
+		sr := newSpecialResource("simple-kmod", "")
+		sr.Spec.NodeSelector = map[string]string{"a": "b"}
+
+		res := handle(admissionv1.Create, sr, nil)
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Code).To(BeEquivalentTo(500))
+	})
+
+	It("should reject a namespace claimed by another SpecialResource", func() {
+		existing = []srov1beta1.SpecialResource{*newSpecialResource("kmods", "")}
+
+		res := handle(admissionv1.Create, newSpecialResource("simple-kmod", "kmods"), nil)
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).To(ContainSubstring("already claimed by SpecialResource kmods"))
+	})
+
+	It("should only check the namespace when it is claimed", func() {
+		existing = []srov1beta1.SpecialResource{*newSpecialResource("kmods", "")}
+
+		sr := newSpecialResource("simple-kmod", "kmods")
+		old := sr.DeepCopy()
+		sr.Spec.Debug = true
+
+		res := handle(admissionv1.Update, sr, old)
+		Expect(res.Allowed).To(BeTrue())
+
+		res = handle(admissionv1.Update, sr, newSpecialResource("simple-kmod", ""))
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).To(ContainSubstring("already claimed by SpecialResource kmods"))
+	})
+
+	It("should allow any update of a SpecialResource being deleted", func() {
+		sr := newSpecialResource("simple-kmod", "kmods")
+		sr.Spec.Chart.Name = ""
+		sr.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+		res := handle(admissionv1.Update, sr, newSpecialResource("simple-kmod", ""))
+		Expect(res.Allowed).To(BeTrue())
+	})
+
+	It("should reject cyclic dependencies", func() {
+		existing = []srov1beta1.SpecialResource{
+			*newSpecialResource("a", "", "b"),
+			*newSpecialResource("b", "", "c"),
+			*newSpecialResource("c", ""),
+			*newSpecialResource("d", "", "d"),
+		}
+
+		res := handle(admissionv1.Update, newSpecialResource("c", "", "d", "a"), newSpecialResource("c", ""))
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).To(ContainSubstring("spec.dependencies[1].chart.name"))
+		Expect(res.Result.Message).To(ContainSubstring("cyclic dependency c -> a -> b -> c"))
+		Expect(res.Result.Message).NotTo(ContainSubstring("dependencies[0]"))
+	})
+
+	It("should reject changes of immutable fields", func() {
+		sr := newSpecialResource("simple-kmod", "kmods")
+		sr.Spec.Chart.Name = "other"
+
+		res := handle(admissionv1.Update, sr, newSpecialResource("simple-kmod", ""))
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).To(ContainSubstring("spec.namespace: Forbidden"))
+		Expect(res.Result.Message).To(ContainSubstring("spec.chart.name: Forbidden"))
+
+		// Defaulting the namespace of an existing SpecialResource is no change
+		res = handle(admissionv1.Update, newSpecialResource("simple-kmod", "simple-kmod"), newSpecialResource("simple-kmod", ""))
+		Expect(res.Allowed).To(BeTrue())
+	})
+})
@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/helmer"
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/utils"
+	"github.com/openshift-psap/special-resource-operator/pkg/values"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	admissionv1 "k8s.io/api/admission/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-sro-openshift-io-v1beta1-specialresource-values,mutating=false,failurePolicy=ignore,sideEffects=None,groups=sro.openshift.io,resources=specialresources,verbs=create;update,versions=v1beta1,name=values.specialresources.sro.openshift.io,admissionReviewVersions=v1
+
+// ValuesPath is the path the ValuesValidator is served at.
+const ValuesPath = "/validate-sro-openshift-io-v1beta1-specialresource-values"
+
+// loadTimeout bounds the loads of the charts of a request, below the 10 seconds
+// the API server waits for the webhook by default.
+const loadTimeout = 8 * time.Second
+
+// ValuesValidator rejects SpecialResources whose values, or the values of their
+// dependencies, violate the values.schema.json of their chart. The runtime
+// information is only known while reconciling, its keys are not validated.
+type ValuesValidator struct {
+	helmer      helmer.Helmer
+	chartVerify *helmerv1beta1.HelmVerify
+	decoder     *admission.Decoder
+	log         logr.Logger
+	timeout     time.Duration
+
+	// loading serializes the loads of the charts, the requests are handled
+	// concurrently but a helmer is not safe for concurrent use
+	loading chan struct{}
+}
+
+// NewValuesValidator returns a ValuesValidator loading the charts with h, which
+// must not be shared with the reconciler.
+func NewValuesValidator(h helmer.Helmer, chartVerify *helmerv1beta1.HelmVerify) *ValuesValidator {
+	return &ValuesValidator{
+		helmer:      h,
+		chartVerify: chartVerify,
+		log:         ctrl.Log.WithName(utils.Print("values-webhook", utils.Blue)),
+		timeout:     loadTimeout,
+		loading:     make(chan struct{}, 1),
+	}
+}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (v *ValuesValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (v *ValuesValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	sr := &srov1beta1.SpecialResource{}
+	if err := v.decoder.Decode(req, sr); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// Nothing is installed anymore once the SpecialResource is being deleted
+	if sr.DeletionTimestamp != nil {
+		return admission.Allowed("")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	var warnings []string
+
+	check := func(spec helmerv1beta1.HelmChart, vals map[string]interface{}) error {
+		err := v.validate(ctx, spec, vals, namespaceOf(sr))
+
+		if errors.Is(err, values.ErrInvalid) {
+			return fmt.Errorf("chart %s: %w", spec.Name, err)
+		}
+
+		// The chart may not be reachable from the API server's point of
+		// view yet, the controller validates the values again anyway
+		if err != nil {
+			v.log.Info("Cannot validate values", "chart", spec.Name, "error", err.Error())
+			warnings = append(warnings, fmt.Sprintf("values of chart %s not validated: %v", spec.Name, err))
+		}
+
+		return nil
+	}
+
+	if err := check(sr.Spec.Chart, sr.Spec.Set.Object); err != nil {
+		return deny(err)
+	}
+
+	for _, dep := range sr.Spec.Dependencies {
+		if err := check(dep.HelmChart, dep.Set.Object); err != nil {
+			return deny(err)
+		}
+	}
+
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+func (v *ValuesValidator) validate(ctx context.Context, spec helmerv1beta1.HelmChart, vals map[string]interface{}, namespace string) error {
+
+	if spec.Verify == nil {
+		spec.Verify = v.chartVerify
+	}
+
+	ch, err := v.load(ctx, spec, namespace)
+	if err != nil {
+		return err
+	}
+
+	merged, err := chartutil.CoalesceValues(ch, vals)
+	if err != nil {
+		return err
+	}
+
+	return values.ValidateChart(ch, merged)
+}
+
+// deny returns a response denying the request with err as message, which is
+// shown to the user by the API server.
+func deny(err error) admission.Response {
+	res := admission.Denied(err.Error())
+	res.Result.Message = err.Error()
+	return res
+}
+
+// load loads the chart of spec, or gives up when ctx is done. A load that does
+// not follow the deadline of ctx, e.g. the download of a repository index, goes
+// on in the background and the next loads wait for it.
+func (v *ValuesValidator) load(ctx context.Context, spec helmerv1beta1.HelmChart, namespace string) (*chart.Chart, error) {
+
+	select {
+	case v.loading <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("cannot load chart %s: %w", spec.Name, ctx.Err())
+	}
+
+	type loaded struct {
+		ch  *chart.Chart
+		err error
+	}
+
+	done := make(chan loaded, 1)
+
+	go func() {
+		defer func() { <-v.loading }()
+
+		ch, err := v.helmer.Load(ctx, spec, namespace)
+		done <- loaded{ch, err}
+	}()
+
+	select {
+	case l := <-done:
+		return l.ch, l.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("cannot load chart %s: %w", spec.Name, ctx.Err())
+	}
+}
@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/helmer"
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
+	"helm.sh/helm/v3/pkg/chart"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhook Suite")
+}
+
+const schema = `{
+  "type": "object",
+  "required": ["kernelFullVersion"],
+  "properties": {
+    "kernelFullVersion": {"type": "string"},
+    "driver": {
+      "type": "object",
+      "properties": {"version": {"type": "string"}}
+    }
+  }
+}`
+
+var _ = Describe("ValuesValidator_Handle", func() {
+	var (
+		ctrl       *gomock.Controller
+		mockHelmer *helmer.MockHelmer
+		validator  *ValuesValidator
+	)
+
+	ctx := context.Background()
+
+	driverChart := helmerv1beta1.HelmChart{Name: "driver", Version: "0.0.1"}
+
+	request := func(op admissionv1.Operation, set map[string]interface{}) admission.Request {
+		if set == nil {
+			set = map[string]interface{}{}
+		}
+		set["kind"] = "Values"
+		set["apiVersion"] = "sro.openshift.io/v1beta1"
+
+		sr := &srov1beta1.SpecialResource{
+			TypeMeta:   metav1.TypeMeta{APIVersion: srov1beta1.GroupVersion.String(), Kind: "SpecialResource"},
+			ObjectMeta: metav1.ObjectMeta{Name: "driver"},
+			Spec: srov1beta1.SpecialResourceSpec{
+				Chart: driverChart,
+				Set:   unstructured.Unstructured{Object: set},
+			},
+		}
+
+		raw, err := json.Marshal(sr)
+		Expect(err).NotTo(HaveOccurred())
+
+		return admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: op,
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockHelmer = helmer.NewMockHelmer(ctrl)
+		validator = NewValuesValidator(mockHelmer, nil)
+
+		scheme := runtime.NewScheme()
+		Expect(srov1beta1.AddToScheme(scheme)).To(Succeed())
+
+		decoder, err := admission.NewDecoder(scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validator.InjectDecoder(decoder)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should deny values violating the chart's schema", func() {
+		mockHelmer.EXPECT().Load(gomock.Any(), driverChart, gomock.Any()).Return(&chart.Chart{
+			Metadata: &chart.Metadata{Name: "driver"},
+			Schema:   []byte(schema),
+		}, nil)
+
+		res := validator.Handle(ctx, request(admissionv1.Create, map[string]interface{}{
+			"driver": map[string]interface{}{"version": 1},
+		}))
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).To(ContainSubstring("/driver/version"))
+		// The runtime information is not known yet
+		Expect(res.Result.Message).NotTo(ContainSubstring("kernelFullVersion"))
+	})
+
+	It("should allow valid values", func() {
+		mockHelmer.EXPECT().Load(gomock.Any(), driverChart, gomock.Any()).Return(&chart.Chart{
+			Metadata: &chart.Metadata{Name: "driver"},
+			Schema:   []byte(schema),
+		}, nil)
+
+		res := validator.Handle(ctx, request(admissionv1.Update, map[string]interface{}{
+			"driver": map[string]interface{}{"version": "1.0"},
+		}))
+		Expect(res.Allowed).To(BeTrue())
+	})
+
+	It("should allow with a warning charts that cannot be loaded", func() {
+		mockHelmer.EXPECT().Load(gomock.Any(), driverChart, gomock.Any()).Return(nil, errors.New("random error"))
+
+		res := validator.Handle(ctx, request(admissionv1.Create, nil))
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Warnings).To(HaveLen(1))
+	})
+
+	It("should not load charts concurrently", func() {
+		var loading int32
+
+		mockHelmer.EXPECT().Load(gomock.Any(), driverChart, gomock.Any()).Times(4).
+			DoAndReturn(func(context.Context, helmerv1beta1.HelmChart, string) (*chart.Chart, error) {
+				defer atomic.AddInt32(&loading, -1)
+				Expect(atomic.AddInt32(&loading, 1)).To(Equal(int32(1)))
+				time.Sleep(10 * time.Millisecond)
+				return &chart.Chart{Metadata: &chart.Metadata{Name: "driver"}, Schema: []byte(schema)}, nil
+			})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				res := validator.Handle(ctx, request(admissionv1.Create, nil))
+				Expect(res.Allowed).To(BeTrue())
+				Expect(res.Warnings).To(BeEmpty())
+			}()
+		}
+		wg.Wait()
+	})
+
+	It("should give up on charts loading past the deadline", func() {
+		validator.timeout = 50 * time.Millisecond
+
+		unblock := make(chan struct{})
+		defer close(unblock)
+
+		mockHelmer.EXPECT().Load(gomock.Any(), driverChart, gomock.Any()).
+			DoAndReturn(func(context.Context, helmerv1beta1.HelmChart, string) (*chart.Chart, error) {
+				// Not following the deadline, e.g. downloading an index
+				<-unblock
+				return nil, errors.New("random error")
+			})
+
+		res := validator.Handle(ctx, request(admissionv1.Create, nil))
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Warnings).To(ConsistOf(ContainSubstring(context.DeadlineExceeded.Error())))
+
+		// The next request waits for the pending load until its own deadline
+		res = validator.Handle(ctx, request(admissionv1.Create, nil))
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Warnings).To(ConsistOf(ContainSubstring(context.DeadlineExceeded.Error())))
+	})
+
+	It("should not load charts on delete", func() {
+		res := validator.Handle(ctx, request(admissionv1.Delete, nil))
+		Expect(res.Allowed).To(BeTrue())
+	})
+})
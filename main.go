@@ -19,20 +19,25 @@ package main
 import (
 	"os"
 
+	srov1 "github.com/openshift-psap/special-resource-operator/api/v1"
 	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/cmd/cli"
+	"github.com/openshift-psap/special-resource-operator/cmd/render"
 	"github.com/openshift-psap/special-resource-operator/controllers"
 	"github.com/openshift-psap/special-resource-operator/internal/controllers/finalizers"
 	"github.com/openshift-psap/special-resource-operator/internal/controllers/state"
 	"github.com/openshift-psap/special-resource-operator/internal/resourcehelper"
+	"github.com/openshift-psap/special-resource-operator/internal/webhook"
 	"github.com/openshift-psap/special-resource-operator/pkg/assets"
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
 	"github.com/openshift-psap/special-resource-operator/pkg/cluster"
 	"github.com/openshift-psap/special-resource-operator/pkg/filter"
 	"github.com/openshift-psap/special-resource-operator/pkg/helmer"
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/pkg/kernel"
 	"github.com/openshift-psap/special-resource-operator/pkg/lifecycle"
 	"github.com/openshift-psap/special-resource-operator/pkg/metrics"
+	"github.com/openshift-psap/special-resource-operator/pkg/nodeupgrade"
 	"github.com/openshift-psap/special-resource-operator/pkg/poll"
 	"github.com/openshift-psap/special-resource-operator/pkg/proxy"
 	"github.com/openshift-psap/special-resource-operator/pkg/registry"
@@ -46,6 +51,7 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -59,10 +65,19 @@ func init() {
 	utilruntime.Must(sroscheme.AddToScheme(scheme))
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(srov1beta1.AddToScheme(scheme))
+	utilruntime.Must(srov1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == cli.RenderCommand {
+		if err := render.Run(os.Args[0], os.Args[2:], scheme); err != nil {
+			setupLog.Error(err, "could not render the chart")
+			os.Exit(1)
+		}
+		return
+	}
+
 	cl, err := cli.ParseCommandLine(os.Args[0], os.Args[1:])
 	if err != nil {
 		setupLog.Error(err, "could not parse command-line arguments")
@@ -99,6 +114,21 @@ func main() {
 	pollActions := poll.New(kubeClient, lc, st)
 	kernelData := kernel.NewKernelData()
 	proxyAPI := proxy.NewProxyAPI(kubeClient)
+	reg := registry.NewRegistry(kubeClient)
+
+	var chartVerify *helmerv1beta1.HelmVerify
+	if cl.ChartVerifySecret != "" {
+		chartVerify = &helmerv1beta1.HelmVerify{
+			Provider:  cl.ChartVerifyProvider,
+			SecretRef: helmerv1beta1.HelmSecretRef{Name: cl.ChartVerifySecret},
+		}
+	}
+
+	imagePolicy, err := cl.ImagePolicy()
+	if err != nil {
+		setupLog.Error(err, "invalid image policy")
+		os.Exit(1)
+	}
 
 	creator := resource.NewCreator(
 		kubeClient,
@@ -108,17 +138,21 @@ func main() {
 		scheme,
 		lc,
 		proxyAPI,
-		resourcehelper.New())
+		resourcehelper.New(),
+		reg,
+		imagePolicy)
+
+	h := helmer.NewHelmer(creator, helmer.DefaultSettings(), kubeClient)
 
 	if err = (&controllers.SpecialResourceReconciler{Cluster: clusterCluster,
-		ClusterInfo:   upgrade.NewClusterInfo(registry.NewRegistry(kubeClient), clusterCluster),
+		ClusterInfo:   upgrade.NewClusterInfo(reg, clusterCluster),
 		Creator:       creator,
 		PollActions:   pollActions,
 		Filter:        filter.NewFilter(lc, st, kernelData),
 		Finalizer:     finalizers.NewSpecialResourceFinalizer(kubeClient, pollActions),
 		StatusUpdater: state.NewStatusUpdater(kubeClient),
 		Storage:       st,
-		Helmer:        helmer.NewHelmer(creator, helmer.DefaultSettings(), kubeClient),
+		Helmer:        h,
 		Assets:        assets.NewAssets(),
 		KernelData:    kernelData,
 		Log:           ctrl.Log,
@@ -126,12 +160,28 @@ func main() {
 		Scheme:        scheme,
 		ProxyAPI:      proxyAPI,
 		KubeClient:    kubeClient,
+		NodeUpgrader:  nodeupgrade.NewNodeUpgrader(kubeClient),
+		ChartVerify:   chartVerify,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SpecialResource")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
+	if cl.EnableWebhooks {
+		hookServer := mgr.GetWebhookServer()
+		hookServer.Register(webhook.DefaultingPath, &admission.Webhook{Handler: webhook.NewSpecialResourceDefaulter()})
+		hookServer.Register(webhook.ValidatingPath, &admission.Webhook{Handler: webhook.NewSpecialResourceValidator(kubeClient)})
+		hookServer.Register(webhook.ValuesPath, &admission.Webhook{Handler: webhook.NewValuesValidator(
+			helmer.NewHelmer(nil, helmer.WebhookSettings(), kubeClient), chartVerify)})
+
+		// Serves the conversion between v1 and v1beta1, the storage version
+		if err = ctrl.NewWebhookManagedBy(mgr).For(&srov1.SpecialResource{}).Complete(); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SpecialResource")
+			os.Exit(1)
+		}
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
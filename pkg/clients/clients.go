@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/openshift-psap/special-resource-operator/pkg/state"
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
 	buildv1 "github.com/openshift/api/build/v1"
 	configv1 "github.com/openshift/api/config/v1"
@@ -11,8 +12,10 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
@@ -37,11 +40,11 @@ var (
 )
 
 type ClientsInterface interface {
-	Update(ctx context.Context, obj client.Object) error
+	Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error
 	Get(ctx context.Context, key client.ObjectKey, obj client.Object) error
 	Delete(ctx context.Context, obj client.Object) error
 	List(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error
-	Create(ctx context.Context, obj client.Object) error
+	Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error
 	GetPodLogs(namespace, podName string, podLogOpts *v1.PodLogOptions) *restclient.Request
 	GetNamespace(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Namespace, error)
 	GetSecret(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.Secret, error)
@@ -53,6 +56,8 @@ type ClientsInterface interface {
 	HasResource(resource schema.GroupVersionResource) (bool, error)
 	GetNodesByLabels(ctx context.Context, matchingLabels map[string]string) (*v1.NodeList, error)
 	GetPlatform() (string, error)
+	EvictPod(ctx context.Context, pod *v1.Pod) error
+	Event(object runtime.Object, eventtype, reason, message string)
 }
 
 type k8sClients struct {
@@ -87,8 +92,8 @@ func NewClients(runtimeClient client.Client, restConfig *restclient.Config, even
 	}, nil
 }
 
-func (k *k8sClients) Update(ctx context.Context, obj client.Object) error {
-	return k.runtimeClient.Update(ctx, obj)
+func (k *k8sClients) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return k.runtimeClient.Update(ctx, obj, opts...)
 }
 
 func (k *k8sClients) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
@@ -103,8 +108,8 @@ func (k *k8sClients) List(ctx context.Context, obj client.ObjectList, opts ...cl
 	return k.runtimeClient.List(ctx, obj, opts...)
 }
 
-func (k *k8sClients) Create(ctx context.Context, obj client.Object) error {
-	return k.runtimeClient.Create(ctx, obj)
+func (k *k8sClients) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return k.runtimeClient.Create(ctx, obj, opts...)
 }
 
 func (k *k8sClients) GetPodLogs(namespace, podName string, podLogOpts *v1.PodLogOptions) *restclient.Request {
@@ -204,8 +209,25 @@ func (k *k8sClients) GetNodesByLabels(ctx context.Context, matchingLabels map[st
 	return &nodes, nil
 }
 
+// Event records an event of object with the operator's event recorder.
+func (k *k8sClients) Event(object runtime.Object, eventtype, reason, message string) {
+	k.eventRecorder.Event(object, eventtype, reason, message)
+}
+
+// EvictPod evicts pod through the Eviction API, which honors its PodDisruptionBudgets.
+func (k *k8sClients) EvictPod(ctx context.Context, pod *v1.Pod) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	}
+	return k.clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+}
+
 func (k *k8sClients) isNodeNotExecOrSchedule(node *v1.Node) bool {
 	for _, taint := range node.Spec.Taints {
+		// The nodes tainted until the states of a SpecialResource are ready are still managed
+		if state.IsNotReadyTaint(taint) {
+			continue
+		}
 		if taint.Effect == v1.TaintEffectNoSchedule || taint.Effect == v1.TaintEffectNoExecute {
 			return true
 		}
@@ -7,9 +7,13 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/openshift-psap/special-resource-operator/pkg/state"
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -23,6 +27,7 @@ var _ = Describe("GetNodesByLabels", func() {
 		numNodes         int
 		labels           map[string]string
 		addTaint         bool
+		taintKey         string
 		taintEffect      corev1.TaintEffect
 		expectedNumNodes int
 	}
@@ -32,7 +37,11 @@ var _ = Describe("GetNodesByLabels", func() {
 		func(test testInput) {
 			nodesList := utils.CreateNodesList(test.numNodes, test.labels)
 			if test.addTaint {
-				utils.SetTaint(&nodesList.Items[0], "taintKey", "taintValue", test.taintEffect)
+				key := test.taintKey
+				if key == "" {
+					key = "taintKey"
+				}
+				utils.SetTaint(&nodesList.Items[0], key, "taintValue", test.taintEffect)
 			}
 			objs := []runtime.Object{nodesList}
 			clientsStruct := k8sClients{runtimeClient: fake.NewClientBuilder().WithRuntimeObjects(objs...).Build()}
@@ -68,5 +77,51 @@ var _ = Describe("GetNodesByLabels", func() {
 				expectedNumNodes: 2,
 			},
 		),
+		Entry(
+			"a node with the not ready taint of a SpecialResource",
+			testInput{
+				numNodes:         3,
+				labels:           map[string]string{"key1": "label1"},
+				addTaint:         true,
+				taintKey:         state.NotReadyTaint("simple-kmod").Key,
+				taintEffect:      corev1.TaintEffectNoSchedule,
+				expectedNumNodes: 3,
+			},
+		),
 	)
 })
+
+var _ = Describe("OfflineClients", func() {
+	It("should record the objects applied, also in dry-run mode", func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		c := NewOfflineClients(scheme, "OCP")
+
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "ns"}}
+		Expect(c.Create(context.TODO(), cm, client.DryRunAll)).To(Succeed())
+
+		found := &corev1.ConfigMap{}
+		Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: "ns", Name: "config"}, found)).To(Succeed())
+
+		found.Data = map[string]string{"key": "value"}
+		Expect(c.Update(context.TODO(), found, client.DryRunAll)).To(Succeed())
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "ns"}}
+		Expect(c.Create(context.TODO(), secret)).To(Succeed())
+
+		applied := c.Applied()
+		Expect(applied).To(HaveLen(2))
+		Expect(applied[0].GetKind()).To(Equal("ConfigMap"))
+		Expect(applied[0].GetResourceVersion()).To(BeEmpty())
+		Expect(applied[0].Object["data"]).To(Equal(map[string]interface{}{"key": "value"}))
+		Expect(applied[1].GetKind()).To(Equal("Secret"))
+
+		Expect(c.GetPlatform()).To(Equal("OCP"))
+	})
+
+	It("should not find the cluster version", func() {
+		_, err := NewOfflineClients(runtime.NewScheme(), "K8S").ClusterVersionGet(context.TODO(), metav1.GetOptions{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
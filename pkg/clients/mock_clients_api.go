@@ -12,6 +12,7 @@ import (
 	v1 "github.com/openshift/api/config/v1"
 	v10 "k8s.io/api/core/v1"
 	v11 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	rest "k8s.io/client-go/rest"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,51 +42,6 @@ func (m *MockClientsInterface) EXPECT() *MockClientsInterfaceMockRecorder {
 	return m.recorder
 }
 
-// ClusterOperatorCreate mocks base method.
-func (m *MockClientsInterface) ClusterOperatorCreate(ctx context.Context, clusterOperator *v1.ClusterOperator, opts v11.CreateOptions) (*v1.ClusterOperator, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ClusterOperatorCreate", ctx, clusterOperator, opts)
-	ret0, _ := ret[0].(*v1.ClusterOperator)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// ClusterOperatorCreate indicates an expected call of ClusterOperatorCreate.
-func (mr *MockClientsInterfaceMockRecorder) ClusterOperatorCreate(ctx, clusterOperator, opts interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterOperatorCreate", reflect.TypeOf((*MockClientsInterface)(nil).ClusterOperatorCreate), ctx, clusterOperator, opts)
-}
-
-// ClusterOperatorGet mocks base method.
-func (m *MockClientsInterface) ClusterOperatorGet(ctx context.Context, name string, opts v11.GetOptions) (*v1.ClusterOperator, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ClusterOperatorGet", ctx, name, opts)
-	ret0, _ := ret[0].(*v1.ClusterOperator)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// ClusterOperatorGet indicates an expected call of ClusterOperatorGet.
-func (mr *MockClientsInterfaceMockRecorder) ClusterOperatorGet(ctx, name, opts interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterOperatorGet", reflect.TypeOf((*MockClientsInterface)(nil).ClusterOperatorGet), ctx, name, opts)
-}
-
-// ClusterOperatorUpdateStatus mocks base method.
-func (m *MockClientsInterface) ClusterOperatorUpdateStatus(ctx context.Context, clusterOperator *v1.ClusterOperator, opts v11.UpdateOptions) (*v1.ClusterOperator, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ClusterOperatorUpdateStatus", ctx, clusterOperator, opts)
-	ret0, _ := ret[0].(*v1.ClusterOperator)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// ClusterOperatorUpdateStatus indicates an expected call of ClusterOperatorUpdateStatus.
-func (mr *MockClientsInterfaceMockRecorder) ClusterOperatorUpdateStatus(ctx, clusterOperator, opts interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterOperatorUpdateStatus", reflect.TypeOf((*MockClientsInterface)(nil).ClusterOperatorUpdateStatus), ctx, clusterOperator, opts)
-}
-
 // ClusterVersionGet mocks base method.
 func (m *MockClientsInterface) ClusterVersionGet(ctx context.Context, opts v11.GetOptions) (*v1.ClusterVersion, error) {
 	m.ctrl.T.Helper()
@@ -102,17 +58,22 @@ func (mr *MockClientsInterfaceMockRecorder) ClusterVersionGet(ctx, opts interfac
 }
 
 // Create mocks base method.
-func (m *MockClientsInterface) Create(ctx context.Context, obj client.Object) error {
+func (m *MockClientsInterface) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, obj)
+	varargs := []interface{}{ctx, obj}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockClientsInterfaceMockRecorder) Create(ctx, obj interface{}) *gomock.Call {
+func (mr *MockClientsInterfaceMockRecorder) Create(ctx, obj interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockClientsInterface)(nil).Create), ctx, obj)
+	varargs := append([]interface{}{ctx, obj}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockClientsInterface)(nil).Create), varargs...)
 }
 
 // CreateOrUpdate mocks base method.
@@ -144,6 +105,32 @@ func (mr *MockClientsInterfaceMockRecorder) Delete(ctx, obj interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClientsInterface)(nil).Delete), ctx, obj)
 }
 
+// Event mocks base method.
+func (m *MockClientsInterface) Event(object runtime.Object, eventtype, reason, message string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Event", object, eventtype, reason, message)
+}
+
+// Event indicates an expected call of Event.
+func (mr *MockClientsInterfaceMockRecorder) Event(object, eventtype, reason, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Event", reflect.TypeOf((*MockClientsInterface)(nil).Event), object, eventtype, reason, message)
+}
+
+// EvictPod mocks base method.
+func (m *MockClientsInterface) EvictPod(ctx context.Context, pod *v10.Pod) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvictPod", ctx, pod)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EvictPod indicates an expected call of EvictPod.
+func (mr *MockClientsInterfaceMockRecorder) EvictPod(ctx, pod interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictPod", reflect.TypeOf((*MockClientsInterface)(nil).EvictPod), ctx, pod)
+}
+
 // Get mocks base method.
 func (m *MockClientsInterface) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
 	m.ctrl.T.Helper()
@@ -308,15 +295,20 @@ func (mr *MockClientsInterfaceMockRecorder) StatusUpdate(ctx, obj interface{}) *
 }
 
 // Update mocks base method.
-func (m *MockClientsInterface) Update(ctx context.Context, obj client.Object) error {
+func (m *MockClientsInterface) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, obj)
+	varargs := []interface{}{ctx, obj}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Update", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockClientsInterfaceMockRecorder) Update(ctx, obj interface{}) *gomock.Call {
+func (mr *MockClientsInterfaceMockRecorder) Update(ctx, obj interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockClientsInterface)(nil).Update), ctx, obj)
+	varargs := append([]interface{}{ctx, obj}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockClientsInterface)(nil).Update), varargs...)
 }
@@ -0,0 +1,150 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	restclient "k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var errOffline = errors.New("not available without a cluster")
+
+// OfflineClients is a ClientsInterface without a cluster, the objects are kept in
+// memory and the objects applied are recorded, e.g. to render charts in CI.
+type OfflineClients struct {
+	k8sClients
+
+	scheme   *runtime.Scheme
+	platform string
+	applied  []*unstructured.Unstructured
+}
+
+// NewOfflineClients returns OfflineClients for a platform, OCP or K8S, holding objs.
+func NewOfflineClients(scheme *runtime.Scheme, platform string, objs ...client.Object) *OfflineClients {
+	return &OfflineClients{
+		k8sClients: k8sClients{
+			runtimeClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		},
+		scheme:   scheme,
+		platform: platform,
+	}
+}
+
+// Create creates obj in memory and records it, also in dry-run mode so that the
+// objects applied again are found.
+func (o *OfflineClients) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+
+	co := &client.CreateOptions{}
+	co.ApplyOptions(opts)
+	co.DryRun = nil
+
+	if err := o.k8sClients.Create(ctx, obj, co); err != nil {
+		return err
+	}
+
+	return o.record(obj)
+}
+
+// Update updates obj in memory and records it, also in dry-run mode.
+func (o *OfflineClients) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+
+	uo := &client.UpdateOptions{}
+	uo.ApplyOptions(opts)
+	uo.DryRun = nil
+
+	if err := o.k8sClients.Update(ctx, obj, uo); err != nil {
+		return err
+	}
+
+	return o.record(obj)
+}
+
+// record replaces the recorded object with the same kind, namespace and name as obj,
+// or appends obj.
+func (o *OfflineClients) record(obj client.Object) error {
+
+	u := &unstructured.Unstructured{}
+	if in, ok := obj.(*unstructured.Unstructured); ok {
+		in.DeepCopyInto(u)
+	} else {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return fmt.Errorf("cannot convert %s to unstructured: %w", obj.GetName(), err)
+		}
+		u.SetUnstructuredContent(content)
+
+		gvk, err := apiutil.GVKForObject(obj, o.scheme)
+		if err != nil {
+			return err
+		}
+		u.SetGroupVersionKind(gvk)
+	}
+
+	// Set by the in-memory store, it differs from one cluster to the other
+	u.SetResourceVersion("")
+
+	for i, a := range o.applied {
+		if a.GroupVersionKind() == u.GroupVersionKind() && a.GetNamespace() == u.GetNamespace() && a.GetName() == u.GetName() {
+			o.applied[i] = u
+			return nil
+		}
+	}
+
+	o.applied = append(o.applied, u)
+
+	return nil
+}
+
+// Applied returns the objects created or updated, in the order they were created.
+func (o *OfflineClients) Applied() []*unstructured.Unstructured {
+	return o.applied
+}
+
+func (o *OfflineClients) GetPodLogs(namespace, podName string, podLogOpts *v1.PodLogOptions) *restclient.Request {
+	return nil
+}
+
+func (o *OfflineClients) GetNamespace(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Namespace, error) {
+	ns := &v1.Namespace{}
+	return ns, o.runtimeClient.Get(ctx, client.ObjectKey{Name: name}, ns)
+}
+
+func (o *OfflineClients) GetSecret(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.Secret, error) {
+	secret := &v1.Secret{}
+	return secret, o.runtimeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret)
+}
+
+func (o *OfflineClients) ClusterVersionGet(ctx context.Context, opts metav1.GetOptions) (*configv1.ClusterVersion, error) {
+	return nil, apierrors.NewNotFound(configv1.Resource("clusterversions"), clusterVersionName)
+}
+
+func (o *OfflineClients) Invalidate() {}
+
+func (o *OfflineClients) ServerGroups() (*metav1.APIGroupList, error) {
+	return &metav1.APIGroupList{}, nil
+}
+
+func (o *OfflineClients) HasResource(resource schema.GroupVersionResource) (bool, error) {
+	return false, nil
+}
+
+func (o *OfflineClients) GetPlatform() (string, error) {
+	return o.platform, nil
+}
+
+func (o *OfflineClients) EvictPod(ctx context.Context, pod *v1.Pod) error {
+	return errOffline
+}
+
+func (o *OfflineClients) Event(object runtime.Object, eventtype, reason, message string) {}
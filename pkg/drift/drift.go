@@ -0,0 +1,162 @@
+package drift
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// quantityMaps are the fields mapping resource names to quantities, e.g. the limits
+// and requests of a container, the hard limits of a ResourceQuota or the bounds of
+// a LimitRange. The API server stores quantities in their canonical form.
+var quantityMaps = map[string]bool{
+	"limits": true, "requests": true, "hard": true, "capacity": true, "overhead": true,
+	"min": true, "max": true, "default": true, "defaultRequest": true, "maxLimitRequestRatio": true,
+}
+
+// Diff returns the paths of the fields set in desired whose value differs in live,
+// sorted. The fields only set in live, e.g. defaulted by the API server or set by
+// other controllers, are not compared, nor are the status and the metadata other
+// than the labels and annotations. The stringData of a Secret is write-only, it is
+// merged into its data by the API server, and quantities are compared by value.
+func Diff(desired, live *unstructured.Unstructured) []string {
+
+	var paths []string
+
+	for key, value := range desired.Object {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "stringData":
+			if desired.GetKind() == "Secret" {
+				continue
+			}
+		case "metadata":
+			for _, field := range []string{"labels", "annotations"} {
+				d, _, _ := unstructured.NestedFieldNoCopy(desired.Object, "metadata", field)
+				l, _, _ := unstructured.NestedFieldNoCopy(live.Object, "metadata", field)
+				paths = diff("metadata."+field, d, l, paths)
+			}
+			continue
+		}
+		paths = diff(key, value, live.Object[key], paths)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+func diff(path string, desired, live interface{}, paths []string) []string {
+
+	// Null fields are not set
+	if desired == nil {
+		return paths
+	}
+
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			if live == nil && len(d) == 0 {
+				return paths
+			}
+			return append(paths, path)
+		}
+		for key, value := range d {
+			paths = diff(path+"."+key, value, l[key], paths)
+		}
+		return paths
+
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			if live == nil && len(d) == 0 {
+				return paths
+			}
+			return append(paths, path)
+		}
+		if len(d) != len(l) {
+			return append(paths, path)
+		}
+		for i := range d {
+			paths = diff(fmt.Sprintf("%s[%d]", path, i), d[i], l[i], paths)
+		}
+		return paths
+	}
+
+	if quantityField(path) && quantityEqual(desired, live) {
+		return paths
+	}
+
+	if !scalarEqual(desired, live) {
+		return append(paths, path)
+	}
+
+	return paths
+}
+
+// quantityField returns true if the field at path holds a quantity, an entry of one
+// of the quantityMaps or the sizeLimit of an emptyDir volume.
+func quantityField(path string) bool {
+
+	fields := strings.Split(path, ".")
+	if fields[len(fields)-1] == "sizeLimit" {
+		return true
+	}
+
+	return len(fields) > 1 && quantityMaps[fields[len(fields)-2]]
+}
+
+// quantityEqual returns true if desired and live parse to the same quantity, e.g.
+// 1000m and 1, or 1024Mi and 1Gi. A YAML number is parsed as its string form.
+func quantityEqual(desired, live interface{}) bool {
+
+	d, err := resource.ParseQuantity(fmt.Sprint(desired))
+	if err != nil {
+		return false
+	}
+
+	l, err := resource.ParseQuantity(fmt.Sprint(live))
+	if err != nil {
+		return false
+	}
+
+	return d.Cmp(l) == 0
+}
+
+// scalarEqual compares the numbers of JSON and YAML decoders by value, and treats
+// zero values missing in live as equal since the API server omits them.
+func scalarEqual(desired, live interface{}) bool {
+
+	if live == nil {
+		return reflect.ValueOf(desired).IsZero()
+	}
+
+	if d, ok := number(desired); ok {
+		l, ok := number(live)
+		return ok && d == l
+	}
+
+	return reflect.DeepEqual(desired, live)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
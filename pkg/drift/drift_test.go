@@ -0,0 +1,197 @@
+package drift
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestDrift(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Drift Suite")
+}
+
+func object(manifest string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	ExpectWithOffset(1, yaml.Unmarshal([]byte(manifest), &obj.Object)).To(Succeed())
+	return obj
+}
+
+const desired = `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: simple-kmod-driver-container
+  labels:
+    app: simple-kmod
+spec:
+  template:
+    spec:
+      hostNetwork: false
+      containers:
+      - name: driver
+        image: quay.io/vendor/driver:1.0
+        resources: {}
+        securityContext:
+          privileged: true
+`
+
+var _ = Describe("Diff", func() {
+	It("should ignore the fields set by the API server", func() {
+		live := object(`
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: simple-kmod-driver-container
+  resourceVersion: "42"
+  labels:
+    app: simple-kmod
+  annotations:
+    specialresource.openshift.io/hash: "1234"
+spec:
+  revisionHistoryLimit: 10
+  template:
+    spec:
+      containers:
+      - name: driver
+        image: quay.io/vendor/driver:1.0
+        imagePullPolicy: IfNotPresent
+        securityContext:
+          privileged: true
+status:
+  numberReady: 1
+`)
+
+		Expect(Diff(object(desired), live)).To(BeEmpty())
+	})
+
+	It("should return the paths of the changed fields", func() {
+		live := object(`
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: simple-kmod-driver-container
+  labels:
+    app: patched
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+      - name: driver
+        image: quay.io/vendor/driver:1.0-hotfix
+        securityContext:
+          privileged: true
+      - name: debug
+        image: busybox
+`)
+
+		Expect(Diff(object(desired), live)).To(Equal([]string{
+			"metadata.labels.app",
+			"spec.template.spec.containers",
+			"spec.template.spec.hostNetwork",
+		}))
+
+		live.Object["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"] = []interface{}{
+			map[string]interface{}{"name": "driver", "image": "quay.io/vendor/driver:1.0-hotfix"},
+		}
+
+		Expect(Diff(object(desired), live)).To(ContainElements(
+			"spec.template.spec.containers[0].image",
+			"spec.template.spec.containers[0].securityContext",
+		))
+	})
+
+	It("should compare numbers by value", func() {
+		d := object(`{"spec": {"replicas": 1}}`)
+		l := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(1)}}}
+
+		Expect(Diff(d, l)).To(BeEmpty())
+	})
+
+	It("should compare quantities by value", func() {
+		d := object(`
+spec:
+  containers:
+  - name: driver
+    resources:
+      limits:
+        cpu: 1000m
+        memory: 1024Mi
+      requests:
+        cpu: 1
+  volumes:
+  - name: cache
+    emptyDir:
+      sizeLimit: 1Gi
+`)
+		l := object(`
+spec:
+  containers:
+  - name: driver
+    resources:
+      limits:
+        cpu: "1"
+        memory: 1Gi
+      requests:
+        cpu: "1"
+  volumes:
+  - name: cache
+    emptyDir:
+      sizeLimit: 1Gi
+`)
+
+		Expect(Diff(d, l)).To(BeEmpty())
+
+		l = object(`
+spec:
+  containers:
+  - name: driver
+    resources:
+      limits:
+        cpu: 500m
+        memory: 1Gi
+      requests:
+        cpu: "1"
+  volumes:
+  - name: cache
+    emptyDir:
+      sizeLimit: 2Gi
+`)
+
+		Expect(Diff(d, l)).To(Equal([]string{
+			"spec.containers[0].resources.limits.cpu",
+			"spec.volumes[0].emptyDir.sizeLimit",
+		}))
+	})
+
+	It("should not compare the stringData of a Secret", func() {
+		d := object(`
+apiVersion: v1
+kind: Secret
+metadata:
+  name: registry
+data:
+  token: c2VjcmV0
+stringData:
+  password: hunter2
+`)
+		l := object(`
+apiVersion: v1
+kind: Secret
+metadata:
+  name: registry
+data:
+  token: c2VjcmV0
+  password: aHVudGVyMg==
+`)
+
+		Expect(Diff(d, l)).To(BeEmpty())
+
+		Expect(unstructured.SetNestedField(l.Object, "b3RoZXI=", "data", "token")).To(Succeed())
+		Expect(Diff(d, l)).To(Equal([]string{"data.token"}))
+	})
+})
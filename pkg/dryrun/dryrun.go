@@ -0,0 +1,124 @@
+package dryrun
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// serverFields are the metadata fields set by the API server on every write, they
+// would make every diff differ.
+var serverFields = []string{"creationTimestamp", "generation", "managedFields", "resourceVersion", "selfLink", "uid"}
+
+// secretFields are the fields of a Secret whose values are redacted, the diffs are
+// stored in a ConfigMap readable by more users than the Secrets.
+var secretFields = []string{"data", "stringData"}
+
+var invalidKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// Change is an object that would be created or updated by a reconciliation.
+type Change struct {
+	Kind      string
+	Namespace string
+	Name      string
+
+	// Diff is the unified diff from the live object to the object returned by the
+	// API server in dry-run mode.
+	Diff string
+
+	// Error is the error of the dry run, if the API server rejected the object.
+	Error string
+}
+
+// Key returns the key of the change in a ConfigMap, <kind>.<namespace>.<name>.diff
+// without the namespace for cluster scoped objects.
+func (c Change) Key() string {
+
+	parts := []string{strings.ToLower(c.Kind)}
+	if c.Namespace != "" {
+		parts = append(parts, c.Namespace)
+	}
+	parts = append(parts, c.Name, "diff")
+
+	return invalidKeyChars.ReplaceAllString(strings.Join(parts, "."), "_")
+}
+
+// String returns the diff of the change, or its error.
+func (c Change) String() string {
+	if c.Error != "" {
+		return "error: " + c.Error + "\n"
+	}
+	return c.Diff
+}
+
+// Diff returns the unified diff from live, nil if the object does not exist yet, to
+// result, the object returned by the API server. The status and the metadata
+// fields set by the API server are left out, the values of a Secret are replaced by
+// their hash so a changed value still shows up in the diff.
+func Diff(live, result *unstructured.Unstructured) (string, error) {
+
+	a, err := manifest(live)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := manifest(result)
+	if err != nil {
+		return "", err
+	}
+
+	from := "live"
+	if live == nil {
+		from = "/dev/null"
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        a,
+		B:        b,
+		FromFile: from,
+		ToFile:   "dry-run",
+		Context:  3,
+	})
+}
+
+func manifest(obj *unstructured.Unstructured) ([]string, error) {
+
+	if obj == nil {
+		return nil, nil
+	}
+
+	obj = obj.DeepCopy()
+	for _, field := range serverFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	if obj.GetKind() == "Secret" {
+		redact(obj)
+	}
+
+	out, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	return difflib.SplitLines(string(out)), nil
+}
+
+func redact(obj *unstructured.Unstructured) {
+
+	for _, field := range secretFields {
+		values, ok := obj.Object[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, value := range values {
+			values[key] = fmt.Sprintf("<redacted sha256:%x>", sha256.Sum256([]byte(fmt.Sprint(value))))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package dryrun
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestDryRun(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DryRun Suite")
+}
+
+func object(manifest string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	ExpectWithOffset(1, yaml.Unmarshal([]byte(manifest), &obj.Object)).To(Succeed())
+	return obj
+}
+
+const live = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: simple-kmod
+  namespace: simple-kmod
+  resourceVersion: "42"
+  uid: 0f4e4c46-3b7a-4a4b-9d5e-1d2b7b1e8a52
+data:
+  kmod: simple-kmod
+  version: "1.0"
+`
+
+const secret = `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: simple-kmod
+  namespace: simple-kmod
+data:
+  token: c2VjcmV0
+stringData:
+  password: hunter2
+`
+
+var _ = Describe("Diff", func() {
+
+	It("should diff the changed fields only", func() {
+		result := object(live)
+		result.SetResourceVersion("43")
+		Expect(unstructured.SetNestedField(result.Object, "1.1", "data", "version")).To(Succeed())
+
+		diff, err := Diff(object(live), result)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(diff).To(HavePrefix("--- live\n+++ dry-run\n"))
+		Expect(diff).To(ContainSubstring("-  version: \"1.0\"\n+  version: \"1.1\"\n"))
+		Expect(diff).NotTo(ContainSubstring("resourceVersion"))
+		Expect(diff).NotTo(ContainSubstring("uid"))
+	})
+
+	It("should diff from nothing for a created object", func() {
+		diff, err := Diff(nil, object(live))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(diff).To(HavePrefix("--- /dev/null\n+++ dry-run\n"))
+		Expect(diff).To(ContainSubstring("+kind: ConfigMap\n"))
+	})
+
+	It("should redact the values of a Secret", func() {
+		result := object(secret)
+		Expect(unstructured.SetNestedField(result.Object, "Y2hhbmdlZA==", "data", "token")).To(Succeed())
+
+		diff, err := Diff(object(secret), result)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(diff).To(MatchRegexp(`-  token: <redacted sha256:[0-9a-f]{64}>\n\+  token: <redacted sha256:[0-9a-f]{64}>\n`))
+		Expect(diff).NotTo(ContainSubstring("c2VjcmV0"))
+		Expect(diff).NotTo(ContainSubstring("Y2hhbmdlZA=="))
+
+		diff, err = Diff(nil, object(secret))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(diff).To(ContainSubstring("+  password: <redacted sha256:"))
+		Expect(diff).NotTo(ContainSubstring("hunter2"))
+	})
+
+	It("should not diff an unchanged object", func() {
+		result := object(live)
+		result.SetResourceVersion("43")
+
+		Expect(Diff(object(live), result)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Change", func() {
+
+	DescribeTable("Key",
+		func(change Change, key string) {
+			Expect(change.Key()).To(Equal(key))
+		},
+		Entry("namespaced", Change{Kind: "DaemonSet", Namespace: "simple-kmod", Name: "driver"}, "daemonset.simple-kmod.driver.diff"),
+		Entry("cluster scoped", Change{Kind: "Namespace", Name: "simple-kmod"}, "namespace.simple-kmod.diff"),
+		Entry("invalid characters", Change{Kind: "ClusterRole", Name: "system:image-puller"}, "clusterrole.system_image-puller.diff"),
+	)
+
+	It("should print the error of a rejected object", func() {
+		change := Change{Diff: "", Error: `namespaces "simple-kmod" not found`}
+		Expect(change.String()).To(Equal("error: namespaces \"simple-kmod\" not found\n"))
+	})
+})
@@ -6,8 +6,10 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/go-logr/logr"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/pkg/kernel"
 	"github.com/openshift-psap/special-resource-operator/pkg/lifecycle"
 	"github.com/openshift-psap/special-resource-operator/pkg/storage"
@@ -44,6 +46,10 @@ type filter struct {
 	storage    storage.Storage
 	kernelData kernel.KernelData
 
+	// suspended records the SpecialResources whose reconciliation is suspended,
+	// by name, as seen in their own events
+	suspended sync.Map
+
 	mode string
 }
 
@@ -113,6 +119,40 @@ func (f *filter) owned(obj client.Object) bool {
 	return false
 }
 
+// trackSuspended records whether obj is suspended if it is a SpecialResource, and
+// returns true if that changed.
+func (f *filter) trackSuspended(obj client.Object) bool {
+
+	sr, ok := obj.(*srov1beta1.SpecialResource)
+	if !ok {
+		return false
+	}
+
+	suspended := sr.IsSuspended()
+	previous, _ := f.suspended.Load(sr.GetName())
+	f.suspended.Store(sr.GetName(), suspended)
+
+	return previous != nil && previous.(bool) != suspended
+}
+
+// ownerSuspended returns true if a suspended SpecialResource owns obj, its changes
+// must not be reverted.
+func (f *filter) ownerSuspended(obj client.Object) bool {
+
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.Kind != Kind {
+			continue
+		}
+		if suspended, ok := f.suspended.Load(owner.Name); ok && suspended.(bool) {
+			f.log.Info(f.mode+" Owner suspended", "Name", obj.GetName(), "Owner", owner.Name,
+				"Type", reflect.TypeOf(obj).String())
+			return true
+		}
+	}
+
+	return false
+}
+
 func (f *filter) GetPredicates() predicate.Predicate {
 	return predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
@@ -122,6 +162,12 @@ func (f *filter) GetPredicates() predicate.Predicate {
 			/* want to recreate it so handle the delete event */
 			obj := e.Object
 
+			f.trackSuspended(obj)
+
+			if f.ownerSuspended(obj) {
+				return false
+			}
+
 			if f.isSpecialResource(obj) {
 				return true
 			}
@@ -149,6 +195,15 @@ func (f *filter) GetPredicates() predicate.Predicate {
 
 			obj := e.ObjectNew
 
+			// Suspending or resuming does not necessarily change the generation
+			if f.trackSuspended(obj) {
+				return true
+			}
+
+			if f.ownerSuspended(obj) {
+				return false
+			}
+
 			// Required for the case when pods are deleted due to OS upgrade
 
 			if f.owned(obj) && f.kernelData.IsObjectAffine(obj) {
@@ -208,6 +263,15 @@ func (f *filter) GetPredicates() predicate.Predicate {
 			// If a specialresource dependency is deleted we
 			/* want to recreate it so handle the delete event */
 			obj := e.Object
+
+			if _, ok := obj.(*srov1beta1.SpecialResource); ok {
+				f.suspended.Delete(obj.GetName())
+			}
+
+			if f.ownerSuspended(obj) {
+				return false
+			}
+
 			if f.isSpecialResource(obj) {
 				return true
 			}
@@ -238,6 +302,11 @@ func (f *filter) GetPredicates() predicate.Predicate {
 			// If a specialresource dependency is updated we
 			// want to reconcile it, handle the update event
 			obj := e.Object
+
+			if f.ownerSuspended(obj) {
+				return false
+			}
+
 			if f.isSpecialResource(obj) {
 				return true
 			}
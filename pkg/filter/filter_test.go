@@ -427,3 +427,37 @@ var _ = Describe("Predicate", func() {
 		)
 	})
 })
+
+var _ = Describe("Suspended", func() {
+	owned := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: Kind, Name: "simple-kmod"},
+			},
+		},
+	}
+
+	It("should filter out the events of the objects owned by a suspended SpecialResource", func() {
+		sr := &v1beta1.SpecialResource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "simple-kmod",
+				Generation:  1,
+				Annotations: map[string]string{v1beta1.PausedAnnotation: "true"},
+			},
+		}
+
+		p := f.GetPredicates()
+
+		Expect(p.Create(event.CreateEvent{Object: sr})).To(BeTrue())
+		Expect(p.Create(event.CreateEvent{Object: owned})).To(BeFalse())
+		Expect(p.Generic(event.GenericEvent{Object: owned})).To(BeFalse())
+		Expect(p.Delete(event.DeleteEvent{Object: owned})).To(BeFalse())
+
+		// Removing the annotation does not change the generation
+		resumed := sr.DeepCopy()
+		resumed.SetAnnotations(nil)
+		Expect(p.Update(event.UpdateEvent{ObjectOld: sr, ObjectNew: resumed})).To(BeTrue())
+
+		Expect(p.Create(event.CreateEvent{Object: owned})).To(BeTrue())
+	})
+})
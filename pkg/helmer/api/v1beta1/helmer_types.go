@@ -27,23 +27,28 @@ type HelmRepo struct {
 	URL string `json:"url"`
 
 	// Username is used to log in against the Helm repository, if required.
+	// Deprecated: use SecretRef instead.
 	// +kubebuilder:validation:Optional
 	Username string `json:"username"`
 
 	// Password is used to log in against the Helm repository, if required.
+	// Deprecated: use SecretRef instead.
 	// +kubebuilder:validation:Optional
 	Password string `json:"password"`
 
 	// CertFile is the path to the client certificate file to be used to authenticate against the Helm repository,
 	// if required.
+	// Deprecated: use SecretRef instead.
 	// +kubebuilder:validation:Optional
 	CertFile string `json:"certFile"`
 
 	// KeyFile is the path to the private key file to be used to authenticate against the Helm repository, if required.
+	// Deprecated: use SecretRef instead.
 	// +kubebuilder:validation:Optional
 	KeyFile string `json:"keyFile"`
 
-	// CertFile is the path to the CA certificate file that was used to sign the Helm repository's certificate.
+	// CAFile is the path to the CA certificate file that was used to sign the Helm repository's certificate.
+	// Deprecated: use CAConfigMapRef instead.
 	// +kubebuilder:validation:Optional
 	CAFile string `json:"caFile"`
 
@@ -52,6 +57,88 @@ type HelmRepo struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=false
 	InsecureSkipTLSverify bool `json:"insecure_skip_tls_verify"`
+
+	// PlainHTTP pulls the charts of an OCI registry over HTTP instead of HTTPS, the credentials are then
+	// sent unencrypted.
+	// +kubebuilder:validation:Optional
+	PlainHTTP bool `json:"plainHTTP,omitempty"`
+
+	// SecretRef references a Secret holding the credentials used to log in against the Helm repository.
+	// The Secret either has the username and password keys, or is of type kubernetes.io/dockerconfigjson.
+	// A client certificate is read from the tls.crt and tls.key keys, a CA bundle from the ca.crt key.
+	// +kubebuilder:validation:Optional
+	SecretRef *HelmSecretRef `json:"secretRef,omitempty"`
+
+	// CAConfigMapRef references a ConfigMap holding the CA bundle that was used to sign the Helm
+	// repository's certificate.
+	// +kubebuilder:validation:Optional
+	CAConfigMapRef *HelmConfigMapRef `json:"caConfigMapRef,omitempty"`
+}
+
+// HelmSecretRef references a Secret.
+type HelmSecretRef struct {
+	// Name is the name of the Secret.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Secret, it defaults to the operator's namespace.
+	// Only the operator's namespace and the namespace of the SpecialResource are allowed.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// HelmConfigMapRef references a key of a ConfigMap.
+type HelmConfigMapRef struct {
+	// Name is the name of the ConfigMap.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ConfigMap, it defaults to the operator's namespace.
+	// Only the operator's namespace and the namespace of the SpecialResource are allowed.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key is the key of the CA bundle in the ConfigMap.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=ca.crt
+	Key string `json:"key,omitempty"`
+}
+
+// HelmGit describes a chart stored in a git repository.
+type HelmGit struct {
+	// Uri is the URL of the git repository, https:// and ssh:// URLs are supported.
+	// +kubebuilder:validation:Required
+	Uri string `json:"uri"`
+
+	// Ref is the branch, tag or full commit SHA to check out, it defaults to the remote HEAD.
+	// A commit SHA pins the chart, it is only fetched once.
+	// +kubebuilder:validation:Optional
+	Ref string `json:"ref,omitempty"`
+
+	// Path is the directory of the chart in the git repository, it defaults to the root.
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+
+	// SecretRef references a Secret holding the credentials of the git repository. The Secret either
+	// has the username and password keys for HTTPS, or the ssh-privatekey and optionally the known_hosts
+	// keys for SSH.
+	// +kubebuilder:validation:Optional
+	SecretRef *HelmSecretRef `json:"secretRef,omitempty"`
+}
+
+// HelmVerify describes how the signature of a chart is verified.
+type HelmVerify struct {
+	// Provider is either helm, to verify the chart's .prov file against a GnuPG keyring, or cosign, to
+	// verify the cosign signature of a chart in an OCI registry against a public key.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=helm;cosign
+	// +kubebuilder:default:=helm
+	Provider string `json:"provider,omitempty"`
+
+	// SecretRef references the Secret holding the public keyring in the keyring.gpg key for helm, or the
+	// public key in the cosign.pub key for cosign.
+	// +kubebuilder:validation:Required
+	SecretRef HelmSecretRef `json:"secretRef"`
 }
 
 // HelmChart describes a Helm Chart.
@@ -62,10 +149,23 @@ type HelmChart struct {
 	// Version is the chart's version.
 	Version string `json:"version"`
 
-	// Repository is the chart's repository information.
-	// +kubebuilder:validation:Required
+	// Repository is the chart's repository information. Charts are pulled from an OCI registry if the
+	// repository's URL starts with oci://.
+	// +kubebuilder:validation:Optional
 	Repository HelmRepo `json:"repository"`
 
+	// Git is the git repository the chart is cloned from, it replaces Repository.
+	// +kubebuilder:validation:Optional
+	Git *HelmGit `json:"git,omitempty"`
+
+	// Digest pins the chart to the digest of its manifest in an OCI registry, e.g. sha256:....
+	// +kubebuilder:validation:Optional
+	Digest string `json:"digest,omitempty"`
+
+	// Verify is the policy used to verify the chart before it is installed.
+	// +kubebuilder:validation:Optional
+	Verify *HelmVerify `json:"verify,omitempty"`
+
 	// Tags is a list of tags for this chart.
 	// +kubebuilder:validation:Optional
 	Tags []string `json:"tags"`
@@ -73,7 +173,17 @@ type HelmChart struct {
 
 func (in *HelmChart) DeepCopyInto(out *HelmChart) {
 	*out = *in
-	out.Repository = in.Repository
+	in.Repository.DeepCopyInto(&out.Repository)
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(HelmGit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(HelmVerify)
+		**out = **in
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make([]string, len(*in))
@@ -94,6 +204,16 @@ func (in *HelmChart) DeepCopy() *HelmChart {
 // DeepCopyInto is a manually created deepcopy function, copying the receiver, writing into out. in must be nonnil.
 func (in *HelmRepo) DeepCopyInto(out *HelmRepo) {
 	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(HelmSecretRef)
+		**out = **in
+	}
+	if in.CAConfigMapRef != nil {
+		in, out := &in.CAConfigMapRef, &out.CAConfigMapRef
+		*out = new(HelmConfigMapRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is a manually created deepcopy function, copying the receiver, creating a new HelmRepo.
@@ -105,3 +225,23 @@ func (in *HelmRepo) DeepCopy() *HelmRepo {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is a manually created deepcopy function, copying the receiver, writing into out. in must be nonnil.
+func (in *HelmGit) DeepCopyInto(out *HelmGit) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(HelmSecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is a manually created deepcopy function, copying the receiver, creating a new HelmGit.
+func (in *HelmGit) DeepCopy() *HelmGit {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmGit)
+	in.DeepCopyInto(out)
+	return out
+}
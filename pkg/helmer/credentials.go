@@ -0,0 +1,274 @@
+package helmer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// Keys of the Secret referenced by a repository's secretRef
+	SecretUsernameKey = "username"
+	SecretPasswordKey = "password"
+
+	// DefaultCAKey is the key of the CA bundle in the ConfigMap referenced by caConfigMapRef
+	DefaultCAKey = "ca.crt"
+
+	redacted = "REDACTED"
+)
+
+// repoAuth holds the credentials of a repository. They are only kept in memory,
+// files are written by materialize for the time of a single Load.
+type repoAuth struct {
+	username string
+	password string
+	cert     []byte
+	key      []byte
+	ca       []byte
+}
+
+func refNamespace(namespace string) string {
+	if namespace == "" {
+		return os.Getenv("OPERATOR_NAMESPACE")
+	}
+	return namespace
+}
+
+// checkRefNamespaces rejects the Secret and ConfigMap references of spec to a
+// namespace other than the operator's and namespace, the namespace of the
+// SpecialResource, the operator would otherwise read them on its behalf.
+func checkRefNamespaces(spec helmerv1beta1.HelmChart, namespace string) error {
+
+	type ref struct{ kind, name, namespace string }
+
+	var refs []ref
+	if r := spec.Repository.SecretRef; r != nil {
+		refs = append(refs, ref{"Secret", r.Name, r.Namespace})
+	}
+	if r := spec.Repository.CAConfigMapRef; r != nil {
+		refs = append(refs, ref{"ConfigMap", r.Name, r.Namespace})
+	}
+	if spec.Git != nil && spec.Git.SecretRef != nil {
+		refs = append(refs, ref{"Secret", spec.Git.SecretRef.Name, spec.Git.SecretRef.Namespace})
+	}
+	if spec.Verify != nil {
+		refs = append(refs, ref{"Secret", spec.Verify.SecretRef.Name, spec.Verify.SecretRef.Namespace})
+	}
+
+	for _, r := range refs {
+		if ns := refNamespace(r.namespace); ns != namespace && ns != os.Getenv("OPERATOR_NAMESPACE") {
+			return fmt.Errorf("%s %s/%s of chart %s is neither in the operator's namespace nor in %s", r.kind, ns, r.name, spec.Name, namespace)
+		}
+	}
+
+	return nil
+}
+
+// repoAuth reads the credentials of the repository from the referenced Secret
+// and ConfigMap, falling back to the deprecated inline fields. They are read on
+// every Load so that rotated credentials are picked up without a restart.
+func (h *helmer) repoAuth(ctx context.Context, spec helmerv1beta1.HelmRepo, host string) (*repoAuth, error) {
+
+	auth := &repoAuth{username: spec.Username, password: spec.Password}
+
+	for _, f := range []struct {
+		path string
+		data *[]byte
+	}{
+		{spec.CertFile, &auth.cert},
+		{spec.KeyFile, &auth.key},
+		{spec.CAFile, &auth.ca},
+	} {
+		if f.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s of repository %s: %w", f.path, spec.Name, err)
+		}
+		*f.data = data
+	}
+
+	if spec.SecretRef != nil {
+		namespace := refNamespace(spec.SecretRef.Namespace)
+
+		secret, err := h.kubeClient.GetSecret(ctx, namespace, spec.SecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("cannot get credentials Secret %s/%s of repository %s: %w", namespace, spec.SecretRef.Name, spec.Name, err)
+		}
+
+		if secret.Type == corev1.SecretTypeDockerConfigJson {
+			if auth.username, auth.password, err = dockerConfigCredentials(secret.Data[corev1.DockerConfigJsonKey], host); err != nil {
+				return nil, err
+			}
+		} else {
+			auth.username = string(secret.Data[SecretUsernameKey])
+			auth.password = string(secret.Data[SecretPasswordKey])
+		}
+
+		if cert, found := secret.Data[corev1.TLSCertKey]; found {
+			auth.cert = cert
+			auth.key = secret.Data[corev1.TLSPrivateKeyKey]
+		}
+		if ca, found := secret.Data[DefaultCAKey]; found {
+			auth.ca = ca
+		}
+	}
+
+	if spec.CAConfigMapRef != nil {
+		namespace := refNamespace(spec.CAConfigMapRef.Namespace)
+
+		key := spec.CAConfigMapRef.Key
+		if key == "" {
+			key = DefaultCAKey
+		}
+
+		cm := &corev1.ConfigMap{}
+		if err := h.kubeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.CAConfigMapRef.Name}, cm); err != nil {
+			return nil, fmt.Errorf("cannot get CA ConfigMap %s/%s of repository %s: %w", namespace, spec.CAConfigMapRef.Name, spec.Name, err)
+		}
+
+		ca, found := cm.Data[key]
+		if !found {
+			return nil, fmt.Errorf("CA ConfigMap %s/%s of repository %s has no key %s", namespace, spec.CAConfigMapRef.Name, spec.Name, key)
+		}
+		auth.ca = []byte(ca)
+	}
+
+	return auth, nil
+}
+
+// dockerConfigCredentials returns the credentials of host from a .dockerconfigjson.
+func dockerConfigCredentials(data []byte, host string) (string, string, error) {
+
+	config := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}{}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", "", fmt.Errorf("cannot parse %s: %w", corev1.DockerConfigJsonKey, err)
+	}
+
+	for registry, auth := range config.Auths {
+		registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+		if strings.SplitN(registry, "/", 2)[0] != host {
+			continue
+		}
+
+		if auth.Auth == "" {
+			return auth.Username, auth.Password, nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot decode the auth of %s: %w", host, err)
+		}
+
+		credentials := strings.SplitN(string(decoded), ":", 2)
+		if len(credentials) != 2 {
+			return "", "", fmt.Errorf("invalid auth for %s", host)
+		}
+
+		return credentials[0], credentials[1], nil
+	}
+
+	return "", "", nil
+}
+
+// tlsConfig returns the TLS configuration of the repository built in memory, nil
+// if the defaults apply.
+func (a *repoAuth) tlsConfig(insecure bool) (*tls.Config, error) {
+
+	if a.cert == nil && a.ca == nil && !insecure {
+		return nil, nil
+	}
+
+	config := &tls.Config{InsecureSkipVerify: insecure} // #nosec G402
+
+	if a.cert != nil {
+		cert, err := tls.X509KeyPair(a.cert, a.key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if a.ca != nil {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(a.ca) {
+			return nil, fmt.Errorf("no valid certificate in the CA bundle")
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// materialize writes the client certificate, key and CA bundle to a private
+// temporary directory for the Helm getters, which only accept file paths. The
+// returned cleanup removes the directory.
+func (a *repoAuth) materialize() (certFile, keyFile, caFile string, cleanup func(), err error) {
+
+	cleanup = func() {}
+
+	if a.cert == nil && a.ca == nil {
+		return "", "", "", cleanup, nil
+	}
+
+	// MkdirTemp creates the directory with mode 0700
+	dir, err := os.MkdirTemp("", "helm-repo-")
+	if err != nil {
+		return "", "", "", cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	write := func(name string, data []byte) (string, error) {
+		if data == nil {
+			return "", nil
+		}
+		path := filepath.Join(dir, name)
+		return path, os.WriteFile(path, data, 0600)
+	}
+
+	if certFile, err = write("tls.crt", a.cert); err == nil {
+		if keyFile, err = write("tls.key", a.key); err == nil {
+			caFile, err = write("ca.crt", a.ca)
+		}
+	}
+
+	if err != nil {
+		cleanup()
+		return "", "", "", func() {}, fmt.Errorf("cannot write repository credentials: %w", err)
+	}
+
+	return certFile, keyFile, caFile, cleanup, nil
+}
+
+// Redact replaces the inline credentials of the repository, so that they do not
+// show up in values passed to templates or in debug output.
+func Redact(spec *helmerv1beta1.HelmRepo) {
+	if spec.Username != "" {
+		spec.Username = redacted
+	}
+	if spec.Password != "" {
+		spec.Password = redacted
+	}
+}
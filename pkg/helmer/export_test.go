@@ -0,0 +1,11 @@
+package helmer
+
+var OCIReference = ociReference
+
+// AllowGitFileProtocol lets the tests load charts from local git repositories,
+// the returned function restores the protocols allowed in production.
+func AllowGitFileProtocol() func() {
+	protocols := gitProtocols
+	gitProtocols = append([]string{"file"}, protocols...)
+	return func() { gitProtocols = protocols }
+}
@@ -0,0 +1,367 @@
+package helmer
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/utils"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// GitCommitAnnotation is set on charts loaded from git to the commit they were loaded from
+	GitCommitAnnotation = "specialresource.openshift.io/git-commit"
+
+	// Keys of the Secret referenced by a git source for SSH
+	SecretKnownHostsKey = "known_hosts"
+
+	// askPass answers the username and password prompts of git from the environment
+	askPass = `#!/bin/sh
+case "$1" in
+Username*) echo "$SRO_GIT_USERNAME" ;;
+*) echo "$SRO_GIT_PASSWORD" ;;
+esac
+`
+)
+
+var (
+	commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+	// scpLike matches the [user@]host:path form of SSH URLs, a transport helper
+	// address such as ext::command has no host before its colon
+	scpLike = regexp.MustCompile(`^([A-Za-z0-9._-]+@)?[A-Za-z0-9][A-Za-z0-9.-]*:[^:]`)
+
+	// gitProtocols are the protocols git is allowed to use, as GIT_ALLOW_PROTOCOL.
+	// Local repositories are only allowed by the tests, a chart must not read
+	// the file system of the operator.
+	gitProtocols = []string{"https", "ssh"}
+)
+
+// GitPollInterval is the interval at which charts tracking a git branch or tag are fetched again.
+var GitPollInterval = 5 * time.Minute
+
+// GitCacheTTL is how long the cached repository of a git source is kept once no
+// chart is loaded from it anymore, e.g. after its SpecialResource was deleted.
+var GitCacheTTL = 24 * time.Hour
+
+// TracksGitRef returns true if the chart is loaded from a git branch or tag, which can move.
+func TracksGitRef(spec helmerv1beta1.HelmChart) bool {
+	return spec.Git != nil && !commitSHA.MatchString(spec.Git.Ref)
+}
+
+// validateGit rejects the URIs and refs git could take for options or for
+// another transport than HTTPS or SSH.
+func validateGit(spec *helmerv1beta1.HelmGit) error {
+
+	if strings.HasPrefix(spec.Ref, "-") {
+		return fmt.Errorf("invalid git ref %q", spec.Ref)
+	}
+
+	if strings.Contains(spec.Uri, "://") {
+		u, err := url.Parse(spec.Uri)
+		if err != nil {
+			return fmt.Errorf("invalid git repository %q: %w", spec.Uri, err)
+		}
+		for _, p := range gitProtocols {
+			if u.Scheme == p {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid git repository %q: scheme should be one of %s", spec.Uri, strings.Join(gitProtocols, ", "))
+	}
+
+	if !scpLike.MatchString(spec.Uri) {
+		return fmt.Errorf("invalid git repository %q: should be an %s:// URL or [user@]host:path", spec.Uri, strings.Join(gitProtocols, ":// or "))
+	}
+
+	return nil
+}
+
+// gitCacheDir returns the bare repository caching the fetched commits of uri.
+func (h *helmer) gitCacheDir(uri string) string {
+	sum := sha256.Sum256([]byte(uri))
+	return filepath.Join(h.settings.RepositoryCache, "git", hex.EncodeToString(sum[:]))
+}
+
+// pruneGitCache removes the cached repositories other than gitDir that no chart
+// was loaded from for GitCacheTTL.
+func (h *helmer) pruneGitCache(gitDir string) {
+
+	cacheDir := filepath.Dir(gitDir)
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			utils.WarnOnError(err)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		dir := filepath.Join(cacheDir, entry.Name())
+		if dir == gitDir {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < GitCacheTTL {
+			continue
+		}
+
+		h.log.Info("Removing unused git cache", "dir", dir)
+
+		if err = os.RemoveAll(dir); err != nil {
+			utils.WarnOnError(err)
+		}
+	}
+}
+
+// gitEnv returns the environment of git commands for the credentials of the
+// source. Credentials are passed through the environment or written to a
+// private temporary directory removed by the returned cleanup.
+func (h *helmer) gitEnv(ctx context.Context, spec *helmerv1beta1.HelmGit) ([]string, func(), error) {
+
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL="+strings.Join(gitProtocols, ":"))
+	cleanup := func() {}
+
+	if spec.SecretRef == nil {
+		return env, cleanup, nil
+	}
+
+	namespace := refNamespace(spec.SecretRef.Namespace)
+
+	secret, err := h.kubeClient.GetSecret(ctx, namespace, spec.SecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("cannot get credentials Secret %s/%s of git repository %s: %w", namespace, spec.SecretRef.Name, spec.Uri, err)
+	}
+
+	// MkdirTemp creates the directory with mode 0700
+	dir, err := os.MkdirTemp("", "helm-git-")
+	if err != nil {
+		return nil, cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	fail := func(err error) ([]string, func(), error) {
+		cleanup()
+		return nil, func() {}, fmt.Errorf("cannot write git credentials: %w", err)
+	}
+
+	if key, found := secret.Data[corev1.SSHAuthPrivateKey]; found {
+		keyFile := filepath.Join(dir, "id")
+		if err = os.WriteFile(keyFile, key, 0600); err != nil {
+			return fail(err)
+		}
+
+		// Without known hosts the host key is accepted on first use, the
+		// file does not outlive the command
+		knownHosts := filepath.Join(dir, SecretKnownHostsKey)
+		checking := "accept-new"
+		if hosts, found := secret.Data[SecretKnownHostsKey]; found {
+			if err = os.WriteFile(knownHosts, hosts, 0600); err != nil {
+				return fail(err)
+			}
+			checking = "yes"
+		}
+
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes -o UserKnownHostsFile=%s -o StrictHostKeyChecking=%s",
+			keyFile, knownHosts, checking))
+	}
+
+	if _, found := secret.Data[SecretUsernameKey]; found {
+		script := filepath.Join(dir, "askpass")
+		if err = os.WriteFile(script, []byte(askPass), 0700); err != nil {
+			return fail(err)
+		}
+		env = append(env,
+			"GIT_ASKPASS="+script,
+			"SRO_GIT_USERNAME="+string(secret.Data[SecretUsernameKey]),
+			"SRO_GIT_PASSWORD="+string(secret.Data[SecretPasswordKey]))
+	}
+
+	if ca, found := secret.Data[DefaultCAKey]; found {
+		caFile := filepath.Join(dir, DefaultCAKey)
+		if err = os.WriteFile(caFile, ca, 0600); err != nil {
+			return fail(err)
+		}
+		env = append(env, "GIT_SSL_CAINFO="+caFile)
+	}
+
+	return env, cleanup, nil
+}
+
+func git(ctx context.Context, env []string, gitDir string, args ...string) ([]byte, error) {
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--git-dir", gitDir}, args...)...)
+	cmd.Env = env
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+// fetchGit shallow fetches the ref of the source into the cache and returns the
+// commit it resolves to. A commit SHA that is already cached is not fetched again.
+func (h *helmer) fetchGit(ctx context.Context, spec *helmerv1beta1.HelmGit, gitDir string, env []string) (string, error) {
+
+	ref := spec.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	if _, err := os.Stat(gitDir); errors.Is(err, os.ErrNotExist) {
+		if err = os.MkdirAll(gitDir, 0755); err != nil {
+			return "", err
+		}
+		if _, err = git(ctx, env, gitDir, "init", "--bare", "--quiet"); err != nil {
+			return "", err
+		}
+	}
+
+	if commitSHA.MatchString(ref) {
+		if _, err := git(ctx, env, gitDir, "cat-file", "-e", "--end-of-options", ref+"^{commit}"); err == nil {
+			return ref, nil
+		}
+	}
+
+	h.log.Info("Fetching", "git", spec.Uri, "ref", ref)
+
+	if _, err := git(ctx, env, gitDir, "fetch", "--quiet", "--depth", "1", "--no-tags", "--end-of-options", spec.Uri, ref); err != nil {
+		return "", fmt.Errorf("cannot fetch %s of %s: %w", ref, spec.Uri, err)
+	}
+
+	out, err := git(ctx, env, gitDir, "rev-parse", "--verify", "--end-of-options", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// loadGit loads the chart in the path of a git repository at the given ref.
+// Branches and tags are fetched on every load so that a moved ref shows up as
+// a new chart revision, annotated with the commit it was loaded from.
+func (h *helmer) loadGit(ctx context.Context, spec helmerv1beta1.HelmChart) (*chart.Chart, error) {
+
+	if err := verifyGit(spec); err != nil {
+		return nil, err
+	}
+
+	if err := validateGit(spec.Git); err != nil {
+		return nil, err
+	}
+
+	env, cleanup, err := h.gitEnv(ctx, spec.Git)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	gitDir := h.gitCacheDir(spec.Git.Uri)
+	h.pruneGitCache(gitDir)
+
+	commit, err := h.fetchGit(ctx, spec.Git, gitDir, env)
+	if err != nil {
+		return nil, err
+	}
+
+	// A pinned commit is not fetched again, the cache is marked as used
+	now := time.Now()
+	if err = os.Chtimes(gitDir, now, now); err != nil {
+		utils.WarnOnError(err)
+	}
+
+	dir := strings.Trim(path.Clean("/"+spec.Git.Path), "/")
+
+	args := []string{"archive", "--format=tar", "--end-of-options", commit}
+	if dir != "" {
+		args = append(args, dir)
+	}
+
+	archive, err := git(ctx, env, gitDir, args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s at %s of %s: %w", spec.Git.Path, commit, spec.Git.Uri, err)
+	}
+
+	files, err := chartFiles(archive, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := loader.LoadFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load chart %s at %s of %s: %w", spec.Git.Path, commit, spec.Git.Uri, err)
+	}
+
+	if loaded.Metadata.Annotations == nil {
+		loaded.Metadata.Annotations = make(map[string]string)
+	}
+	loaded.Metadata.Annotations[GitCommitAnnotation] = commit
+
+	h.log.Info("Loaded", "chart", loaded.Name(), "git", spec.Git.Uri, "commit", commit)
+
+	return loaded, nil
+}
+
+// chartFiles returns the files of the tar archive below dir, relative to dir.
+func chartFiles(archive []byte, dir string) ([]*loader.BufferedFile, error) {
+
+	var files []*loader.BufferedFile
+
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read git archive: %w", err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := hdr.Name
+		if dir != "" {
+			name = strings.TrimPrefix(name, dir+"/")
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s from git archive: %w", hdr.Name, err)
+		}
+
+		files = append(files, &loader.BufferedFile{Name: name, Data: data})
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no chart in %q", dir)
+	}
+
+	return files, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -17,11 +18,15 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
 	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
 	helmtime "helm.sh/helm/v3/pkg/time"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,6 +46,17 @@ func DefaultSettings() *cli.EnvSettings {
 	return s
 }
 
+// WebhookSettings returns the settings of the helmer of the admission webhooks,
+// its repository config and cache are apart from the reconciler's.
+func WebhookSettings() *cli.EnvSettings {
+	s := DefaultSettings()
+
+	s.RepositoryConfig = "/cache/helm/webhook/repositories/config.yaml"
+	s.RepositoryCache = "/cache/helm/webhook/cache"
+
+	return s
+}
+
 func OpenShiftInstallOrder() {
 	// Mutates helm package exported variables
 	idx := utils.StringSliceFind(releaseutil.InstallOrder, "Service")
@@ -51,9 +67,12 @@ func OpenShiftInstallOrder() {
 	releaseutil.InstallOrder = utils.StringSliceInsert(releaseutil.InstallOrder, idx, "Certificates")
 }
 
+//go:generate mockgen -source=helmer.go -package=helmer -destination=mock_helmer_api.go
+
 type Helmer interface {
-	Load(helmerv1beta1.HelmChart) (*chart.Chart, error)
-	Run(context.Context, chart.Chart, map[string]interface{}, v1.Object, string, string, map[string]string, string, string, bool) error
+	Load(context.Context, helmerv1beta1.HelmChart, string) (*chart.Chart, error)
+	Run(context.Context, chart.Chart, map[string]interface{}, v1.Object, string, string, map[string]string, string, string, bool, bool, postrender.PostRenderer, *resource.Drivers) (*resource.Result, error)
+	Template(chart.Chart, map[string]interface{}, string, postrender.PostRenderer) (string, error)
 }
 
 type helmer struct {
@@ -64,6 +83,10 @@ type helmer struct {
 	kubeClient      clients.ClientsInterface
 	repoFile        *repo.File
 	settings        *cli.EnvSettings
+
+	// capabilities are the capabilities of the cluster the charts are rendered
+	// for without contacting it, nil if they are run against a cluster
+	capabilities *chartutil.Capabilities
 }
 
 func NewHelmer(creator resource.Creator, settings *cli.EnvSettings, kubeClient clients.ClientsInterface) *helmer {
@@ -81,6 +104,14 @@ func NewHelmer(creator resource.Creator, settings *cli.EnvSettings, kubeClient c
 	}
 }
 
+// NewOfflineHelmer returns a Helmer rendering the charts for a cluster with capabilities
+// without contacting it, the releases are only kept in memory.
+func NewOfflineHelmer(creator resource.Creator, settings *cli.EnvSettings, kubeClient clients.ClientsInterface, capabilities *chartutil.Capabilities) *helmer {
+	h := NewHelmer(creator, settings, kubeClient)
+	h.capabilities = capabilities
+	return h
+}
+
 func init() {
 	OpenShiftInstallOrder()
 }
@@ -102,7 +133,16 @@ func (h *helmer) AddorUpdateRepo(entry *repo.Entry) error {
 		return nil
 	}
 
-	h.repoFile.Update(entry)
+	// Credentials are materialized for a single Load only and must not be
+	// persisted with the repository
+	stored := *entry
+	stored.Username = ""
+	stored.Password = ""
+	stored.CertFile = ""
+	stored.KeyFile = ""
+	stored.CAFile = ""
+
+	h.repoFile.Update(&stored)
 
 	if err = h.repoFile.WriteFile(h.settings.RepositoryConfig, 0644); err != nil {
 		return fmt.Errorf("could not write repository config %s: %w", h.settings.RepositoryConfig, err)
@@ -111,33 +151,60 @@ func (h *helmer) AddorUpdateRepo(entry *repo.Entry) error {
 	return nil
 }
 
-func (h *helmer) Load(spec helmerv1beta1.HelmChart) (*chart.Chart, error) {
+// Load loads the chart of spec, namespace is the namespace of the SpecialResource.
+// Its Secret and ConfigMap references may only point to it or to the operator's.
+func (h *helmer) Load(ctx context.Context, spec helmerv1beta1.HelmChart, namespace string) (*chart.Chart, error) {
+
+	if err := checkRefNamespaces(spec, namespace); err != nil {
+		return nil, err
+	}
+
+	if spec.Git != nil {
+		return h.loadGit(ctx, spec)
+	}
+
+	if IsOCI(spec.Repository.URL) {
+		return h.loadOCI(ctx, spec)
+	}
+
+	auth, err := h.repoAuth(ctx, spec.Repository, "")
+	if err != nil {
+		return nil, err
+	}
+
+	certFile, keyFile, caFile, cleanup, err := auth.materialize()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
 
 	entry := &repo.Entry{
 		Name:                  spec.Repository.Name,
 		URL:                   spec.Repository.URL,
-		Username:              spec.Repository.Username,
-		Password:              spec.Repository.Password,
-		CertFile:              spec.Repository.CertFile,
-		KeyFile:               spec.Repository.KeyFile,
-		CAFile:                spec.Repository.CAFile,
+		Username:              auth.username,
+		Password:              auth.password,
+		CertFile:              certFile,
+		KeyFile:               keyFile,
+		CAFile:                caFile,
 		InsecureSkipTLSverify: spec.Repository.InsecureSkipTLSverify,
 	}
 
-	if err := h.AddorUpdateRepo(entry); err != nil {
+	if err = h.AddorUpdateRepo(entry); err != nil {
 		utils.WarnOnError(err)
 		return nil, err
 	}
 
+	// The credentials are passed to the downloader directly, the repository
+	// config on disk does not hold them
 	act := action.ChartPathOptions{
-		CaFile:                "",
-		CertFile:              "",
-		KeyFile:               "",
+		CaFile:                entry.CAFile,
+		CertFile:              entry.CertFile,
+		KeyFile:               entry.KeyFile,
 		InsecureSkipTLSverify: entry.InsecureSkipTLSverify,
 		Keyring:               "",
-		Password:              "",
+		Password:              entry.Password,
 		RepoURL:               "",
-		Username:              "",
+		Username:              entry.Username,
 		Verify:                false,
 		Version:               spec.Version,
 	}
@@ -146,13 +213,19 @@ func (h *helmer) Load(spec helmerv1beta1.HelmChart) (*chart.Chart, error) {
 	repoChartName := entry.Name + "/" + spec.Name
 	h.log.Info("Locating", "chart", repoChartName)
 
-	var err error
 	var path string
 
 	if path, err = act.LocateChart(repoChartName, h.settings); err != nil {
 		return nil, fmt.Errorf("Could not locate chart %s: %w", repoChartName, err)
 	}
 
+	if spec.Verify != nil {
+		if err = h.verifyRepoChart(ctx, spec, entry, path); err != nil {
+			return nil, err
+		}
+		h.log.Info("Verified", "chart", repoChartName, "provider", verifyProvider(spec.Verify))
+	}
+
 	loaded, err := loader.Load(path)
 
 	return loaded, err
@@ -164,6 +237,28 @@ func (h *helmer) logWrap(format string, v ...interface{}) {
 	h.log.Info("Helm", "internal", msg)
 }
 
+// dryRunReleases replaces the release storage with an in-memory one holding the
+// history of the release name, the one-time objects of an installed release are
+// still skipped but the releases of the dry run are not stored.
+func (h *helmer) dryRunReleases(name string) error {
+
+	history, err := h.actionConfig.Releases.History(name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return fmt.Errorf("cannot get the history of release %s: %w", name, err)
+	}
+
+	releases := storage.Init(driver.NewMemory())
+	for _, rel := range history {
+		if err = releases.Create(rel); err != nil {
+			return fmt.Errorf("cannot copy release %s: %w", rel.Name, err)
+		}
+	}
+
+	h.actionConfig.Releases = releases
+
+	return nil
+}
+
 func (h *helmer) failRelease(rel *release.Release, err error) error {
 	rel.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", rel.Name, err.Error()))
 	if e := h.actionConfig.Releases.Update(rel); e != nil {
@@ -172,8 +267,10 @@ func (h *helmer) failRelease(rel *release.Release, err error) error {
 	return err
 }
 
-func (h *helmer) deleteHookByPolicy(hook *release.Hook, policy release.HookDeletePolicy) error {
-	if hook.Kind == "CustomResourceDefinition" {
+// deleteHookByPolicy deletes the objects of hook if it has policy, nothing is deleted
+// in dry-run mode.
+func (h *helmer) deleteHookByPolicy(hook *release.Hook, policy release.HookDeletePolicy, dryRun bool) error {
+	if hook.Kind == "CustomResourceDefinition" || dryRun {
 		return nil
 	}
 	found := false
@@ -201,19 +298,74 @@ func (h *helmer) deleteHookByPolicy(hook *release.Hook, policy release.HookDelet
 	return nil
 }
 
-func (h *helmer) InstallCRDs(ctx context.Context, crds []chart.CRD, owner v1.Object, name string, namespace string) error {
+func (h *helmer) InstallCRDs(ctx context.Context, crds []chart.CRD, owner v1.Object, name string, namespace string) (*resource.Result, error) {
 
 	var manifests bytes.Buffer
 
 	for _, crd := range crds {
 		fmt.Fprintf(&manifests, "---\n# Source: %s\n%s\n", crd.Filename, crd.File.Data)
 	}
-	if err := h.creator.CreateFromYAML(ctx, manifests.Bytes(),
-		false, owner, name, namespace, nil, "", ""); err != nil {
-		return err
+
+	return h.creator.CreateFromYAML(ctx, manifests.Bytes(),
+		false, owner, name, namespace, nil, "", "", nil)
+}
+
+// newInstall returns the install action rendering ch with cfg, it only renders the
+// manifests and hooks which are applied by the creator.
+func (h *helmer) newInstall(cfg *action.Configuration, ch chart.Chart, namespace string, postRenderer postrender.PostRenderer) (*action.Install, error) {
+
+	install := action.NewInstall(cfg)
+
+	install.DryRun = true
+	install.ReleaseName = ch.Metadata.Name
+	install.Replace = false
+	install.ClientOnly = false
+	install.APIVersions = []string{}
+	install.IncludeCRDs = false
+	install.Namespace = namespace
+	install.DisableHooks = false
+	install.IsUpgrade = false
+	install.Timeout = time.Second * 300
+	install.PostRenderer = postRenderer
+
+	if h.capabilities != nil {
+		install.ClientOnly = true
+		install.KubeVersion = &h.capabilities.KubeVersion
+		install.APIVersions = h.capabilities.APIVersions
 	}
 
-	return nil
+	if install.Version == "" {
+		install.Version = ">0.0.0-0"
+	}
+
+	if ch.Metadata.Type != "" && ch.Metadata.Type != "application" {
+		return nil, fmt.Errorf("Chart has an unsupported type %s and can not be installed", ch.Metadata.Type)
+	}
+
+	return install, nil
+}
+
+// Template renders the manifests of ch with vals without applying them, the CRDs
+// and hooks of the chart are left out.
+func (h *helmer) Template(ch chart.Chart, vals map[string]interface{}, namespace string, postRenderer postrender.PostRenderer) (string, error) {
+
+	cfg := new(action.Configuration)
+
+	if err := cfg.Init(h.settings.RESTClientGetter(), namespace, "configmaps", h.logWrap); err != nil {
+		return "", fmt.Errorf("Cannot initialize helm action config: %w", err)
+	}
+
+	install, err := h.newInstall(cfg, ch, namespace, postRenderer)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := install.Run(&ch, vals)
+	if err != nil {
+		return "", err
+	}
+
+	return rel.Manifest, nil
 }
 
 func (h *helmer) Run(
@@ -226,57 +378,67 @@ func (h *helmer) Run(
 	nodeSelector map[string]string,
 	kernelFullVersion string,
 	operatingSystemMajorMinor string,
-	debug bool) error {
+	debug bool,
+	dryRun bool,
+	postRenderer postrender.PostRenderer,
+	drivers *resource.Drivers) (*resource.Result, error) {
+
+	res := &resource.Result{}
 
 	h.actionConfig = new(action.Configuration)
 
 	err := h.actionConfig.Init(h.settings.RESTClientGetter(), namespace, "configmaps", h.logWrap)
 	if err != nil {
-		return fmt.Errorf("Cannot initialize helm action config: %w", err)
+		return res, fmt.Errorf("Cannot initialize helm action config: %w", err)
 	}
 
-	install := action.NewInstall(h.actionConfig)
-
-	install.DryRun = true
-	install.ReleaseName = ch.Metadata.Name
-	install.Replace = false
-	install.ClientOnly = false
-	install.APIVersions = []string{}
-	install.IncludeCRDs = false
-	install.Namespace = namespace
-	install.DisableHooks = false
-	install.IsUpgrade = false
-	install.Timeout = time.Second * 300
-
-	if install.Version == "" {
-		install.Version = ">0.0.0-0"
+	switch {
+	case h.capabilities != nil:
+		h.actionConfig.Releases = storage.Init(driver.NewMemory())
+	case dryRun:
+		if err = h.dryRunReleases(name); err != nil {
+			return res, err
+		}
 	}
 
-	if ch.Metadata.Type != "" && ch.Metadata.Type != "application" {
-		return fmt.Errorf("Chart has an unsupported type %s and can not be installed", ch.Metadata.Type)
+	install, err := h.newInstall(h.actionConfig, ch, namespace, postRenderer)
+	if err != nil {
+		return res, err
 	}
 
 	// Pre-install anything in the crd/ directory. We do this before Helm
 	// contacts the upstream server and builds the capabilities object.
-	if crds := ch.CRDObjects(); !install.ClientOnly && !install.SkipCRDs && len(crds) > 0 {
+	if crds := ch.CRDObjects(); !install.SkipCRDs && len(crds) > 0 {
 
 		h.log.Info("Release CRDs")
-		err := h.InstallCRDs(ctx, crds, owner, install.ReleaseName, install.Namespace)
+		crdRes, err := h.InstallCRDs(ctx, crds, owner, install.ReleaseName, install.Namespace)
+		res.Add(crdRes)
 		if err != nil {
-			return fmt.Errorf("Cannot install CRDs: %w", err)
+			return res, fmt.Errorf("Cannot install CRDs: %w", err)
 		}
 	}
 
 	rel, err := install.Run(&ch, vals)
 	if err != nil {
 		utils.WarnOnError(err)
-		return err
+		return res, err
+	}
+
+	// Helm only post-renders the manifests, the hooks are rendered objects too
+	if postRenderer != nil {
+		for _, hk := range rel.Hooks {
+			out, err := postRenderer.Run(bytes.NewBufferString(hk.Manifest))
+			if err != nil {
+				return res, fmt.Errorf("error while running post render on hook %s: %w", hk.Path, err)
+			}
+			hk.Manifest = out.String()
+		}
 	}
 
 	if debug {
 		json, err := json.MarshalIndent(vals, "", " ")
 		if err != nil {
-			return err
+			return res, err
 		}
 		h.log.Info("Debug active. Showing manifests", "json", json, "manifest", rel.Manifest)
 		for _, hook := range rel.Hooks {
@@ -297,14 +459,14 @@ func (h *helmer) Run(
 	h.log.Info("Release pre-install hooks")
 	// pre-install hooks
 	if !install.DisableHooks {
-		if err := h.ExecHook(ctx, rel, release.HookPreInstall, owner, name, namespace); err != nil {
-			return h.failRelease(rel, fmt.Errorf("failed pre-install: %s", err))
+		if err := h.ExecHook(ctx, rel, release.HookPreInstall, owner, name, namespace, dryRun, res); err != nil {
+			return res, h.failRelease(rel, fmt.Errorf("failed pre-install: %s", err))
 		}
 
 	}
 
 	h.log.Info("Release manifests")
-	err = h.creator.CreateFromYAML(
+	manifestRes, err := h.creator.CreateFromYAML(
 		ctx,
 		[]byte(rel.Manifest),
 		h.ReleaseInstalled(name),
@@ -313,16 +475,18 @@ func (h *helmer) Run(
 		namespace,
 		nodeSelector,
 		kernelFullVersion,
-		operatingSystemMajorMinor)
+		operatingSystemMajorMinor,
+		drivers)
+	res.Add(manifestRes)
 
 	if err != nil {
-		return h.failRelease(rel, err)
+		return res, h.failRelease(rel, err)
 	}
 
 	h.log.Info("Release post-install hooks")
 	if !install.DisableHooks {
-		if err := h.ExecHook(ctx, rel, release.HookPostInstall, owner, name, namespace); err != nil {
-			return h.failRelease(rel, fmt.Errorf("failed post-install: %s", err))
+		if err := h.ExecHook(ctx, rel, release.HookPostInstall, owner, name, namespace, dryRun, res); err != nil {
+			return res, h.failRelease(rel, fmt.Errorf("failed post-install: %s", err))
 		}
 	}
 
@@ -333,10 +497,10 @@ func (h *helmer) Run(
 	}
 
 	if err := h.actionConfig.Releases.Update(rel); err != nil {
-		return err
+		return res, err
 	}
 
-	return nil
+	return res, nil
 }
 
 // hookByWeight is a sorter for hooks
@@ -351,7 +515,10 @@ func (x hookByWeight) Less(i, j int) bool {
 	return x[i].Weight < x[j].Weight
 }
 
-func (h *helmer) ExecHook(ctx context.Context, rl *release.Release, hook release.HookEvent, owner v1.Object, name string, namespace string) error {
+// ExecHook creates the objects of the hooks of rl for the event hook once, the results
+// of their creation are added to res. In dry-run mode the hooks run again on the next
+// reconciliation.
+func (h *helmer) ExecHook(ctx context.Context, rl *release.Release, hook release.HookEvent, owner v1.Object, name string, namespace string, dryRun bool, res *resource.Result) error {
 
 	obj := unstructured.Unstructured{}
 	obj.SetKind("ConfigMap")
@@ -394,7 +561,7 @@ func (h *helmer) ExecHook(ctx context.Context, rl *release.Release, hook release
 			hk.DeletePolicies = []release.HookDeletePolicy{release.HookBeforeHookCreation}
 		}
 
-		if err := h.deleteHookByPolicy(hk, release.HookBeforeHookCreation); err != nil {
+		if err := h.deleteHookByPolicy(hk, release.HookBeforeHookCreation, dryRun); err != nil {
 			return err
 		}
 
@@ -411,11 +578,13 @@ func (h *helmer) ExecHook(ctx context.Context, rl *release.Release, hook release
 		// the most appropriate value to surface.
 		hk.LastRun.Phase = release.HookPhaseUnknown
 
-		if err := h.creator.CreateFromYAML(ctx, []byte(hk.Manifest), false, owner, name, namespace, nil, "", ""); err != nil {
+		hookRes, err := h.creator.CreateFromYAML(ctx, []byte(hk.Manifest), false, owner, name, namespace, nil, "", "", nil)
+		res.Add(hookRes)
+		if err != nil {
 
 			hk.LastRun.CompletedAt = helmtime.Now()
 			hk.LastRun.Phase = release.HookPhaseFailed
-			if err := h.deleteHookByPolicy(hk, release.HookFailed); err != nil {
+			if err := h.deleteHookByPolicy(hk, release.HookFailed, dryRun); err != nil {
 				return fmt.Errorf("failed to delete hook by policy %s %s: %w", hk.Name, hk.Path, err)
 			}
 			return fmt.Errorf("hook execution failed %s %s: %w", hk.Name, hk.Path, err)
@@ -430,11 +599,17 @@ func (h *helmer) ExecHook(ctx context.Context, rl *release.Release, hook release
 	// If all hooks are successful, check the annotation of each hook to determine whether the hook should be deleted
 	// under succeeded condition. If so, then clear the corresponding resource object in each hook
 	for _, hk := range hooks {
-		if err := h.deleteHookByPolicy(hk, release.HookSucceeded); err != nil {
+		if err := h.deleteHookByPolicy(hk, release.HookSucceeded, dryRun); err != nil {
 			return err
 		}
 	}
 
+	// The hooks run again once the dry run is over
+	if dryRun {
+		h.log.Info("Hooks", string(hook), "Ready (DryRun)")
+		return nil
+	}
+
 	if err := h.kubeClient.Create(ctx, &obj); err != nil {
 		h.log.Error(err, "Could not create the ConfigMap")
 
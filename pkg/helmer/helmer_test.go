@@ -1,23 +1,52 @@
 package helmer_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
 	"github.com/openshift-psap/special-resource-operator/pkg/helmer"
 	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/pkg/resource"
+	"golang.org/x/crypto/openpgp"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/provenance"
 	"helm.sh/helm/v3/pkg/repo"
 	v1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const pluginsDir = "../../helm-plugins"
@@ -94,7 +123,7 @@ var _ = Describe("helmer_Load", func() {
 
 			settings.PluginsDirectory = pluginsDir
 
-			_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(spec)
+			_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec, "ns")
 			Expect(err).To(HaveOccurred())
 		})
 
@@ -117,7 +146,7 @@ var _ = Describe("helmer_Load", func() {
 			settings.RepositoryConfig = repoConfigFile
 			settings.RepositoryCache = filepath.Join(tempDir, "cache")
 
-			_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(spec)
+			_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec, "ns")
 			Expect(err).To(HaveOccurred())
 		})
 
@@ -140,7 +169,7 @@ var _ = Describe("helmer_Load", func() {
 			settings.RepositoryConfig = repoConfigFile
 			settings.RepositoryCache = filepath.Join(tempDir, "cache")
 
-			chart, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(spec)
+			chart, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec, "ns")
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(chart.Name()).To(Equal("test-chart"))
@@ -149,6 +178,705 @@ var _ = Describe("helmer_Load", func() {
 	})
 })
 
+var _ = Describe("helmer_Load_credentials", func() {
+	const (
+		username = "user"
+		password = "secret"
+	)
+
+	var (
+		server         *httptest.Server
+		settings       *cli.EnvSettings
+		repoConfigFile string
+	)
+
+	BeforeEach(func() {
+		files := http.FileServer(http.Dir("testdata"))
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			files.ServeHTTP(w, r)
+		}))
+
+		tempDir := GinkgoT().TempDir()
+		repoConfigFile = filepath.Join(tempDir, "config.yaml")
+
+		settings = cli.New()
+		settings.PluginsDirectory = pluginsDir
+		settings.RepositoryConfig = repoConfigFile
+		settings.RepositoryCache = filepath.Join(tempDir, "cache")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	spec := helmerv1beta1.HelmChart{
+		Name:    "test-chart",
+		Version: "0.1.0",
+		Repository: helmerv1beta1.HelmRepo{
+			Name:           "test",
+			SecretRef:      &helmerv1beta1.HelmSecretRef{Name: "repo-credentials", Namespace: "ns"},
+			CAConfigMapRef: &helmerv1beta1.HelmConfigMapRef{Name: "repo-ca", Namespace: "ns"},
+		},
+	}
+
+	expectSecret := func(password string) {
+		mockKubeClient.
+			EXPECT().
+			GetSecret(context.TODO(), "ns", "repo-credentials", gomock.Any()).
+			Return(&v1.Secret{Data: map[string][]byte{
+				helmer.SecretUsernameKey: []byte(username),
+				helmer.SecretPasswordKey: []byte(password),
+			}}, nil)
+	}
+
+	expectCA := func() {
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		mockKubeClient.
+			EXPECT().
+			Get(context.TODO(), k8stypes.NamespacedName{Namespace: "ns", Name: "repo-ca"}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ k8stypes.NamespacedName, obj client.Object) error {
+				obj.(*v1.ConfigMap).Data = map[string]string{helmer.DefaultCAKey: string(ca)}
+				return nil
+			})
+	}
+
+	It("should load the chart with the credentials and CA of the Secret and ConfigMap", func() {
+		expectSecret(password)
+		expectCA()
+
+		s := spec
+		s.Repository.URL = server.URL
+
+		loaded, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "ns")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Name()).To(Equal("test-chart"))
+
+		contents, err := os.ReadFile(repoConfigFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).NotTo(ContainSubstring(password))
+		Expect(string(contents)).To(ContainSubstring(`caFile: ""`))
+	})
+
+	It("should pick up rotated credentials", func() {
+		h := helmer.NewHelmer(mockCreator, settings, mockKubeClient)
+
+		s := spec
+		s.Repository.URL = server.URL
+
+		expectSecret("outdated")
+		expectCA()
+
+		_, err := h.Load(context.TODO(), s, "ns")
+		Expect(err).To(HaveOccurred())
+
+		expectSecret(password)
+		expectCA()
+
+		_, err = h.Load(context.TODO(), s, "ns")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should fail if the CA ConfigMap does not have the key", func() {
+		expectSecret(password)
+
+		mockKubeClient.
+			EXPECT().
+			Get(context.TODO(), k8stypes.NamespacedName{Namespace: "ns", Name: "repo-ca"}, gomock.Any())
+
+		s := spec
+		s.Repository.URL = server.URL
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "ns")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should refuse references outside of the operator's namespace and the SpecialResource's", func() {
+		s := spec
+		s.Repository.URL = server.URL
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "other")
+		Expect(err).To(MatchError(ContainSubstring("Secret ns/repo-credentials")))
+
+		os.Setenv("OPERATOR_NAMESPACE", "ns")
+		defer os.Unsetenv("OPERATOR_NAMESPACE")
+
+		expectSecret(password)
+		expectCA()
+
+		_, err = helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "other")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should redact inline credentials", func() {
+		r := helmerv1beta1.HelmRepo{Name: "test", Username: username, Password: password}
+
+		helmer.Redact(&r)
+
+		Expect(r.Name).To(Equal("test"))
+		Expect(r.Username).To(Equal("REDACTED"))
+		Expect(r.Password).To(Equal("REDACTED"))
+	})
+})
+
+var _ = Describe("helmer_Load_git", func() {
+	var (
+		bare     string
+		work     string
+		settings *cli.EnvSettings
+
+		restoreProtocols func()
+	)
+
+	run := func(dir string, args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	// commit copies the test chart with the given version into the work tree and pushes it
+	commit := func(version string) string {
+		dst := filepath.Join(work, "charts", "test-chart")
+		Expect(os.MkdirAll(filepath.Join(dst, "templates"), 0755)).To(Succeed())
+
+		for _, f := range []string{"values.yaml", "templates/service.yaml", "templates/_helpers.tpl"} {
+			data, err := os.ReadFile(filepath.Join("testdata/test-chart", f))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(dst, f), data, 0644)).To(Succeed())
+		}
+
+		chartYAML := "apiVersion: v2\nname: test-chart\ntype: application\nversion: " + version + "\n"
+		Expect(os.WriteFile(filepath.Join(dst, "Chart.yaml"), []byte(chartYAML), 0644)).To(Succeed())
+
+		run(work, "add", "-A")
+		run(work, "commit", "--quiet", "-m", version)
+		run(work, "push", "--quiet", "origin", "HEAD:main")
+
+		return run(work, "rev-parse", "HEAD")
+	}
+
+	BeforeEach(func() {
+		tempDir := GinkgoT().TempDir()
+		bare = filepath.Join(tempDir, "charts.git")
+		work = filepath.Join(tempDir, "work")
+
+		run(tempDir, "init", "--quiet", "--bare", "--initial-branch=main", bare)
+		run(tempDir, "init", "--quiet", "--initial-branch=main", work)
+		run(work, "remote", "add", "origin", bare)
+
+		settings = cli.New()
+		settings.PluginsDirectory = pluginsDir
+		settings.RepositoryCache = filepath.Join(tempDir, "cache")
+
+		restoreProtocols = helmer.AllowGitFileProtocol()
+		DeferCleanup(restoreProtocols)
+	})
+
+	spec := func(ref string) helmerv1beta1.HelmChart {
+		return helmerv1beta1.HelmChart{
+			Name: "test-chart",
+			Git: &helmerv1beta1.HelmGit{
+				Uri:  "file://" + bare,
+				Ref:  ref,
+				Path: "charts/test-chart",
+			},
+		}
+	}
+
+	It("should load the chart of a branch and pick up new commits", func() {
+		first := commit("0.1.0")
+
+		h := helmer.NewHelmer(mockCreator, settings, mockKubeClient)
+
+		loaded, err := h.Load(context.TODO(), spec("main"), "ns")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Name()).To(Equal("test-chart"))
+		Expect(loaded.Metadata.Version).To(Equal("0.1.0"))
+		Expect(loaded.Metadata.Annotations).To(HaveKeyWithValue(helmer.GitCommitAnnotation, first))
+		Expect(loaded.Templates).To(HaveLen(2))
+
+		second := commit("0.2.0")
+
+		loaded, err = h.Load(context.TODO(), spec("main"), "ns")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Metadata.Version).To(Equal("0.2.0"))
+		Expect(loaded.Metadata.Annotations).To(HaveKeyWithValue(helmer.GitCommitAnnotation, second))
+	})
+
+	It("should load a pinned commit from the cache", func() {
+		first := commit("0.1.0")
+		commit("0.2.0")
+
+		h := helmer.NewHelmer(mockCreator, settings, mockKubeClient)
+
+		loaded, err := h.Load(context.TODO(), spec(first), "ns")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Metadata.Version).To(Equal("0.1.0"))
+
+		Expect(os.RemoveAll(bare)).To(Succeed())
+
+		loaded, err = h.Load(context.TODO(), spec(first), "ns")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Metadata.Version).To(Equal("0.1.0"))
+
+		_, err = h.Load(context.TODO(), spec("main"), "ns")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should remove the cached repositories no chart was loaded from", func() {
+		commit("0.1.0")
+
+		h := helmer.NewHelmer(mockCreator, settings, mockKubeClient)
+
+		_, err := h.Load(context.TODO(), spec("main"), "ns")
+		Expect(err).NotTo(HaveOccurred())
+
+		cached, err := filepath.Glob(filepath.Join(settings.RepositoryCache, "git", "*"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cached).To(HaveLen(1))
+
+		old := time.Now().Add(-2 * helmer.GitCacheTTL)
+		Expect(os.Chtimes(cached[0], old, old)).To(Succeed())
+
+		// Another URI of the same repository has its own cache
+		s := spec("main")
+		s.Git.Uri += "/"
+
+		_, err = h.Load(context.TODO(), s, "ns")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cached[0]).NotTo(BeADirectory())
+		Expect(filepath.Glob(filepath.Join(settings.RepositoryCache, "git", "*"))).To(HaveLen(1))
+	})
+
+	It("should fail if there is no chart in the path", func() {
+		commit("0.1.0")
+
+		s := spec("main")
+		s.Git.Path = "charts/invalid"
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "ns")
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("should reject URIs and refs git could take for options or other transports",
+		func(uri, ref string) {
+			restoreProtocols()
+
+			pwn := filepath.Join(GinkgoT().TempDir(), "pwn")
+
+			s := spec(ref)
+			s.Git.Uri = strings.ReplaceAll(uri, "PWN", pwn)
+
+			_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "ns")
+			Expect(err).To(MatchError(ContainSubstring("invalid git")))
+			Expect(pwn).NotTo(BeAnExistingFile())
+		},
+		Entry("upload-pack option", "--upload-pack=touch PWN", "main"),
+		Entry("ext transport", "ext::touch PWN", "main"),
+		Entry("http scheme", "http://example.com/charts.git", "main"),
+		Entry("file scheme", "file://PWN", "main"),
+		Entry("option ref", "https://example.com/charts.git", "--upload-pack=touch"),
+	)
+
+	It("should accept scp-like SSH URIs", func() {
+		s := spec("main")
+		s.Git.Uri = "git@invalid.example.com:charts.git"
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "ns")
+		Expect(err).To(HaveOccurred())
+		Expect(err).NotTo(MatchError(ContainSubstring("invalid git")))
+	})
+
+	It("should fail if the credentials Secret cannot be read", func() {
+		s := spec("main")
+		s.Git.SecretRef = &helmerv1beta1.HelmSecretRef{Name: "git-credentials", Namespace: "ns"}
+
+		mockKubeClient.
+			EXPECT().
+			GetSecret(context.TODO(), "ns", "git-credentials", gomock.Any()).
+			Return(nil, errors.New("random error"))
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "ns")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// newKeyring returns a signer for charts and the public keyring verifying its signatures.
+func newKeyring(dir string) (*provenance.Signatory, []byte) {
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	Expect(err).NotTo(HaveOccurred())
+
+	var private, public bytes.Buffer
+	Expect(entity.SerializePrivate(&private, nil)).To(Succeed())
+	Expect(entity.Serialize(&public)).To(Succeed())
+
+	privatePath := filepath.Join(dir, "secring.gpg")
+	Expect(os.WriteFile(privatePath, private.Bytes(), 0600)).To(Succeed())
+
+	signer, err := provenance.NewFromKeyring(privatePath, "test")
+	Expect(err).NotTo(HaveOccurred())
+
+	return signer, public.Bytes()
+}
+
+var _ = Describe("helmer_Load_verify", func() {
+	var (
+		server   *httptest.Server
+		settings *cli.EnvSettings
+		repoDir  string
+		signer   *provenance.Signatory
+		keyring  []byte
+	)
+
+	BeforeEach(func() {
+		tempDir := GinkgoT().TempDir()
+		repoDir = filepath.Join(tempDir, "repo")
+		Expect(os.MkdirAll(repoDir, 0755)).To(Succeed())
+
+		for _, f := range []string{"index.yaml", "test-chart-0.1.0.tgz"} {
+			data, err := os.ReadFile(filepath.Join("testdata", f))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(repoDir, f), data, 0644)).To(Succeed())
+		}
+
+		signer, keyring = newKeyring(tempDir)
+
+		server = httptest.NewServer(http.FileServer(http.Dir(repoDir)))
+
+		settings = cli.New()
+		settings.PluginsDirectory = pluginsDir
+		settings.RepositoryConfig = filepath.Join(tempDir, "config.yaml")
+		settings.RepositoryCache = filepath.Join(tempDir, "cache")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	sign := func() {
+		prov, err := signer.ClearSign(filepath.Join(repoDir, "test-chart-0.1.0.tgz"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(repoDir, "test-chart-0.1.0.tgz.prov"), []byte(prov), 0644)).To(Succeed())
+	}
+
+	expectKeyring := func(keyring []byte) {
+		mockKubeClient.
+			EXPECT().
+			GetSecret(context.TODO(), "ns", "chart-keys", gomock.Any()).
+			Return(&v1.Secret{Data: map[string][]byte{helmer.KeyringKey: keyring}}, nil)
+	}
+
+	spec := func() helmerv1beta1.HelmChart {
+		return helmerv1beta1.HelmChart{
+			Name:    "test-chart",
+			Version: "0.1.0",
+			Repository: helmerv1beta1.HelmRepo{
+				Name: "test",
+				URL:  server.URL,
+			},
+			Verify: &helmerv1beta1.HelmVerify{
+				SecretRef: helmerv1beta1.HelmSecretRef{Name: "chart-keys", Namespace: "ns"},
+			},
+		}
+	}
+
+	It("should load a chart signed by a key of the keyring", func() {
+		sign()
+		expectKeyring(keyring)
+
+		loaded, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec(), "ns")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Name()).To(Equal("test-chart"))
+	})
+
+	It("should refuse a chart signed by another key", func() {
+		sign()
+		_, other := newKeyring(GinkgoT().TempDir())
+		expectKeyring(other)
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec(), "ns")
+		Expect(errors.Is(err, helmer.ErrVerification)).To(BeTrue())
+	})
+
+	It("should refuse a chart without provenance", func() {
+		expectKeyring(keyring)
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec(), "ns")
+		Expect(errors.Is(err, helmer.ErrVerification)).To(BeTrue())
+	})
+
+	It("should refuse cosign for charts of a Helm repository", func() {
+		s := spec()
+		s.Verify.Provider = helmer.VerifyProviderCosign
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "ns")
+		Expect(errors.Is(err, helmer.ErrVerification)).To(BeTrue())
+	})
+})
+
+var _ = Describe("helmer_Load_OCI", func() {
+	const (
+		username = "user"
+		password = "secret"
+	)
+
+	var (
+		server   *httptest.Server
+		settings *cli.EnvSettings
+		digest   string
+		host     string
+	)
+
+	BeforeEach(func() {
+		handler := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+
+		// Require basic auth, like a private chart catalog
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+				w.Header().Set("WWW-Authenticate", `Basic realm="charts"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		}))
+
+		u, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		host = u.Host
+
+		layer, err := tarball.LayerFromFile("testdata/test-chart-0.1.0.tgz")
+		Expect(err).NotTo(HaveOccurred())
+
+		img, err := mutate.Append(empty.Image, mutate.Addendum{
+			Layer:     layer,
+			MediaType: "application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		img = mutate.MediaType(img, types.OCIManifestSchema1)
+
+		ref, err := name.NewTag(host + "/charts/test-chart:0.1.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remote.Write(ref, img, remote.WithAuth(&authn.Basic{Username: username, Password: password}))).To(Succeed())
+
+		d, err := img.Digest()
+		Expect(err).NotTo(HaveOccurred())
+		digest = d.String()
+
+		settings = cli.New()
+		settings.PluginsDirectory = pluginsDir
+		settings.RepositoryCache = GinkgoT().TempDir()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	spec := func(version, digest string) helmerv1beta1.HelmChart {
+		return helmerv1beta1.HelmChart{
+			Name:    "test-chart",
+			Version: version,
+			Digest:  digest,
+			Repository: helmerv1beta1.HelmRepo{
+				Name:      "charts",
+				URL:       "oci://" + host + "/charts",
+				SecretRef: &helmerv1beta1.HelmSecretRef{Name: "chart-credentials", Namespace: "ns"},
+			},
+		}
+	}
+
+	credentials := func() {
+		mockKubeClient.
+			EXPECT().
+			GetSecret(context.TODO(), "ns", "chart-credentials", gomock.Any()).
+			Return(&v1.Secret{Data: map[string][]byte{
+				"username": []byte(username),
+				"password": []byte(password),
+			}}, nil)
+	}
+
+	It("should pull the chart with the credentials of the Secret and cache it", func() {
+		credentials()
+
+		h := helmer.NewHelmer(mockCreator, settings, mockKubeClient)
+
+		loaded, err := h.Load(context.TODO(), spec("0.1.0", ""), "ns")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Name()).To(Equal("test-chart"))
+		Expect(loaded.Metadata.Version).To(Equal("0.1.0"))
+
+		// Pinned to the digest the chart is loaded from the cache
+		server.Close()
+
+		loaded, err = h.Load(context.TODO(), spec("", digest), "ns")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Name()).To(Equal("test-chart"))
+	})
+
+	It("should pull the chart pinned to its digest", func() {
+		credentials()
+
+		loaded, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec("", digest), "ns")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Name()).To(Equal("test-chart"))
+	})
+
+	It("should fail with a dockerconfigjson Secret for another registry", func() {
+		mockKubeClient.
+			EXPECT().
+			GetSecret(context.TODO(), "ns", "chart-credentials", gomock.Any()).
+			Return(&v1.Secret{
+				Type: v1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					v1.DockerConfigJsonKey: []byte(`{"auths":{"quay.io":{"auth":"dXNlcjpzZWNyZXQ="}}}`),
+				},
+			}, nil)
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec("0.1.0", ""), "ns")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should only reach the registry over HTTP with plainHTTP", func() {
+		chart := spec("0.1.0", "")
+		chart.Repository.URL = "oci://registry.example.com/charts"
+		chart.Repository.InsecureSkipTLSverify = true
+
+		ref, err := helmer.OCIReference(chart)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref.Context().Scheme()).To(Equal("https"))
+
+		chart.Repository.PlainHTTP = true
+
+		ref, err = helmer.OCIReference(chart)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref.Context().Scheme()).To(Equal("http"))
+	})
+
+	It("should use the matching dockerconfigjson entry", func() {
+		mockKubeClient.
+			EXPECT().
+			GetSecret(context.TODO(), "ns", "chart-credentials", gomock.Any()).
+			Return(&v1.Secret{
+				Type: v1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					v1.DockerConfigJsonKey: []byte(`{"auths":{"` + host + `":{"auth":"dXNlcjpzZWNyZXQ="}}}`),
+				},
+			}, nil)
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec("0.1.0", ""), "ns")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("cosign", func() {
+		var key *ecdsa.PrivateKey
+
+		BeforeEach(func() {
+			var err error
+			key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		// pushSignature pushes the cosign signature of the chart's digest signed by signer
+		pushSignature := func(signer *ecdsa.PrivateKey) {
+			payload := []byte(`{"critical":{"identity":{"docker-reference":"` + host + `/charts/test-chart"},` +
+				`"image":{"docker-manifest-digest":"` + digest + `"},"type":"cosign container image signature"},"optional":null}`)
+
+			sum := sha256.Sum256(payload)
+			sig, err := ecdsa.SignASN1(rand.Reader, signer, sum[:])
+			Expect(err).NotTo(HaveOccurred())
+
+			img, err := mutate.Append(empty.Image, mutate.Addendum{
+				Layer:       rawLayer(payload),
+				MediaType:   "application/vnd.dev.cosign.simplesigning.v1+json",
+				Annotations: map[string]string{"dev.cosignproject.cosign/signature": base64.StdEncoding.EncodeToString(sig)},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			ref, err := name.NewTag(host + "/charts/test-chart:" + strings.Replace(digest, ":", "-", 1) + ".sig")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remote.Write(ref, img, remote.WithAuth(&authn.Basic{Username: username, Password: password}))).To(Succeed())
+		}
+
+		expectKey := func() {
+			der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			mockKubeClient.
+				EXPECT().
+				GetSecret(context.TODO(), "ns", "chart-keys", gomock.Any()).
+				Return(&v1.Secret{Data: map[string][]byte{
+					helmer.CosignKeyKey: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+				}}, nil)
+		}
+
+		verified := func() helmerv1beta1.HelmChart {
+			s := spec("0.1.0", "")
+			s.Verify = &helmerv1beta1.HelmVerify{
+				Provider:  helmer.VerifyProviderCosign,
+				SecretRef: helmerv1beta1.HelmSecretRef{Name: "chart-keys", Namespace: "ns"},
+			}
+			return s
+		}
+
+		It("should load a chart signed with the key", func() {
+			pushSignature(key)
+			credentials()
+			expectKey()
+
+			loaded, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), verified(), "ns")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.Name()).To(Equal("test-chart"))
+		})
+
+		It("should refuse a chart signed with another key", func() {
+			other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+
+			pushSignature(other)
+			credentials()
+			expectKey()
+
+			_, err = helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), verified(), "ns")
+			Expect(errors.Is(err, helmer.ErrVerification)).To(BeTrue())
+		})
+
+		It("should refuse an unsigned chart", func() {
+			credentials()
+			expectKey()
+
+			_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), verified(), "ns")
+			Expect(errors.Is(err, helmer.ErrVerification)).To(BeTrue())
+		})
+	})
+
+	It("should refuse a chart without provenance layer", func() {
+		credentials()
+
+		mockKubeClient.
+			EXPECT().
+			GetSecret(context.TODO(), "ns", "chart-keys", gomock.Any()).
+			Return(&v1.Secret{Data: map[string][]byte{helmer.KeyringKey: []byte("keyring")}}, nil)
+
+		s := spec("0.1.0", "")
+		s.Verify = &helmerv1beta1.HelmVerify{SecretRef: helmerv1beta1.HelmSecretRef{Name: "chart-keys", Namespace: "ns"}}
+
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), s, "ns")
+		Expect(errors.Is(err, helmer.ErrVerification)).To(BeTrue())
+	})
+
+	It("should fail without version nor digest", func() {
+		_, err := helmer.NewHelmer(mockCreator, settings, mockKubeClient).Load(context.TODO(), spec("", ""), "ns")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
 var _ = Describe("helmer_InstallCRDs", func() {
 	const (
 		name      = "some-name"
@@ -162,10 +890,10 @@ var _ = Describe("helmer_InstallCRDs", func() {
 
 		mockCreator.
 			EXPECT().
-			CreateFromYAML(context.TODO(), nil, false, owner, name, namespace, nil, "", "").
-			Return(randomError)
+			CreateFromYAML(context.TODO(), nil, false, owner, name, namespace, nil, "", "", nil).
+			Return(nil, randomError)
 
-		err := helmer.NewHelmer(mockCreator, cli.New(), mockKubeClient).InstallCRDs(context.TODO(), nil, owner, name, namespace)
+		_, err := helmer.NewHelmer(mockCreator, cli.New(), mockKubeClient).InstallCRDs(context.TODO(), nil, owner, name, namespace)
 		Expect(err).To(Equal(randomError))
 	})
 
@@ -191,9 +919,9 @@ def
 
 		mockCreator.
 			EXPECT().
-			CreateFromYAML(context.TODO(), manifests, false, owner, name, namespace, nil, "", "")
+			CreateFromYAML(context.TODO(), manifests, false, owner, name, namespace, nil, "", "", nil)
 
-		err := helmer.NewHelmer(mockCreator, cli.New(), mockKubeClient).InstallCRDs(context.TODO(), crds, owner, name, namespace)
+		_, err := helmer.NewHelmer(mockCreator, cli.New(), mockKubeClient).InstallCRDs(context.TODO(), crds, owner, name, namespace)
 		Expect(err).NotTo(HaveOccurred())
 	})
 })
@@ -214,9 +942,9 @@ var _ = Describe("helmer_Run", func() {
 			},
 		}
 
-		err := helmer.
+		_, err := helmer.
 			NewHelmer(mockCreator, cli.New(), mockKubeClient).
-			Run(context.TODO(), ch, nil, owner, name, namespace, nil, "", "", false)
+			Run(context.TODO(), ch, nil, owner, name, namespace, nil, "", "", false, false, nil, nil)
 		Expect(err).To(HaveOccurred())
 	})
 
@@ -238,12 +966,27 @@ var _ = Describe("helmer_Run", func() {
 
 		mockCreator.
 			EXPECT().
-			CreateFromYAML(context.TODO(), gomock.Any(), false, owner, name, namespace, nil, "", "").
-			Return(randomError)
+			CreateFromYAML(context.TODO(), gomock.Any(), false, owner, name, namespace, nil, "", "", nil).
+			Return(nil, randomError)
 
-		err := helmer.
+		_, err := helmer.
 			NewHelmer(mockCreator, cli.New(), mockKubeClient).
-			Run(context.TODO(), ch, nil, owner, name, namespace, nil, "", "", false)
+			Run(context.TODO(), ch, nil, owner, name, namespace, nil, "", "", false, false, nil, nil)
 		Expect(errors.Is(err, randomError)).To(BeTrue())
 	})
 })
+
+// rawLayer is a layer stored as is, unlike tarball layers which are compressed.
+type rawLayer []byte
+
+func (l rawLayer) Digest() (ggcrv1.Hash, error) {
+	h, _, err := ggcrv1.SHA256(bytes.NewReader(l))
+	return h, err
+}
+func (l rawLayer) DiffID() (ggcrv1.Hash, error)         { return l.Digest() }
+func (l rawLayer) Compressed() (io.ReadCloser, error)   { return io.NopCloser(bytes.NewReader(l)), nil }
+func (l rawLayer) Uncompressed() (io.ReadCloser, error) { return l.Compressed() }
+func (l rawLayer) Size() (int64, error)                 { return int64(len(l)), nil }
+func (l rawLayer) MediaType() (types.MediaType, error) {
+	return "application/vnd.dev.cosign.simplesigning.v1+json", nil
+}
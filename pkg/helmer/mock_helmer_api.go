@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: helmer.go
+
+// Package helmer is a generated GoMock package.
+package helmer
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	v1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
+	resource "github.com/openshift-psap/special-resource-operator/pkg/resource"
+	chart "helm.sh/helm/v3/pkg/chart"
+	postrender "helm.sh/helm/v3/pkg/postrender"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MockHelmer is a mock of Helmer interface.
+type MockHelmer struct {
+	ctrl     *gomock.Controller
+	recorder *MockHelmerMockRecorder
+}
+
+// MockHelmerMockRecorder is the mock recorder for MockHelmer.
+type MockHelmerMockRecorder struct {
+	mock *MockHelmer
+}
+
+// NewMockHelmer creates a new mock instance.
+func NewMockHelmer(ctrl *gomock.Controller) *MockHelmer {
+	mock := &MockHelmer{ctrl: ctrl}
+	mock.recorder = &MockHelmerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHelmer) EXPECT() *MockHelmerMockRecorder {
+	return m.recorder
+}
+
+// Load mocks base method.
+func (m *MockHelmer) Load(arg0 context.Context, arg1 v1beta1.HelmChart, arg2 string) (*chart.Chart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*chart.Chart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockHelmerMockRecorder) Load(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockHelmer)(nil).Load), arg0, arg1, arg2)
+}
+
+// Run mocks base method.
+func (m *MockHelmer) Run(arg0 context.Context, arg1 chart.Chart, arg2 map[string]interface{}, arg3 v1.Object, arg4, arg5 string, arg6 map[string]string, arg7, arg8 string, arg9, arg10 bool, arg11 postrender.PostRenderer, arg12 *resource.Drivers) (*resource.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12)
+	ret0, _ := ret[0].(*resource.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockHelmerMockRecorder) Run(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockHelmer)(nil).Run), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12)
+}
+
+// Template mocks base method.
+func (m *MockHelmer) Template(arg0 chart.Chart, arg1 map[string]interface{}, arg2 string, arg3 postrender.PostRenderer) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Template", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Template indicates an expected call of Template.
+func (mr *MockHelmerMockRecorder) Template(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Template", reflect.TypeOf((*MockHelmer)(nil).Template), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,216 @@
+package helmer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+const (
+	ociScheme = "oci://"
+
+	// Media types of the chart layer, Helm < 3.7 pushed charts as application/tar+gzip
+	chartLayerMediaType       = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	legacyChartLayerMediaType = "application/tar+gzip"
+)
+
+// IsOCI returns true if the repository is an OCI registry.
+func IsOCI(url string) bool {
+	return strings.HasPrefix(url, ociScheme)
+}
+
+// ociReference returns the reference of the chart in the registry, pinned to
+// the digest if there is one. The registry is only reached over HTTP with
+// PlainHTTP, skipping the TLS verification is configured on the transport.
+func ociReference(spec helmerv1beta1.HelmChart) (name.Reference, error) {
+
+	var opts []name.Option
+	if spec.Repository.PlainHTTP {
+		opts = append(opts, name.Insecure)
+	}
+
+	repository := strings.TrimSuffix(strings.TrimPrefix(spec.Repository.URL, ociScheme), "/") + "/" + spec.Name
+
+	if spec.Digest != "" {
+		return name.NewDigest(repository+"@"+spec.Digest, opts...)
+	}
+
+	if spec.Version == "" {
+		return nil, fmt.Errorf("version or digest is required for OCI chart %s", repository)
+	}
+
+	// Helm replaces + by _ since + is not allowed in OCI tags
+	return name.NewTag(repository+":"+strings.ReplaceAll(spec.Version, "+", "_"), opts...)
+}
+
+func (h *helmer) ociRemoteOptions(ctx context.Context, spec helmerv1beta1.HelmRepo, registry string) ([]remote.Option, error) {
+
+	opts := []remote.Option{remote.WithContext(ctx)}
+
+	auth, err := h.repoAuth(ctx, spec, registry)
+	if err != nil {
+		return nil, err
+	}
+
+	if auth.username != "" || auth.password != "" {
+		opts = append(opts, remote.WithAuth(&authn.Basic{Username: auth.username, Password: auth.password}))
+	} else {
+		opts = append(opts, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	}
+
+	config, err := auth.tlsConfig(spec.InsecureSkipTLSverify)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure TLS for repository %s: %w", spec.Name, err)
+	}
+
+	if config != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config
+		opts = append(opts, remote.WithTransport(transport))
+	}
+
+	return opts, nil
+}
+
+// loadOCI pulls the chart from an OCI registry. Pulled charts are cached by the
+// digest of their manifest, a chart pinned to a digest that is already in the
+// cache is loaded without contacting the registry unless it has to be verified.
+func (h *helmer) loadOCI(ctx context.Context, spec helmerv1beta1.HelmChart) (*chart.Chart, error) {
+
+	ref, err := ociReference(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDir := filepath.Join(h.settings.RepositoryCache, "oci")
+
+	if spec.Digest != "" && spec.Verify == nil {
+		if data, err := os.ReadFile(cachedOCIPath(cacheDir, spec.Digest)); err == nil {
+			if loaded, err := loader.LoadArchive(bytes.NewReader(data)); err == nil {
+				h.log.Info("Loaded chart from cache", "chart", ref.String())
+				return loaded, nil
+			}
+		}
+	}
+
+	opts, err := h.ociRemoteOptions(ctx, spec.Repository, ref.Context().RegistryStr())
+	if err != nil {
+		return nil, err
+	}
+
+	h.log.Info("Pulling", "chart", ref.String())
+
+	desc, err := remote.Get(ref, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get manifest of chart %s: %w", ref.String(), err)
+	}
+
+	if spec.Digest != "" && desc.Digest.String() != spec.Digest {
+		return nil, fmt.Errorf("chart %s has digest %s, expected %s", ref.String(), desc.Digest.String(), spec.Digest)
+	}
+
+	manifest, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse manifest of chart %s: %w", ref.String(), err)
+	}
+
+	data, err := os.ReadFile(cachedOCIPath(cacheDir, desc.Digest.String()))
+	cached := err == nil
+
+	if cached {
+		h.log.Info("Loaded chart from cache", "chart", ref.String(), "digest", desc.Digest.String())
+	} else if data, err = pullChartLayer(ref, manifest, opts); err != nil {
+		return nil, err
+	}
+
+	loaded, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load chart %s: %w", ref.String(), err)
+	}
+
+	if spec.Verify != nil {
+		if err = h.verifyOCIChart(ctx, spec, ref, desc, manifest, loaded, data, opts); err != nil {
+			return nil, err
+		}
+		h.log.Info("Verified", "chart", ref.String(), "provider", verifyProvider(spec.Verify))
+	}
+
+	if !cached {
+		if err = writeCachedOCI(cacheDir, desc.Digest.String(), data); err != nil {
+			h.log.Info("Could not cache chart", "chart", ref.String(), "error", err.Error())
+		}
+		h.log.Info("Pulled", "chart", ref.String(), "digest", desc.Digest.String())
+	}
+
+	return loaded, nil
+}
+
+// pullChartLayer returns the content of the chart layer of the manifest.
+func pullChartLayer(ref name.Reference, manifest *v1.Manifest, opts []remote.Option) ([]byte, error) {
+
+	var layer *v1.Descriptor
+	for i, l := range manifest.Layers {
+		if l.MediaType == chartLayerMediaType || l.MediaType == legacyChartLayerMediaType {
+			layer = &manifest.Layers[i]
+			break
+		}
+	}
+
+	if layer == nil {
+		return nil, fmt.Errorf("%s is not a Helm chart, no layer of type %s", ref.String(), chartLayerMediaType)
+	}
+
+	blob, err := remote.Layer(ref.Context().Digest(layer.Digest.String()), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get chart layer of %s: %w", ref.String(), err)
+	}
+
+	// The content of the layer is verified against its digest while reading
+	data, err := readLayer(blob)
+	if err != nil {
+		return nil, fmt.Errorf("cannot pull chart %s: %w", ref.String(), err)
+	}
+
+	return data, nil
+}
+
+func cachedOCIPath(cacheDir, digest string) string {
+	return filepath.Join(cacheDir, strings.ReplaceAll(digest, ":", "-")+".tgz")
+}
+
+func writeCachedOCI(cacheDir, digest string, data []byte) error {
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a partially written chart is never loaded
+	tmp, err := os.CreateTemp(cacheDir, "chart-*.tgz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), cachedOCIPath(cacheDir, digest))
+}
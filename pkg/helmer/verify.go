@@ -0,0 +1,329 @@
+package helmer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	helmerv1beta1 "github.com/openshift-psap/special-resource-operator/pkg/helmer/api/v1beta1"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	VerifyProviderHelm   = "helm"
+	VerifyProviderCosign = "cosign"
+
+	// Keys of the Secret referenced by the verification policy
+	KeyringKey   = "keyring.gpg"
+	CosignKeyKey = "cosign.pub"
+
+	provenanceLayerMediaType = "application/vnd.cncf.helm.chart.provenance.v1.prov"
+	cosignSignatureAnno      = "dev.cosignproject.cosign/signature"
+)
+
+// ErrVerification is wrapped by the errors of charts failing verification, the
+// chart must not be installed.
+var ErrVerification = errors.New("chart verification failed")
+
+func verificationError(format string, a ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrVerification, fmt.Sprintf(format, a...))
+}
+
+func verifyProvider(v *helmerv1beta1.HelmVerify) string {
+	if v.Provider == "" {
+		return VerifyProviderHelm
+	}
+	return v.Provider
+}
+
+// verificationKey returns the keyring or public key of the verification policy.
+func (h *helmer) verificationKey(ctx context.Context, v *helmerv1beta1.HelmVerify) ([]byte, error) {
+
+	key := KeyringKey
+	if verifyProvider(v) == VerifyProviderCosign {
+		key = CosignKeyKey
+	}
+
+	namespace := refNamespace(v.SecretRef.Namespace)
+
+	secret, err := h.kubeClient.GetSecret(ctx, namespace, v.SecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get verification Secret %s/%s: %w", namespace, v.SecretRef.Name, err)
+	}
+
+	data, found := secret.Data[key]
+	if !found {
+		return nil, fmt.Errorf("verification Secret %s/%s has no key %s", namespace, v.SecretRef.Name, key)
+	}
+
+	return data, nil
+}
+
+// verifyProvenance verifies the chart archive at chartPath against its .prov
+// file signed by a key of the keyring.
+func verifyProvenance(chartPath string, prov, keyring []byte) error {
+
+	dir, err := os.MkdirTemp("", "helm-verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	keyringPath := filepath.Join(dir, KeyringKey)
+	provPath := filepath.Join(dir, filepath.Base(chartPath)+".prov")
+
+	if err = os.WriteFile(keyringPath, keyring, 0600); err != nil {
+		return err
+	}
+	if err = os.WriteFile(provPath, prov, 0600); err != nil {
+		return err
+	}
+
+	sig, err := provenance.NewFromKeyring(keyringPath, "")
+	if err != nil {
+		return fmt.Errorf("cannot load keyring: %w", err)
+	}
+
+	if _, err = sig.Verify(chartPath, provPath); err != nil {
+		return verificationError("%s: %v", filepath.Base(chartPath), err)
+	}
+
+	return nil
+}
+
+// verifyRepoChart verifies a chart downloaded from a Helm repository to
+// chartPath, the .prov file is downloaded next to the chart's URL.
+func (h *helmer) verifyRepoChart(ctx context.Context, spec helmerv1beta1.HelmChart, entry *repo.Entry, chartPath string) error {
+
+	if verifyProvider(spec.Verify) != VerifyProviderHelm {
+		return verificationError("%s is only supported for OCI charts", verifyProvider(spec.Verify))
+	}
+
+	keyring, err := h.verificationKey(ctx, spec.Verify)
+	if err != nil {
+		return err
+	}
+
+	index, err := repo.LoadIndexFile(filepath.Join(h.settings.RepositoryCache, entry.Name+"-index.yaml"))
+	if err != nil {
+		return fmt.Errorf("cannot load index of repository %s: %w", entry.Name, err)
+	}
+
+	cv, err := index.Get(spec.Name, spec.Version)
+	if err != nil || len(cv.URLs) == 0 {
+		return fmt.Errorf("cannot find %s %s in the index of repository %s: %v", spec.Name, spec.Version, entry.Name, err)
+	}
+
+	chartURL, err := repo.ResolveReferenceURL(entry.URL, cv.URLs[0])
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(chartURL)
+	if err != nil {
+		return err
+	}
+
+	g, err := h.getterProviders.ByScheme(u.Scheme)
+	if err != nil {
+		return err
+	}
+
+	prov, err := g.Get(chartURL+".prov",
+		getter.WithURL(entry.URL),
+		getter.WithBasicAuth(entry.Username, entry.Password),
+		getter.WithTLSClientConfig(entry.CertFile, entry.KeyFile, entry.CAFile),
+		getter.WithInsecureSkipVerifyTLS(entry.InsecureSkipTLSverify))
+	if err != nil {
+		return verificationError("cannot get provenance of %s: %v", chartURL, err)
+	}
+
+	return verifyProvenance(chartPath, prov.Bytes(), keyring)
+}
+
+// verifyOCIChart verifies a chart pulled from an OCI registry, either against
+// the provenance layer of its manifest or against its cosign signature.
+func (h *helmer) verifyOCIChart(ctx context.Context, spec helmerv1beta1.HelmChart, ref name.Reference, desc *remote.Descriptor, manifest *v1.Manifest, loaded *chart.Chart, data []byte, opts []remote.Option) error {
+
+	key, err := h.verificationKey(ctx, spec.Verify)
+	if err != nil {
+		return err
+	}
+
+	if verifyProvider(spec.Verify) == VerifyProviderCosign {
+		return verifyCosign(ref, desc.Digest, key, opts)
+	}
+
+	var prov *v1.Descriptor
+	for i, l := range manifest.Layers {
+		if l.MediaType == provenanceLayerMediaType {
+			prov = &manifest.Layers[i]
+			break
+		}
+	}
+
+	if prov == nil {
+		return verificationError("%s has no provenance layer", ref.String())
+	}
+
+	blob, err := remote.Layer(ref.Context().Digest(prov.Digest.String()), opts...)
+	if err != nil {
+		return fmt.Errorf("cannot get provenance layer of %s: %w", ref.String(), err)
+	}
+
+	provData, err := readLayer(blob)
+	if err != nil {
+		return fmt.Errorf("cannot get provenance layer of %s: %w", ref.String(), err)
+	}
+
+	dir, err := os.MkdirTemp("", "helm-chart-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// The provenance holds the digest of the archive under the name helm package gives it
+	chartPath := filepath.Join(dir, loaded.Metadata.Name+"-"+loaded.Metadata.Version+".tgz")
+	if err = os.WriteFile(chartPath, data, 0600); err != nil {
+		return err
+	}
+
+	return verifyProvenance(chartPath, provData, key)
+}
+
+func readLayer(l v1.Layer) ([]byte, error) {
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+// verifyCosign verifies that the cosign signature image of the chart manifest
+// digest holds a payload for digest signed by key.
+func verifyCosign(ref name.Reference, digest v1.Hash, key []byte, opts []remote.Option) error {
+
+	pub, err := parsePublicKey(key)
+	if err != nil {
+		return err
+	}
+
+	sigRef := ref.Context().Tag(digest.Algorithm + "-" + digest.Hex + ".sig")
+
+	img, err := remote.Image(sigRef, opts...)
+	if err != nil {
+		return verificationError("cannot get cosign signature %s: %v", sigRef.String(), err)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return verificationError("cannot get cosign signature %s: %v", sigRef.String(), err)
+	}
+
+	for _, l := range manifest.Layers {
+		sig, err := base64.StdEncoding.DecodeString(l.Annotations[cosignSignatureAnno])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+
+		layer, err := img.LayerByDigest(l.Digest)
+		if err != nil {
+			return err
+		}
+
+		payload, err := readLayer(layer)
+		if err != nil {
+			return err
+		}
+
+		if verifySignature(pub, payload, sig) != nil {
+			continue
+		}
+
+		signed := struct {
+			Critical struct {
+				Image struct {
+					DockerManifestDigest string `json:"docker-manifest-digest"`
+				} `json:"image"`
+			} `json:"critical"`
+		}{}
+
+		if err = json.Unmarshal(payload, &signed); err != nil {
+			continue
+		}
+
+		if signed.Critical.Image.DockerManifestDigest == digest.String() {
+			return nil
+		}
+	}
+
+	return verificationError("no valid cosign signature for %s@%s", ref.Context().String(), digest.String())
+}
+
+func parsePublicKey(key []byte) (crypto.PublicKey, error) {
+
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", CosignKeyKey)
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", CosignKeyKey, err)
+	}
+
+	return pub, nil
+}
+
+func verifySignature(pub crypto.PublicKey, payload, sig []byte) error {
+
+	sum := sha256.Sum256(payload)
+
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, sum[:], sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// verifyGit refuses charts from git with a verification policy, they have no
+// packaged archive that a signature could cover.
+func verifyGit(spec helmerv1beta1.HelmChart) error {
+	if spec.Verify == nil {
+		return nil
+	}
+	return verificationError("charts loaded from git have no signed archive and cannot be verified")
+}
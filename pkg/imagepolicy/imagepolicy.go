@@ -0,0 +1,135 @@
+package imagepolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/postrender"
+	"github.com/openshift-psap/special-resource-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ErrNotAllowed is wrapped by the errors of images outside the allowed registries.
+var ErrNotAllowed = errors.New("image not allowed")
+
+// Resolver applies image policies to the containers of rendered objects.
+type Resolver struct {
+	registry registry.Registry
+	policies []*srov1beta1.SpecialResourceImagePolicy
+}
+
+// NewResolver returns a Resolver applying policies in order, the nil ones are
+// ignored. It returns nil if there is no policy to apply.
+func NewResolver(reg registry.Registry, policies ...*srov1beta1.SpecialResourceImagePolicy) *Resolver {
+
+	r := &Resolver{registry: reg}
+
+	for _, p := range policies {
+		if p != nil {
+			r.policies = append(r.policies, p)
+		}
+	}
+
+	if len(r.policies) == 0 {
+		return nil
+	}
+
+	return r
+}
+
+// hasPrefix returns true if image belongs to the registry or repository prefix.
+func hasPrefix(image, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return image == prefix || strings.HasPrefix(image, prefix+"/")
+}
+
+// Resolve returns image rewritten, pinned to its digest and checked against the
+// allowed registries of every policy.
+func (r *Resolver) Resolve(ctx context.Context, image string) (string, error) {
+
+	for _, p := range r.policies {
+		for _, rw := range p.RegistryRewrites {
+			if hasPrefix(image, rw.From) {
+				image = strings.TrimSuffix(rw.To, "/") + strings.TrimPrefix(image, strings.TrimSuffix(rw.From, "/"))
+				break
+			}
+		}
+	}
+
+	name, tag, digest := postrender.SplitImage(image)
+
+	for _, p := range r.policies {
+		if !p.PinDigests || digest != "" {
+			continue
+		}
+
+		resolved, err := r.registry.ImageDigest(ctx, image)
+		if err != nil {
+			return "", err
+		}
+
+		// Images yet to be built, e.g. driver containers, cannot be pinned
+		if resolved != "" {
+			digest = resolved
+			image = postrender.JoinImage(name, tag, digest)
+		}
+	}
+
+	for _, p := range r.policies {
+		if len(p.AllowedRegistries) == 0 {
+			continue
+		}
+
+		allowed := false
+		for _, prefix := range p.AllowedRegistries {
+			allowed = allowed || hasPrefix(image, prefix)
+		}
+
+		if !allowed {
+			return "", fmt.Errorf("%w: %s is not in %s", ErrNotAllowed, image, strings.Join(p.AllowedRegistries, ", "))
+		}
+	}
+
+	return image, nil
+}
+
+// Apply resolves the image of every container of obj and returns the images resolved.
+func (r *Resolver) Apply(ctx context.Context, obj *unstructured.Unstructured) ([]srov1beta1.ResolvedImageStatus, error) {
+
+	var (
+		images []srov1beta1.ResolvedImageStatus
+		err    error
+	)
+
+	postrender.VisitContainers(obj.Object, func(container map[string]interface{}) {
+
+		image, ok := container["image"].(string)
+		if !ok || image == "" || err != nil {
+			return
+		}
+
+		var resolved string
+		if resolved, err = r.Resolve(ctx, image); err != nil {
+			err = fmt.Errorf("%s %s: %w", obj.GetKind(), obj.GetName(), err)
+			return
+		}
+
+		container["image"] = resolved
+
+		name, _ := container["name"].(string)
+
+		images = append(images, srov1beta1.ResolvedImageStatus{
+			Kind:      obj.GetKind(),
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Container: name,
+			Image:     image,
+			Resolved:  resolved,
+		})
+	})
+
+	return images, err
+}
@@ -0,0 +1,115 @@
+package imagepolicy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/registry"
+)
+
+func TestImagePolicy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ImagePolicy Suite")
+}
+
+var _ = Describe("Resolver_Resolve", func() {
+	var (
+		ctrl         *gomock.Controller
+		mockRegistry *registry.MockRegistry
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockRegistry = registry.NewMockRegistry(ctrl)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should return nil without policies", func() {
+		Expect(NewResolver(mockRegistry)).To(BeNil())
+		Expect(NewResolver(mockRegistry, nil, nil)).To(BeNil())
+	})
+
+	DescribeTable("should rewrite the registry of images",
+		func(image, expected string) {
+			r := NewResolver(mockRegistry, &srov1beta1.SpecialResourceImagePolicy{
+				RegistryRewrites: []srov1beta1.SpecialResourceRegistryRewrite{
+					{From: "quay.io/vendor", To: "mirror.corp/vendor-mirror"},
+					{From: "quay.io", To: "mirror.corp/quay/"},
+				},
+			})
+
+			resolved, err := r.Resolve(ctx, image)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolved).To(Equal(expected))
+		},
+		Entry("repository prefix first", "quay.io/vendor/driver:1.0", "mirror.corp/vendor-mirror/driver:1.0"),
+		Entry("registry", "quay.io/other/driver:1.0", "mirror.corp/quay/other/driver:1.0"),
+		Entry("not a path component", "quay.io.example.com/driver:1.0", "quay.io.example.com/driver:1.0"),
+		Entry("other registry", "registry.redhat.io/ubi8", "registry.redhat.io/ubi8"),
+	)
+
+	It("should pin images to their digests", func() {
+		mockRegistry.EXPECT().ImageDigest(ctx, "quay.io/vendor/driver:1.0").Return("sha256:0123", nil)
+
+		r := NewResolver(mockRegistry, &srov1beta1.SpecialResourceImagePolicy{PinDigests: true}, &srov1beta1.SpecialResourceImagePolicy{PinDigests: true})
+
+		resolved, err := r.Resolve(ctx, "quay.io/vendor/driver:1.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved).To(Equal("quay.io/vendor/driver:1.0@sha256:0123"))
+	})
+
+	It("should keep the tag of images not found in their registry", func() {
+		mockRegistry.EXPECT().ImageDigest(ctx, "registry/driver-container:5.14.0").Return("", nil)
+
+		r := NewResolver(mockRegistry, &srov1beta1.SpecialResourceImagePolicy{PinDigests: true})
+
+		resolved, err := r.Resolve(ctx, "registry/driver-container:5.14.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved).To(Equal("registry/driver-container:5.14.0"))
+	})
+
+	It("should not resolve images already pinned", func() {
+		r := NewResolver(mockRegistry, &srov1beta1.SpecialResourceImagePolicy{PinDigests: true})
+
+		resolved, err := r.Resolve(ctx, "quay.io/vendor/driver@sha256:0123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved).To(Equal("quay.io/vendor/driver@sha256:0123"))
+	})
+
+	It("should return the registry errors", func() {
+		randomError := errors.New("random error")
+		mockRegistry.EXPECT().ImageDigest(ctx, "quay.io/vendor/driver:1.0").Return("", randomError)
+
+		r := NewResolver(mockRegistry, &srov1beta1.SpecialResourceImagePolicy{PinDigests: true})
+
+		_, err := r.Resolve(ctx, "quay.io/vendor/driver:1.0")
+		Expect(err).To(MatchError(randomError))
+	})
+
+	It("should check the rewritten images against every allow-list", func() {
+		r := NewResolver(mockRegistry,
+			&srov1beta1.SpecialResourceImagePolicy{
+				RegistryRewrites:  []srov1beta1.SpecialResourceRegistryRewrite{{From: "quay.io", To: "mirror.corp/quay"}},
+				AllowedRegistries: []string{"mirror.corp", "registry.redhat.io"},
+			},
+			&srov1beta1.SpecialResourceImagePolicy{AllowedRegistries: []string{"mirror.corp/quay"}},
+		)
+
+		resolved, err := r.Resolve(ctx, "quay.io/vendor/driver:1.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved).To(Equal("mirror.corp/quay/vendor/driver:1.0"))
+
+		_, err = r.Resolve(ctx, "registry.redhat.io/ubi8")
+		Expect(err).To(MatchError(ErrNotAllowed))
+	})
+})
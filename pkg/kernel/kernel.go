@@ -15,12 +15,37 @@ import (
 //go:generate mockgen -source=kernel.go -package=kernel -destination=mock_kernel_api.go
 
 type KernelData interface {
-	SetAffineAttributes(obj *unstructured.Unstructured, kernelFullVersion, operatingSystemMajorMinor string) error
+	SetAffineAttributes(obj *unstructured.Unstructured, kernelFullVersion, operatingSystemMajorMinor string, affine AffineObjects) error
 	IsObjectAffine(obj client.Object) bool
 	FullVersion(*corev1.NodeList) (string, error)
 	PatchVersion(kernelFullVersion string) (string, error)
 }
 
+// AffineObjects holds the original names of the kernel-affine objects that are
+// rendered together, grouped by kind. Objects referencing one of them are
+// rewritten to point to the renamed, per-kernel object.
+type AffineObjects map[string]map[string]bool
+
+func (a AffineObjects) Add(kind, name string) {
+	if a[kind] == nil {
+		a[kind] = make(map[string]bool)
+	}
+	a[kind][name] = true
+}
+
+func (a AffineObjects) Has(kind, name string) bool {
+	return a[kind][name]
+}
+
+// podSpecFields is the path to the PodSpec of each workload kind.
+var podSpecFields = map[string][]string{
+	"Pod":         {"spec"},
+	"Job":         {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+}
+
 type kernelData struct {
 	log logr.Logger
 }
@@ -31,25 +56,60 @@ func NewKernelData() KernelData {
 	}
 }
 
+// AffineSuffix returns the suffix appended to the name of kernel-affine objects.
+func AffineSuffix(kernelFullVersion, operatingSystemMajorMinor string) (string, error) {
+	kernelVersion := strings.ReplaceAll(kernelFullVersion, "_", "-")
+	return utils.FNV64a(operatingSystemMajorMinor + "-" + kernelVersion)
+}
+
 func (k *kernelData) SetAffineAttributes(obj *unstructured.Unstructured,
 	kernelFullVersion string,
-	operatingSystemMajorMinor string) error {
+	operatingSystemMajorMinor string,
+	affine AffineObjects) error {
 
-	kernelVersion := strings.ReplaceAll(kernelFullVersion, "_", "-")
-	hash64, err := utils.FNV64a(operatingSystemMajorMinor + "-" + kernelVersion)
+	hash64, err := AffineSuffix(kernelFullVersion, operatingSystemMajorMinor)
 	if err != nil {
 		return err
 	}
-	name := obj.GetName() + "-" + hash64
+	originalName := obj.GetName()
+	name := originalName + "-" + hash64
 	obj.SetName(name)
 
-	if obj.GetKind() == "BuildRun" {
-		if err = unstructured.SetNestedField(obj.Object, name, "spec", "buildRef", "name"); err != nil {
-			return err
+	affineName := func(kind, ref string) string {
+		if affine.Has(kind, ref) {
+			return ref + "-" + hash64
 		}
+		return ref
 	}
 
-	if obj.GetKind() == "DaemonSet" || obj.GetKind() == "Deployment" || obj.GetKind() == "StatefulSet" {
+	switch obj.GetKind() {
+	case "BuildRun":
+		ref, found, err := unstructured.NestedString(obj.Object, "spec", "buildRef", "name")
+		if err != nil {
+			return err
+		}
+		// The Build either was renamed together with this BuildRun or
+		// follows the convention of sharing the BuildRun's name.
+		if !found || ref == originalName {
+			ref = name
+		} else {
+			ref = affineName("Build", ref)
+		}
+		if err = unstructured.SetNestedField(obj.Object, ref, "spec", "buildRef", "name"); err != nil {
+			return err
+		}
+
+	case "Build":
+		for _, fields := range [][]string{
+			{"spec", "output", "credentials", "name"},
+			{"spec", "source", "credentials", "name"},
+		} {
+			if err = k.rewriteReference(obj, affineName, "Secret", fields...); err != nil {
+				return err
+			}
+		}
+
+	case "DaemonSet", "Deployment", "StatefulSet":
 		if err = unstructured.SetNestedField(obj.Object, name, "metadata", "labels", "app"); err != nil {
 			return err
 		}
@@ -62,9 +122,26 @@ func (k *kernelData) SetAffineAttributes(obj *unstructured.Unstructured,
 			return err
 		}
 
+	case "Job":
+		// The Job controller generates the selector, only the labels are set.
+		if err = unstructured.SetNestedField(obj.Object, name, "metadata", "labels", "app"); err != nil {
+			return err
+		}
+
 		if err = unstructured.SetNestedField(obj.Object, name, "spec", "template", "metadata", "labels", "app"); err != nil {
 			return err
 		}
+
+	case "Pod":
+		if err = unstructured.SetNestedField(obj.Object, name, "metadata", "labels", "app"); err != nil {
+			return err
+		}
+	}
+
+	if fields, ok := podSpecFields[obj.GetKind()]; ok {
+		if err = k.rewritePodSpecReferences(obj, affineName, fields...); err != nil {
+			return errors.Wrap(err, "Cannot rewrite kernel affine references for obj: "+obj.GetKind())
+		}
 	}
 
 	if err := k.setVersionNodeAffinity(obj, kernelFullVersion); err != nil {
@@ -73,18 +150,119 @@ func (k *kernelData) SetAffineAttributes(obj *unstructured.Unstructured,
 	return nil
 }
 
-func (k *kernelData) setVersionNodeAffinity(obj *unstructured.Unstructured, kernelFullVersion string) error {
+// rewriteReference points the name at fields to the per-kernel object of kind,
+// if that object was renamed together with obj.
+func (k *kernelData) rewriteReference(obj *unstructured.Unstructured, affineName func(string, string) string, kind string, fields ...string) error {
+
+	ref, found, err := unstructured.NestedString(obj.Object, fields...)
+	if err != nil || !found {
+		return err
+	}
 
-	if strings.Compare(obj.GetKind(), "DaemonSet") == 0 ||
-		strings.Compare(obj.GetKind(), "Deployment") == 0 ||
-		strings.Compare(obj.GetKind(), "Statefulset") == 0 {
-		if err := k.versionNodeAffinity(kernelFullVersion, obj, "spec", "template", "spec", "nodeSelector"); err != nil {
-			return errors.Wrap(err, "Cannot setup DaemonSet kernel version affinity")
+	if renamed := affineName(kind, ref); renamed != ref {
+		k.log.Info("Rewriting kernel affine reference", "Object", obj.GetName(), "Kind", kind, "From", ref, "To", renamed)
+		return unstructured.SetNestedField(obj.Object, renamed, fields...)
+	}
+
+	return nil
+}
+
+// rewritePodSpecReferences updates the ServiceAccount, ConfigMaps and Secrets
+// referenced by the PodSpec at fields: by its volumes, including projected ones,
+// by the env and envFrom of its containers, and by its image pull secrets.
+func (k *kernelData) rewritePodSpecReferences(obj *unstructured.Unstructured, affineName func(string, string) string, fields ...string) error {
+
+	for _, sa := range []string{"serviceAccountName", "serviceAccount"} {
+		if err := k.rewriteReference(obj, affineName, "ServiceAccount", append(fields, sa)...); err != nil {
+			return err
 		}
 	}
-	if strings.Compare(obj.GetKind(), "Pod") == 0 {
-		if err := k.versionNodeAffinity(kernelFullVersion, obj, "spec", "nodeSelector"); err != nil {
-			return errors.Wrap(err, "Cannot setup Pod kernel version affinity")
+
+	err := rewriteSlice(obj, func(volume map[string]interface{}) {
+		renameRef(volume, affineName, "ConfigMap", "configMap", "name")
+		renameRef(volume, affineName, "Secret", "secret", "secretName")
+
+		if projected, ok := volume["projected"].(map[string]interface{}); ok {
+			if sources, ok := projected["sources"].([]interface{}); ok {
+				for _, s := range sources {
+					if source, ok := s.(map[string]interface{}); ok {
+						renameRef(source, affineName, "ConfigMap", "configMap", "name")
+						renameRef(source, affineName, "Secret", "secret", "name")
+					}
+				}
+			}
+		}
+	}, append(fields, "volumes")...)
+	if err != nil {
+		return err
+	}
+
+	for _, containers := range []string{"initContainers", "containers"} {
+		err = rewriteSlice(obj, func(container map[string]interface{}) {
+			if env, ok := container["env"].([]interface{}); ok {
+				for _, e := range env {
+					if variable, ok := e.(map[string]interface{}); ok {
+						if valueFrom, ok := variable["valueFrom"].(map[string]interface{}); ok {
+							renameRef(valueFrom, affineName, "ConfigMap", "configMapKeyRef", "name")
+							renameRef(valueFrom, affineName, "Secret", "secretKeyRef", "name")
+						}
+					}
+				}
+			}
+			if envFrom, ok := container["envFrom"].([]interface{}); ok {
+				for _, e := range envFrom {
+					if source, ok := e.(map[string]interface{}); ok {
+						renameRef(source, affineName, "ConfigMap", "configMapRef", "name")
+						renameRef(source, affineName, "Secret", "secretRef", "name")
+					}
+				}
+			}
+		}, append(fields, containers)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rewriteSlice(obj, func(secret map[string]interface{}) {
+		if ref, ok := secret["name"].(string); ok {
+			secret["name"] = affineName("Secret", ref)
+		}
+	}, append(fields, "imagePullSecrets")...)
+}
+
+// rewriteSlice calls rewrite with every object of the slice at fields and sets the
+// rewritten slice.
+func rewriteSlice(obj *unstructured.Unstructured, rewrite func(map[string]interface{}), fields ...string) error {
+
+	items, found, err := unstructured.NestedSlice(obj.Object, fields...)
+	if err != nil || !found {
+		return err
+	}
+
+	for _, i := range items {
+		if item, ok := i.(map[string]interface{}); ok {
+			rewrite(item)
+		}
+	}
+
+	return unstructured.SetNestedSlice(obj.Object, items, fields...)
+}
+
+// renameRef points the name at field of the reference m[ref] to the per-kernel
+// object of kind.
+func renameRef(m map[string]interface{}, affineName func(string, string) string, kind, ref, field string) {
+	if r, ok := m[ref].(map[string]interface{}); ok {
+		if name, ok := r[field].(string); ok {
+			r[field] = affineName(kind, name)
+		}
+	}
+}
+
+func (k *kernelData) setVersionNodeAffinity(obj *unstructured.Unstructured, kernelFullVersion string) error {
+
+	if fields, ok := podSpecFields[obj.GetKind()]; ok {
+		if err := k.versionNodeAffinity(kernelFullVersion, obj, append(fields, "nodeSelector")...); err != nil {
+			return errors.Wrap(err, "Cannot setup "+obj.GetKind()+" kernel version affinity")
 		}
 	}
 	if strings.Compare(obj.GetKind(), "BuildConfig") == 0 {
@@ -47,10 +47,235 @@ var _ = Describe("AffineAttributes", func() {
 	It("should work for BuildRun", func() {
 		obj := newObj("BuildRun", objName)
 
-		err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor)
+		err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, AffineObjects{})
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(obj.GetName()).To(Equal(objNewName))
+
+		v, ok, err := unstructured.NestedString(obj.Object, "spec", "buildRef", "name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(v).To(Equal(objNewName))
+	})
+
+	It("should point a BuildRun to the Build renamed together with it", func() {
+		const buildName = "test-build"
+
+		obj := newObj("BuildRun", objName)
+		Expect(unstructured.SetNestedField(obj.Object, buildName, "spec", "buildRef", "name")).To(Succeed())
+
+		affine := AffineObjects{}
+		affine.Add("BuildRun", objName)
+		affine.Add("Build", buildName)
+
+		err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, affine)
+		Expect(err).NotTo(HaveOccurred())
+
+		v, _, err := unstructured.NestedString(obj.Object, "spec", "buildRef", "name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(buildName + "-" + objNameHash))
+	})
+
+	It("should not rewrite a BuildRun referencing a Build that is not kernel affine", func() {
+		const buildName = "shared-build"
+
+		obj := newObj("BuildRun", objName)
+		Expect(unstructured.SetNestedField(obj.Object, buildName, "spec", "buildRef", "name")).To(Succeed())
+
+		err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, AffineObjects{})
+		Expect(err).NotTo(HaveOccurred())
+
+		v, _, err := unstructured.NestedString(obj.Object, "spec", "buildRef", "name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(buildName))
+	})
+
+	It("should point a Build to the Secrets renamed together with it", func() {
+		const (
+			pushSecret = "push-secret"
+			gitSecret  = "git-secret"
+		)
+
+		obj := newObj("Build", objName)
+		Expect(unstructured.SetNestedField(obj.Object, pushSecret, "spec", "output", "credentials", "name")).To(Succeed())
+		Expect(unstructured.SetNestedField(obj.Object, gitSecret, "spec", "source", "credentials", "name")).To(Succeed())
+
+		affine := AffineObjects{}
+		affine.Add("Secret", pushSecret)
+
+		err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, affine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetName()).To(Equal(objNewName))
+
+		v, _, err := unstructured.NestedString(obj.Object, "spec", "output", "credentials", "name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(pushSecret + "-" + objNameHash))
+
+		v, _, err = unstructured.NestedString(obj.Object, "spec", "source", "credentials", "name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(gitSecret))
+	})
+
+	It("should work for Job", func() {
+		obj := newObj("Job", objName)
+
+		err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, AffineObjects{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetLabels()).To(HaveKeyWithValue("app", objNewName))
+
+		v, ok, err := unstructured.NestedString(obj.Object, "spec", "template", "metadata", "labels", "app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(v).To(Equal(objNewName))
+
+		_, ok, err = unstructured.NestedMap(obj.Object, "spec", "selector")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		m, ok, err := unstructured.NestedMap(obj.Object, "spec", "template", "spec", "nodeSelector")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(m).To(HaveKeyWithValue("feature.node.kubernetes.io/kernel-version.full", kernelFullVersion))
+	})
+
+	It("should point a PodSpec to the objects renamed together with it", func() {
+		const (
+			serviceAccount = "sa"
+			configMap      = "cm"
+			secret         = "secret"
+			pullSecret     = "pull-secret"
+		)
+
+		obj := newObj("DaemonSet", objName)
+
+		podSpec := map[string]interface{}{
+			"serviceAccountName": serviceAccount,
+			"volumes": []interface{}{
+				map[string]interface{}{"name": "a", "configMap": map[string]interface{}{"name": configMap}},
+				map[string]interface{}{"name": "b", "secret": map[string]interface{}{"secretName": secret}},
+			},
+			"imagePullSecrets": []interface{}{
+				map[string]interface{}{"name": pullSecret},
+			},
+		}
+		Expect(unstructured.SetNestedMap(obj.Object, podSpec, "spec", "template", "spec")).To(Succeed())
+
+		affine := AffineObjects{}
+		affine.Add("ServiceAccount", serviceAccount)
+		affine.Add("ConfigMap", configMap)
+		affine.Add("Secret", pullSecret)
+
+		err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, affine)
+		Expect(err).NotTo(HaveOccurred())
+
+		v, _, err := unstructured.NestedString(obj.Object, "spec", "template", "spec", "serviceAccountName")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(serviceAccount + "-" + objNameHash))
+
+		volumes, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "volumes")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(volumes).To(HaveLen(2))
+		Expect(volumes[0]).To(HaveKeyWithValue("configMap", map[string]interface{}{"name": configMap + "-" + objNameHash}))
+		// The Secret was not rendered together with the DaemonSet
+		Expect(volumes[1]).To(HaveKeyWithValue("secret", map[string]interface{}{"secretName": secret}))
+
+		pullSecrets, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "imagePullSecrets")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pullSecrets).To(ConsistOf(map[string]interface{}{"name": pullSecret + "-" + objNameHash}))
+	})
+
+	It("should point the env, envFrom and projected volumes of a PodSpec to the objects renamed together with it", func() {
+		const (
+			configMap = "cm"
+			secret    = "secret"
+			other     = "other"
+		)
+
+		obj := newObj("Deployment", objName)
+
+		container := func(name string) map[string]interface{} {
+			return map[string]interface{}{
+				"name": name,
+				"env": []interface{}{
+					map[string]interface{}{"name": "A", "value": "a"},
+					map[string]interface{}{"name": "B", "valueFrom": map[string]interface{}{
+						"configMapKeyRef": map[string]interface{}{"name": configMap, "key": "b"},
+					}},
+					map[string]interface{}{"name": "C", "valueFrom": map[string]interface{}{
+						"secretKeyRef": map[string]interface{}{"name": secret, "key": "c"},
+					}},
+					map[string]interface{}{"name": "D", "valueFrom": map[string]interface{}{
+						"secretKeyRef": map[string]interface{}{"name": other, "key": "d"},
+					}},
+				},
+				"envFrom": []interface{}{
+					map[string]interface{}{"configMapRef": map[string]interface{}{"name": configMap}},
+					map[string]interface{}{"secretRef": map[string]interface{}{"name": secret}},
+				},
+			}
+		}
+
+		podSpec := map[string]interface{}{
+			"initContainers": []interface{}{container("init")},
+			"containers":     []interface{}{container("main")},
+			"volumes": []interface{}{
+				map[string]interface{}{"name": "a", "projected": map[string]interface{}{
+					"sources": []interface{}{
+						map[string]interface{}{"configMap": map[string]interface{}{"name": configMap}},
+						map[string]interface{}{"secret": map[string]interface{}{"name": secret}},
+						map[string]interface{}{"secret": map[string]interface{}{"name": other}},
+						map[string]interface{}{"serviceAccountToken": map[string]interface{}{"path": "token"}},
+					},
+				}},
+			},
+		}
+		Expect(unstructured.SetNestedMap(obj.Object, podSpec, "spec", "template", "spec")).To(Succeed())
+
+		affine := AffineObjects{}
+		affine.Add("ConfigMap", configMap)
+		affine.Add("Secret", secret)
+
+		err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, affine)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, containers := range []string{"initContainers", "containers"} {
+			c, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", containers)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(HaveLen(1))
+
+			env, _, err := unstructured.NestedSlice(c[0].(map[string]interface{}), "env")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env[0]).To(Equal(map[string]interface{}{"name": "A", "value": "a"}))
+			Expect(env[1]).To(HaveKeyWithValue("valueFrom", map[string]interface{}{
+				"configMapKeyRef": map[string]interface{}{"name": configMap + "-" + objNameHash, "key": "b"},
+			}))
+			Expect(env[2]).To(HaveKeyWithValue("valueFrom", map[string]interface{}{
+				"secretKeyRef": map[string]interface{}{"name": secret + "-" + objNameHash, "key": "c"},
+			}))
+			// The Secret was not rendered together with the Deployment
+			Expect(env[3]).To(HaveKeyWithValue("valueFrom", map[string]interface{}{
+				"secretKeyRef": map[string]interface{}{"name": other, "key": "d"},
+			}))
+
+			envFrom, _, err := unstructured.NestedSlice(c[0].(map[string]interface{}), "envFrom")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envFrom).To(Equal([]interface{}{
+				map[string]interface{}{"configMapRef": map[string]interface{}{"name": configMap + "-" + objNameHash}},
+				map[string]interface{}{"secretRef": map[string]interface{}{"name": secret + "-" + objNameHash}},
+			}))
+		}
+
+		volumes, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "volumes")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(volumes).To(HaveLen(1))
+		Expect(volumes[0]).To(HaveKeyWithValue("projected", map[string]interface{}{
+			"sources": []interface{}{
+				map[string]interface{}{"configMap": map[string]interface{}{"name": configMap + "-" + objNameHash}},
+				map[string]interface{}{"secret": map[string]interface{}{"name": secret + "-" + objNameHash}},
+				map[string]interface{}{"secret": map[string]interface{}{"name": other}},
+				map[string]interface{}{"serviceAccountToken": map[string]interface{}{"path": "token"}},
+			},
+		}))
 	})
 
 	DescribeTable(
@@ -58,7 +283,7 @@ var _ = Describe("AffineAttributes", func() {
 		func(kind string) {
 			obj := newObj(kind, objNewName)
 
-			err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor)
+			err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, AffineObjects{})
 			Expect(err).NotTo(HaveOccurred())
 
 			expectedSelector := map[string]interface{}{
@@ -79,7 +304,7 @@ var _ = Describe("AffineAttributes", func() {
 		func(kind string) {
 			obj := newObj(kind, objName)
 
-			err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor)
+			err := kernel.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, AffineObjects{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(obj.GetLabels()).To(HaveKeyWithValue("app", objNewName))
 
@@ -93,19 +318,14 @@ var _ = Describe("AffineAttributes", func() {
 			Expect(ok).To(BeTrue())
 			Expect(v).To(Equal(objNewName))
 
-			// one if compares the kind to StatefulSet, the other one to StatefulSet (capital S)
-			if kind != "StatefulSet" {
-				expectedSelector := map[string]interface{}{
-					"feature.node.kubernetes.io/kernel-version.full": kernelFullVersion,
-				}
-
-				var m map[string]interface{}
-
-				m, ok, err = unstructured.NestedMap(obj.Object, "spec", "template", "spec", "nodeSelector")
-				Expect(err).NotTo(HaveOccurred())
-				Expect(ok).To(BeTrue())
-				Expect(m).To(Equal(expectedSelector))
+			expectedSelector := map[string]interface{}{
+				"feature.node.kubernetes.io/kernel-version.full": kernelFullVersion,
 			}
+
+			m, ok, err := unstructured.NestedMap(obj.Object, "spec", "template", "spec", "nodeSelector")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(m).To(Equal(expectedSelector))
 		},
 		Entry(nil, "DaemonSet"),
 		Entry(nil, "Deployment"),
@@ -155,7 +375,8 @@ var _ = Describe("SetVersionNodeAffinity", func() {
 		},
 		Entry("DaemonSet", "DaemonSet"),
 		Entry("Deployment", "Deployment"),
-		Entry("Statefulset", "Statefulset"),
+		Entry("StatefulSet", "StatefulSet"),
+		Entry("Job", "Job"),
 	)
 })
 
@@ -0,0 +1,81 @@
+package maintenance
+
+import (
+	"fmt"
+	"time"
+
+	// The operator image may not ship the time zone database
+	_ "time/tzdata"
+
+	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/robfig/cron/v3"
+)
+
+// Window is a parsed maintenance window.
+type Window struct {
+	schedule cron.Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+// Parse parses the schedule and time zone of window.
+func Parse(window v1beta1.SpecialResourceMaintenanceWindow) (*Window, error) {
+
+	location := time.UTC
+	if window.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(window.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", window.TimeZone, err)
+		}
+	}
+
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", window.Schedule, err)
+	}
+
+	if window.Duration.Duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %s: must be positive", window.Duration.Duration)
+	}
+
+	return &Window{schedule: schedule, duration: window.Duration.Duration, location: location}, nil
+}
+
+// IsOpen returns true if the window opened less than its duration before now.
+func (w *Window) IsOpen(now time.Time) bool {
+	opened := w.schedule.Next(now.In(w.location).Add(-w.duration))
+	return !opened.After(now)
+}
+
+// Next returns the first time the window opens after now.
+func (w *Window) Next(now time.Time) time.Time {
+	return w.schedule.Next(now.In(w.location))
+}
+
+// Open returns true if one of windows is open at now, or if there is no window, and
+// otherwise the next time one of them opens.
+func Open(windows []v1beta1.SpecialResourceMaintenanceWindow, now time.Time) (bool, time.Time, error) {
+
+	if len(windows) == 0 {
+		return true, time.Time{}, nil
+	}
+
+	var next time.Time
+
+	for i, spec := range windows {
+		window, err := Parse(spec)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("maintenance window %d: %w", i, err)
+		}
+
+		if window.IsOpen(now) {
+			return true, time.Time{}, nil
+		}
+
+		if opens := window.Next(now); next.IsZero() || opens.Before(next) {
+			next = opens
+		}
+	}
+
+	return false, next, nil
+}
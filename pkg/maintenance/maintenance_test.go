@@ -0,0 +1,90 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaintenance(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Maintenance Suite")
+}
+
+func window(schedule string, duration time.Duration, timeZone string) v1beta1.SpecialResourceMaintenanceWindow {
+	return v1beta1.SpecialResourceMaintenanceWindow{
+		Schedule: schedule,
+		Duration: metav1.Duration{Duration: duration},
+		TimeZone: timeZone,
+	}
+}
+
+var _ = Describe("Open", func() {
+	// A Wednesday
+	at := func(hour, minute int) time.Time {
+		return time.Date(2021, time.December, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	It("should be open without windows", func() {
+		open, next, err := Open(nil, at(12, 0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeTrue())
+		Expect(next).To(BeZero())
+	})
+
+	It("should be open during a window", func() {
+		windows := []v1beta1.SpecialResourceMaintenanceWindow{window("0 22 * * *", 4*time.Hour, "")}
+
+		for _, now := range []time.Time{at(22, 0), at(23, 59), at(1, 59)} {
+			open, _, err := Open(windows, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(open).To(BeTrue(), now.String())
+		}
+	})
+
+	It("should return the next opening outside of the windows", func() {
+		windows := []v1beta1.SpecialResourceMaintenanceWindow{
+			window("0 22 * * *", 4*time.Hour, ""),
+			window("30 12 * * 3", time.Hour, ""),
+		}
+
+		open, next, err := Open(windows, at(2, 0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(next).To(Equal(at(12, 30)))
+
+		open, next, err = Open(windows, at(13, 30))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(next).To(Equal(at(22, 0)))
+	})
+
+	It("should evaluate the schedule in the time zone of the window", func() {
+		// 22:00 in Paris is 21:00 UTC in winter
+		windows := []v1beta1.SpecialResourceMaintenanceWindow{window("0 22 * * *", time.Hour, "Europe/Paris")}
+
+		open, _, err := Open(windows, at(21, 30))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeTrue())
+
+		open, next, err := Open(windows, at(22, 30))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(next.UTC()).To(Equal(at(21, 0).Add(24 * time.Hour)))
+	})
+
+	It("should reject invalid windows", func() {
+		_, _, err := Open([]v1beta1.SpecialResourceMaintenanceWindow{window("0 22 * *", time.Hour, "")}, at(0, 0))
+		Expect(err).To(MatchError(ContainSubstring("invalid schedule")))
+
+		_, _, err = Open([]v1beta1.SpecialResourceMaintenanceWindow{window("0 22 * * *", time.Hour, "Mars/Olympus")}, at(0, 0))
+		Expect(err).To(MatchError(ContainSubstring("invalid time zone")))
+
+		_, _, err = Open([]v1beta1.SpecialResourceMaintenanceWindow{window("0 22 * * *", 0, "")}, at(0, 0))
+		Expect(err).To(MatchError(ContainSubstring("invalid duration")))
+	})
+})
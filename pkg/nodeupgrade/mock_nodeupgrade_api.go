@@ -0,0 +1,69 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: nodeupgrade.go
+
+// Package nodeupgrade is a generated GoMock package.
+package nodeupgrade
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	v1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	v1 "k8s.io/api/apps/v1"
+	v10 "k8s.io/api/core/v1"
+)
+
+// MockNodeUpgrader is a mock of NodeUpgrader interface.
+type MockNodeUpgrader struct {
+	ctrl     *gomock.Controller
+	recorder *MockNodeUpgraderMockRecorder
+}
+
+// MockNodeUpgraderMockRecorder is the mock recorder for MockNodeUpgrader.
+type MockNodeUpgraderMockRecorder struct {
+	mock *MockNodeUpgrader
+}
+
+// NewMockNodeUpgrader creates a new mock instance.
+func NewMockNodeUpgrader(ctrl *gomock.Controller) *MockNodeUpgrader {
+	mock := &MockNodeUpgrader{ctrl: ctrl}
+	mock.recorder = &MockNodeUpgraderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNodeUpgrader) EXPECT() *MockNodeUpgraderMockRecorder {
+	return m.recorder
+}
+
+// OutdatedPods mocks base method.
+func (m *MockNodeUpgrader) OutdatedPods(ctx context.Context, ds *v1.DaemonSet) (map[string]*v10.Pod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OutdatedPods", ctx, ds)
+	ret0, _ := ret[0].(map[string]*v10.Pod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OutdatedPods indicates an expected call of OutdatedPods.
+func (mr *MockNodeUpgraderMockRecorder) OutdatedPods(ctx, ds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OutdatedPods", reflect.TypeOf((*MockNodeUpgrader)(nil).OutdatedPods), ctx, ds)
+}
+
+// Upgrade mocks base method.
+func (m *MockNodeUpgrader) Upgrade(ctx context.Context, sr *v1beta1.SpecialResource, ds *v1.DaemonSet, statuses []v1beta1.NodeUpgradeStatus) ([]v1beta1.NodeUpgradeStatus, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upgrade", ctx, sr, ds, statuses)
+	ret0, _ := ret[0].([]v1beta1.NodeUpgradeStatus)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Upgrade indicates an expected call of Upgrade.
+func (mr *MockNodeUpgraderMockRecorder) Upgrade(ctx, sr, ds, statuses interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upgrade", reflect.TypeOf((*MockNodeUpgrader)(nil).Upgrade), ctx, sr, ds, statuses)
+}
@@ -0,0 +1,544 @@
+package nodeupgrade
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	"github.com/openshift-psap/special-resource-operator/pkg/maintenance"
+	"github.com/openshift-psap/special-resource-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+//go:generate mockgen -source=nodeupgrade.go -package=nodeupgrade -destination=mock_nodeupgrade_api.go
+
+const (
+	// LabelPrefix is followed by the name of the SpecialResource in the label
+	// recording the upgrade state of a node.
+	LabelPrefix = "specialresource.openshift.io/upgrade-"
+
+	// PodNodeNameField is the field index of the Pods by node, the drains only
+	// list the Pods of the node.
+	PodNodeNameField = "spec.nodeName"
+
+	defaultDrainTimeout = 5 * time.Minute
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+)
+
+// PodNodeName indexes a Pod by PodNodeNameField.
+func PodNodeName(obj client.Object) []string {
+	pod, ok := obj.(*v1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []string{pod.Spec.NodeName}
+}
+
+// NodeUpgrader upgrades the nodes running an outdated Pod of a driver DaemonSet
+// with the OnDelete update strategy, a few nodes at a time: the node is cordoned,
+// the consumers of the driver are evicted, the driver Pod is deleted and the
+// node is uncordoned once the new driver Pod is ready.
+type NodeUpgrader interface {
+	// Upgrade advances the upgrades of the nodes running ds without waiting and
+	// returns the updated statuses, and true if all the Pods of ds are up to date.
+	Upgrade(ctx context.Context, sr *v1beta1.SpecialResource, ds *appsv1.DaemonSet, statuses []v1beta1.NodeUpgradeStatus) ([]v1beta1.NodeUpgradeStatus, bool, error)
+
+	// OutdatedPods returns the Pods of ds by node that do not run its latest revision.
+	OutdatedPods(ctx context.Context, ds *appsv1.DaemonSet) (map[string]*v1.Pod, error)
+}
+
+type nodeUpgrader struct {
+	kubeClient clients.ClientsInterface
+	log        logr.Logger
+}
+
+func NewNodeUpgrader(kubeClient clients.ClientsInterface) NodeUpgrader {
+	return &nodeUpgrader{
+		kubeClient: kubeClient,
+		log:        zap.New(zap.UseDevMode(true)).WithName(utils.Print("nodeupgrade", utils.Blue)),
+	}
+}
+
+// InProgress returns true if the upgrade of the node is neither done nor failed.
+func InProgress(status v1beta1.NodeUpgradeStatus) bool {
+	return status.State == v1beta1.NodeUpgradeDraining || status.State == v1beta1.NodeUpgradeRestarting
+}
+
+func (u *nodeUpgrader) Upgrade(ctx context.Context, sr *v1beta1.SpecialResource, ds *appsv1.DaemonSet, statuses []v1beta1.NodeUpgradeStatus) ([]v1beta1.NodeUpgradeStatus, bool, error) {
+
+	name := ds.Namespace + "/" + ds.Name
+
+	revision, err := u.currentRevision(ctx, ds)
+	if err != nil {
+		return statuses, false, err
+	}
+
+	pods, err := u.daemonSetPods(ctx, ds)
+	if err != nil {
+		return statuses, false, err
+	}
+
+	kept := make([]v1beta1.NodeUpgradeStatus, 0, len(statuses))
+
+	byNode := make(map[string]int)
+	inProgress := 0
+
+	for _, status := range statuses {
+		if status.DaemonSet != name {
+			kept = append(kept, status)
+			continue
+		}
+
+		// The status of a node deleted in the meantime is dropped, its
+		// upgrade would neither finish nor fail otherwise
+		exists, err := u.nodeExists(ctx, status.Node)
+		if err != nil {
+			return statuses, false, err
+		}
+
+		if exists && InProgress(status) {
+			// The DaemonSet may have changed again during the upgrade
+			status.Revision = revision
+			err = u.step(ctx, sr, revision, pods[status.Node], &status)
+			exists = !apierrors.IsNotFound(err)
+			if err != nil && exists {
+				return statuses, false, fmt.Errorf("cannot upgrade node %s: %w", status.Node, err)
+			}
+		}
+
+		if !exists {
+			u.log.Info("Node deleted during its upgrade", "node", status.Node, "DaemonSet", name)
+			continue
+		}
+
+		if InProgress(status) {
+			inProgress++
+		}
+
+		byNode[status.Node] = len(kept)
+		kept = append(kept, status)
+	}
+
+	statuses = kept
+
+	nodes := make([]string, 0, len(pods))
+	for node, pod := range pods {
+		if pod.Labels[appsv1.DefaultDaemonSetUniqueLabelKey] != revision {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+
+	maxUnavailable := 1
+	if strategy := sr.Spec.UpgradeStrategy; strategy != nil && strategy.MaxUnavailable != nil {
+		if maxUnavailable, err = intstr.GetScaledValueFromIntOrPercent(strategy.MaxUnavailable, len(pods), true); err != nil {
+			return statuses, false, fmt.Errorf("invalid maxUnavailable: %w", err)
+		}
+		if maxUnavailable < 1 {
+			maxUnavailable = 1
+		}
+	}
+
+	// The upgrades in progress are finished, new ones wait for a maintenance window
+	open, _, err := maintenance.Open(sr.Spec.MaintenanceWindows, time.Now())
+	if err != nil {
+		return statuses, false, err
+	}
+
+	pending := 0
+
+	for _, node := range nodes {
+		// A failed node is retried with the next revision only
+		if i, found := byNode[node]; found && statuses[i].Revision == revision {
+			continue
+		}
+
+		if !open || inProgress >= maxUnavailable {
+			pending++
+			continue
+		}
+
+		now := metav1.Now()
+		status := v1beta1.NodeUpgradeStatus{
+			Node:               node,
+			DaemonSet:          name,
+			Revision:           revision,
+			State:              v1beta1.NodeUpgradeDraining,
+			StartTime:          now,
+			LastTransitionTime: now,
+		}
+
+		u.log.Info("Upgrading node", "node", node, "DaemonSet", name, "revision", revision)
+
+		err = u.updateNode(ctx, sr, node, status.State, func(n *v1.Node) {
+			if !n.Spec.Unschedulable {
+				n.Spec.Unschedulable = true
+				status.Cordoned = true
+			}
+		})
+		if apierrors.IsNotFound(err) {
+			// The Pods of a deleted node are yet to be garbage collected
+			continue
+		}
+		if err != nil {
+			return statuses, false, fmt.Errorf("cannot cordon node %s: %w", node, err)
+		}
+
+		if i, found := byNode[node]; found {
+			statuses[i] = status
+		} else {
+			statuses = append(statuses, status)
+			byNode[node] = len(statuses) - 1
+		}
+
+		inProgress++
+	}
+
+	return statuses, inProgress == 0 && pending == 0, nil
+}
+
+func (u *nodeUpgrader) OutdatedPods(ctx context.Context, ds *appsv1.DaemonSet) (map[string]*v1.Pod, error) {
+
+	revision, err := u.currentRevision(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := u.daemonSetPods(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	for node, pod := range pods {
+		if pod.Labels[appsv1.DefaultDaemonSetUniqueLabelKey] == revision {
+			delete(pods, node)
+		}
+	}
+
+	return pods, nil
+}
+
+// step advances the upgrade of a node: the Pods selected by the drain options are
+// evicted, then the driver Pod is deleted until its replacement is ready.
+func (u *nodeUpgrader) step(ctx context.Context, sr *v1beta1.SpecialResource, revision string, pod *v1.Pod, status *v1beta1.NodeUpgradeStatus) error {
+
+	if status.State == v1beta1.NodeUpgradeDraining {
+
+		var drain *v1beta1.SpecialResourceDrainSpec
+		if sr.Spec.UpgradeStrategy != nil {
+			drain = sr.Spec.UpgradeStrategy.Drain
+		}
+
+		if drain != nil {
+			timeout := defaultDrainTimeout
+			if drain.Timeout != nil {
+				timeout = drain.Timeout.Duration
+			}
+			expired := time.Since(status.LastTransitionTime.Time) > timeout
+
+			remaining, blocked, err := u.drain(ctx, status.Node, drain, expired && drain.Force)
+			if err != nil {
+				if reason, ok := err.(drainError); ok {
+					return u.fail(ctx, sr, status, string(reason))
+				}
+				return err
+			}
+
+			// The Pods using emptyDir volumes are left to be deleted by
+			// hand until the timeout, even with force
+			status.BlockedPods = blocked
+
+			if remaining > 0 {
+				if expired && (!drain.Force || len(blocked) > 0) {
+					message := fmt.Sprintf("%d Pods not evicted within %s", remaining, timeout)
+					if len(blocked) > 0 {
+						message += fmt.Sprintf(", %s use emptyDir volumes, see deleteEmptyDirData", strings.Join(blocked, ", "))
+					}
+					return u.fail(ctx, sr, status, message)
+				}
+				return nil
+			}
+		}
+
+		if err := u.transition(ctx, sr, status, v1beta1.NodeUpgradeRestarting, nil); err != nil {
+			return err
+		}
+	}
+
+	// The DaemonSet creates the new Pod once the old one is gone
+	if pod == nil {
+		return nil
+	}
+
+	if pod.Labels[appsv1.DefaultDaemonSetUniqueLabelKey] != revision {
+		if pod.DeletionTimestamp != nil {
+			return nil
+		}
+		u.log.Info("Deleting outdated driver Pod", "node", status.Node, "pod", pod.Name)
+		if err := u.kubeClient.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete Pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		return nil
+	}
+
+	if !podReady(pod) {
+		return nil
+	}
+
+	u.log.Info("Node upgraded", "node", status.Node, "revision", revision)
+
+	return u.transition(ctx, sr, status, v1beta1.NodeUpgradeDone, u.uncordon(status))
+}
+
+// drainError is a reason the node cannot be drained, other than timing out.
+type drainError string
+
+func (e drainError) Error() string {
+	return string(e)
+}
+
+// drain evicts, or deletes if force is true, the Pods of node selected by the drain
+// options, and returns the number of Pods still running on the node and the Pods
+// not evicted because of their emptyDir volumes.
+func (u *nodeUpgrader) drain(ctx context.Context, node string, drain *v1beta1.SpecialResourceDrainSpec, force bool) (int, []string, error) {
+
+	opts := []client.ListOption{client.MatchingFields{PodNodeNameField: node}}
+	if drain.PodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(drain.PodSelector)
+		if err != nil {
+			return 0, nil, drainError(fmt.Sprintf("invalid pod selector: %v", err))
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+
+	pods := &v1.PodList{}
+	if err := u.kubeClient.List(ctx, pods, opts...); err != nil {
+		return 0, nil, fmt.Errorf("cannot list Pods: %w", err)
+	}
+
+	remaining := 0
+	var blocked []string
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+
+		if !evictable(pod) || operatorPod(pod) {
+			continue
+		}
+
+		remaining++
+
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		if usesEmptyDir(pod) && !drain.DeleteEmptyDirData {
+			blocked = append(blocked, pod.Namespace+"/"+pod.Name)
+			continue
+		}
+
+		var err error
+		if force {
+			u.log.Info("Deleting Pod not evicted in time", "node", node, "pod", pod.Namespace+"/"+pod.Name)
+			err = u.kubeClient.Delete(ctx, pod)
+		} else {
+			u.log.Info("Evicting Pod", "node", node, "pod", pod.Namespace+"/"+pod.Name)
+			err = u.kubeClient.EvictPod(ctx, pod)
+		}
+
+		switch {
+		case err == nil:
+		case apierrors.IsNotFound(err):
+			remaining--
+		case apierrors.IsTooManyRequests(err):
+			// A PodDisruptionBudget does not allow the eviction yet
+			u.log.Info("Eviction not allowed yet", "pod", pod.Namespace+"/"+pod.Name)
+		default:
+			return 0, nil, fmt.Errorf("cannot evict Pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	return remaining, blocked, nil
+}
+
+// fail ends the upgrade of the node, which is uncordoned: the driver Pod was not touched.
+func (u *nodeUpgrader) fail(ctx context.Context, sr *v1beta1.SpecialResource, status *v1beta1.NodeUpgradeStatus, message string) error {
+	u.log.Info("Node upgrade failed", "node", status.Node, "reason", message)
+	status.Message = message
+	return u.transition(ctx, sr, status, v1beta1.NodeUpgradeFailed, u.uncordon(status))
+}
+
+func (u *nodeUpgrader) uncordon(status *v1beta1.NodeUpgradeStatus) func(*v1.Node) {
+	cordoned := status.Cordoned
+	return func(n *v1.Node) {
+		if cordoned {
+			n.Spec.Unschedulable = false
+		}
+	}
+}
+
+// transition sets the state of the node upgrade, in status and in the node's label.
+func (u *nodeUpgrader) transition(ctx context.Context, sr *v1beta1.SpecialResource, status *v1beta1.NodeUpgradeStatus, state string, mutate func(*v1.Node)) error {
+
+	if err := u.updateNode(ctx, sr, status.Node, state, mutate); err != nil {
+		return fmt.Errorf("cannot update node %s: %w", status.Node, err)
+	}
+
+	status.State = state
+	status.LastTransitionTime = metav1.Now()
+
+	return nil
+}
+
+// nodeExists returns false if the node name was deleted.
+func (u *nodeUpgrader) nodeExists(ctx context.Context, name string) (bool, error) {
+
+	err := u.kubeClient.Get(ctx, types.NamespacedName{Name: name}, &v1.Node{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot get node %s: %w", name, err)
+	}
+
+	return true, nil
+}
+
+func (u *nodeUpgrader) updateNode(ctx context.Context, sr *v1beta1.SpecialResource, name, state string, mutate func(*v1.Node)) error {
+
+	node := &v1.Node{}
+	if err := u.kubeClient.Get(ctx, types.NamespacedName{Name: name}, node); err != nil {
+		return err
+	}
+
+	if mutate != nil {
+		mutate(node)
+	}
+
+	labels := node.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[LabelPrefix+sr.Name] = state
+	node.SetLabels(labels)
+
+	return u.kubeClient.Update(ctx, node)
+}
+
+// currentRevision returns the hash of the latest ControllerRevision of ds, the
+// label of its up to date Pods.
+func (u *nodeUpgrader) currentRevision(ctx context.Context, ds *appsv1.DaemonSet) (string, error) {
+
+	revisions := &appsv1.ControllerRevisionList{}
+
+	opts := []client.ListOption{
+		client.InNamespace(ds.Namespace),
+		client.MatchingLabels(ds.Spec.Selector.MatchLabels),
+	}
+
+	if err := u.kubeClient.List(ctx, revisions, opts...); err != nil {
+		return "", fmt.Errorf("cannot list the ControllerRevisions of DaemonSet %s/%s: %w", ds.Namespace, ds.Name, err)
+	}
+
+	var latest *appsv1.ControllerRevision
+	for i := range revisions.Items {
+		rev := &revisions.Items[i]
+		if metav1.IsControlledBy(rev, ds) && (latest == nil || rev.Revision > latest.Revision) {
+			latest = rev
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("DaemonSet %s/%s has no ControllerRevision yet", ds.Namespace, ds.Name)
+	}
+
+	return latest.Labels[appsv1.DefaultDaemonSetUniqueLabelKey], nil
+}
+
+// daemonSetPods returns the Pods of ds by node, preferring the Pods not being deleted.
+func (u *nodeUpgrader) daemonSetPods(ctx context.Context, ds *appsv1.DaemonSet) (map[string]*v1.Pod, error) {
+
+	pods := &v1.PodList{}
+
+	opts := []client.ListOption{
+		client.InNamespace(ds.Namespace),
+		client.MatchingLabels(ds.Spec.Selector.MatchLabels),
+	}
+
+	if err := u.kubeClient.List(ctx, pods, opts...); err != nil {
+		return nil, fmt.Errorf("cannot list the Pods of DaemonSet %s/%s: %w", ds.Namespace, ds.Name, err)
+	}
+
+	byNode := make(map[string]*v1.Pod)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !metav1.IsControlledBy(pod, ds) || pod.Spec.NodeName == "" {
+			continue
+		}
+		if other, found := byNode[pod.Spec.NodeName]; found && other.DeletionTimestamp == nil {
+			continue
+		}
+		byNode[pod.Spec.NodeName] = pod
+	}
+
+	return byNode, nil
+}
+
+// evictable returns false for the Pods a drain leaves alone: DaemonSet Pods would be
+// recreated on the node, mirror Pods cannot be evicted, finished Pods hold no resource.
+func evictable(pod *v1.Pod) bool {
+
+	if _, found := pod.Annotations[mirrorPodAnnotation]; found {
+		return false
+	}
+
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return false
+	}
+
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+
+	return true
+}
+
+// operatorPod returns true for the Pod of the operator, which drains the node.
+func operatorPod(pod *v1.Pod) bool {
+	name := os.Getenv("POD_NAME")
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	return pod.Namespace == os.Getenv("OPERATOR_NAMESPACE") && pod.Name == name
+}
+
+func usesEmptyDir(pod *v1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,432 @@
+package nodeupgrade
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNodeUpgrade(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "NodeUpgrade Suite")
+}
+
+const (
+	current  = "new"
+	outdated = "old"
+	dsName   = "driver-container/simple-kmod-driver-container"
+)
+
+var _ = Describe("NodeUpgrader_Upgrade", func() {
+	var (
+		ctrl       *gomock.Controller
+		mockClient *clients.MockClientsInterface
+		u          *nodeUpgrader
+		sr         *v1beta1.SpecialResource
+		ds         *appsv1.DaemonSet
+		nodes      map[string]*v1.Node
+		pods       []v1.Pod
+		deleted    []string
+	)
+
+	ctx := context.Background()
+
+	newPod := func(name, node, revision string, ready bool) v1.Pod {
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ds.Namespace,
+				Labels:    map[string]string{appsv1.DefaultDaemonSetUniqueLabelKey: revision},
+			},
+			Spec: v1.PodSpec{NodeName: node},
+		}
+		if revision != "" {
+			pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet"))}
+		}
+		if ready {
+			pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+		}
+		return pod
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockClient = clients.NewMockClientsInterface(ctrl)
+		u = NewNodeUpgrader(mockClient).(*nodeUpgrader)
+
+		sr = &v1beta1.SpecialResource{
+			ObjectMeta: metav1.ObjectMeta{Name: "simple-kmod"},
+			Spec: v1beta1.SpecialResourceSpec{
+				UpgradeStrategy: &v1beta1.SpecialResourceUpgradeStrategy{Type: v1beta1.UpgradeStrategyOnDelete},
+			},
+		}
+
+		ds = &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "simple-kmod-driver-container", Namespace: "driver-container", UID: "ds-uid"},
+			Spec:       appsv1.DaemonSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "driver"}}},
+		}
+
+		nodes = map[string]*v1.Node{
+			"worker-0": {ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}},
+			"worker-1": {ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+		}
+		pods = nil
+		deleted = nil
+
+		revisions := []appsv1.ControllerRevision{
+			{ObjectMeta: metav1.ObjectMeta{Name: "rev-1", Labels: map[string]string{appsv1.DefaultDaemonSetUniqueLabelKey: outdated}}, Revision: 1},
+			{ObjectMeta: metav1.ObjectMeta{Name: "rev-2", Labels: map[string]string{appsv1.DefaultDaemonSetUniqueLabelKey: current}}, Revision: 2},
+		}
+		for i := range revisions {
+			revisions[i].OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet"))}
+		}
+
+		mockClient.EXPECT().
+			List(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+				switch l := list.(type) {
+				case *appsv1.ControllerRevisionList:
+					l.Items = revisions
+				case *v1.PodList:
+					// The drains list the Pods of a node by field
+					lo := (&client.ListOptions{}).ApplyOptions(opts)
+					l.Items = nil
+					for _, pod := range pods {
+						if lo.FieldSelector == nil || lo.FieldSelector.Matches(fields.Set{PodNodeNameField: pod.Spec.NodeName}) {
+							l.Items = append(l.Items, pod)
+						}
+					}
+				}
+				return nil
+			}).
+			AnyTimes()
+
+		mockClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.Node{})).
+			DoAndReturn(func(_ context.Context, key types.NamespacedName, node *v1.Node) error {
+				if nodes[key.Name] == nil {
+					return apierrors.NewNotFound(v1.Resource("nodes"), key.Name)
+				}
+				nodes[key.Name].DeepCopyInto(node)
+				return nil
+			}).
+			AnyTimes()
+
+		mockClient.EXPECT().
+			Update(ctx, gomock.AssignableToTypeOf(&v1.Node{})).
+			DoAndReturn(func(_ context.Context, node *v1.Node) error {
+				if nodes[node.Name] == nil {
+					return apierrors.NewNotFound(v1.Resource("nodes"), node.Name)
+				}
+				nodes[node.Name] = node.DeepCopy()
+				return nil
+			}).
+			AnyTimes()
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	label := func(node string) string {
+		return nodes[node].Labels[LabelPrefix+sr.Name]
+	}
+
+	It("should be done if all the Pods are up to date", func() {
+		pods = []v1.Pod{newPod("a", "worker-0", current, true), newPod("b", "worker-1", current, true)}
+
+		statuses, done, err := u.Upgrade(ctx, sr, ds, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeTrue())
+		Expect(statuses).To(BeEmpty())
+	})
+
+	It("should return the outdated Pods by node", func() {
+		pods = []v1.Pod{newPod("a", "worker-0", outdated, true), newPod("b", "worker-1", current, true)}
+
+		outdatedPods, err := u.OutdatedPods(ctx, ds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outdatedPods).To(HaveLen(1))
+		Expect(outdatedPods["worker-0"].Name).To(Equal("a"))
+	})
+
+	It("should cordon at most maxUnavailable nodes", func() {
+		pods = []v1.Pod{newPod("a", "worker-0", outdated, true), newPod("b", "worker-1", outdated, true)}
+
+		statuses, done, err := u.Upgrade(ctx, sr, ds, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeFalse())
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].Node).To(Equal("worker-0"))
+		Expect(statuses[0].DaemonSet).To(Equal(dsName))
+		Expect(statuses[0].Revision).To(Equal(current))
+		Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeDraining))
+		Expect(statuses[0].Cordoned).To(BeTrue())
+
+		Expect(nodes["worker-0"].Spec.Unschedulable).To(BeTrue())
+		Expect(label("worker-0")).To(Equal(v1beta1.NodeUpgradeDraining))
+		Expect(nodes["worker-1"].Spec.Unschedulable).To(BeFalse())
+
+		two := intstr.FromString("100%")
+		sr.Spec.UpgradeStrategy.MaxUnavailable = &two
+
+		statuses, _, err = u.Upgrade(ctx, sr, ds, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(HaveLen(2))
+	})
+
+	It("should not start upgrades outside of the maintenance windows", func() {
+		pods = []v1.Pod{newPod("a", "worker-0", outdated, true)}
+
+		// Opens one hour after the current time for one minute
+		opens := time.Now().UTC().Add(time.Hour)
+		sr.Spec.MaintenanceWindows = []v1beta1.SpecialResourceMaintenanceWindow{{
+			Schedule: fmt.Sprintf("%d %d * * *", opens.Minute(), opens.Hour()),
+			Duration: metav1.Duration{Duration: time.Minute},
+		}}
+
+		statuses, done, err := u.Upgrade(ctx, sr, ds, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeFalse())
+		Expect(statuses).To(BeEmpty())
+		Expect(nodes["worker-0"].Spec.Unschedulable).To(BeFalse())
+	})
+
+	It("should not uncordon a node it did not cordon", func() {
+		nodes["worker-0"].Spec.Unschedulable = true
+		pods = []v1.Pod{newPod("a", "worker-0", outdated, true)}
+
+		statuses, _, err := u.Upgrade(ctx, sr, ds, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses[0].Cordoned).To(BeFalse())
+
+		pods = []v1.Pod{newPod("a", "worker-0", current, true)}
+		statuses[0].State = v1beta1.NodeUpgradeRestarting
+
+		statuses, done, err := u.Upgrade(ctx, sr, ds, statuses)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeTrue())
+		Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeDone))
+		Expect(nodes["worker-0"].Spec.Unschedulable).To(BeTrue())
+	})
+
+	It("should replace the driver Pod and uncordon the node once it is ready", func() {
+		pods = []v1.Pod{newPod("a", "worker-0", outdated, true)}
+
+		mockClient.EXPECT().
+			Delete(ctx, gomock.AssignableToTypeOf(&v1.Pod{})).
+			DoAndReturn(func(_ context.Context, pod *v1.Pod) error {
+				deleted = append(deleted, pod.Name)
+				return nil
+			})
+
+		statuses, _, err := u.Upgrade(ctx, sr, ds, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Without drain options the driver Pod is deleted right away
+		statuses, done, err := u.Upgrade(ctx, sr, ds, statuses)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeFalse())
+		Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeRestarting))
+		Expect(deleted).To(Equal([]string{"a"}))
+		Expect(label("worker-0")).To(Equal(v1beta1.NodeUpgradeRestarting))
+
+		pods = []v1.Pod{newPod("b", "worker-0", current, false)}
+
+		statuses, done, err = u.Upgrade(ctx, sr, ds, statuses)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeFalse())
+		Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeRestarting))
+
+		pods = []v1.Pod{newPod("b", "worker-0", current, true)}
+
+		statuses, done, err = u.Upgrade(ctx, sr, ds, statuses)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeTrue())
+		Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeDone))
+		Expect(nodes["worker-0"].Spec.Unschedulable).To(BeFalse())
+		Expect(label("worker-0")).To(Equal(v1beta1.NodeUpgradeDone))
+	})
+
+	DescribeTable("should drop the status of a node deleted during its upgrade",
+		func(state string) {
+			pods = []v1.Pod{newPod("b", "worker-1", outdated, true)}
+
+			now := metav1.Now()
+			statuses := []v1beta1.NodeUpgradeStatus{
+				{Node: "worker-0", DaemonSet: dsName, Revision: current, State: state, Cordoned: true, StartTime: now, LastTransitionTime: now},
+			}
+			delete(nodes, "worker-0")
+
+			// The upgrade of the next node starts right away
+			statuses, done, err := u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(BeFalse())
+			Expect(statuses).To(HaveLen(1))
+			Expect(statuses[0].Node).To(Equal("worker-1"))
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeDraining))
+		},
+		Entry("while draining", v1beta1.NodeUpgradeDraining),
+		Entry("while restarting", v1beta1.NodeUpgradeRestarting),
+		Entry("once done", v1beta1.NodeUpgradeDone),
+	)
+
+	It("should not upgrade a deleted node whose Pod is left", func() {
+		pods = []v1.Pod{newPod("a", "worker-0", outdated, true)}
+		delete(nodes, "worker-0")
+
+		statuses, _, err := u.Upgrade(ctx, sr, ds, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(BeEmpty())
+	})
+
+	Context("with drain options", func() {
+		var consumer v1.Pod
+
+		BeforeEach(func() {
+			sr.Spec.UpgradeStrategy.Drain = &v1beta1.SpecialResourceDrainSpec{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+			}
+			consumer = newPod("consumer", "worker-0", "", true)
+			consumer.Namespace = "workloads"
+			pods = []v1.Pod{newPod("a", "worker-0", outdated, true), consumer, newPod("other", "worker-1", "", true)}
+		})
+
+		It("should wait for the evicted Pods to be gone", func() {
+			mockClient.EXPECT().EvictPod(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, pod *v1.Pod) error {
+				Expect(pod.Name).To(Equal("consumer"))
+				return nil
+			})
+
+			statuses, _, err := u.Upgrade(ctx, sr, ds, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			statuses, _, err = u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeDraining))
+
+			now := metav1.Now()
+			pods[1].DeletionTimestamp = &now
+
+			statuses, _, err = u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeDraining))
+
+			mockClient.EXPECT().Delete(ctx, gomock.Any())
+			pods = pods[:1]
+
+			statuses, _, err = u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeRestarting))
+		})
+
+		It("should fail the node if the eviction is not allowed in time", func() {
+			mockClient.EXPECT().
+				EvictPod(ctx, gomock.Any()).
+				Return(apierrors.NewTooManyRequests("disruption budget", 10)).
+				Times(2)
+
+			statuses, _, err := u.Upgrade(ctx, sr, ds, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			statuses, _, err = u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeDraining))
+
+			statuses[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+
+			statuses, done, err := u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeFailed))
+			Expect(statuses[0].Message).To(ContainSubstring("not evicted within 5m0s"))
+			Expect(nodes["worker-0"].Spec.Unschedulable).To(BeFalse())
+
+			// The node is not retried for the same revision
+			Expect(done).To(BeTrue())
+		})
+
+		It("should delete the Pods not evicted in time with force", func() {
+			sr.Spec.UpgradeStrategy.Drain.Force = true
+
+			statuses, _, err := u.Upgrade(ctx, sr, ds, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			statuses[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+
+			mockClient.EXPECT().Delete(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, pod *v1.Pod) error {
+				Expect(pod.Name).To(Equal("consumer"))
+				return nil
+			})
+
+			statuses, _, err = u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeDraining))
+		})
+
+		It("should evict the other Pods and report the ones using emptyDir volumes", func() {
+			pods[1].Spec.Volumes = []v1.Volume{{Name: "scratch", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+			other := newPod("other-consumer", "worker-0", "", true)
+			other.Namespace = "workloads"
+			pods = append(pods, other)
+
+			mockClient.EXPECT().EvictPod(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, pod *v1.Pod) error {
+				Expect(pod.Name).To(Equal("other-consumer"))
+				return nil
+			})
+
+			statuses, _, err := u.Upgrade(ctx, sr, ds, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			statuses, _, err = u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeDraining))
+			Expect(statuses[0].BlockedPods).To(Equal([]string{"workloads/consumer"}))
+
+			// The Pod using emptyDir volumes is still there once the drain times out
+			pods = pods[:3]
+			statuses[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+
+			statuses, _, err = u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeFailed))
+			Expect(statuses[0].Message).To(ContainSubstring("workloads/consumer use emptyDir"))
+			Expect(label("worker-0")).To(Equal(v1beta1.NodeUpgradeFailed))
+		})
+
+		It("should not evict the operator's own Pod", func() {
+			sr.Spec.UpgradeStrategy.Drain.PodSelector = nil
+			pods[1].Spec.Volumes = []v1.Volume{{Name: "scratch", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+
+			os.Setenv("OPERATOR_NAMESPACE", "workloads")
+			os.Setenv("POD_NAME", "consumer")
+			defer os.Unsetenv("OPERATOR_NAMESPACE")
+			defer os.Unsetenv("POD_NAME")
+
+			statuses, _, err := u.Upgrade(ctx, sr, ds, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			mockClient.EXPECT().Delete(ctx, gomock.Any())
+
+			statuses, _, err = u.Upgrade(ctx, sr, ds, statuses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses[0].State).To(Equal(v1beta1.NodeUpgradeRestarting))
+			Expect(statuses[0].BlockedPods).To(BeEmpty())
+		})
+	})
+})
@@ -42,6 +42,8 @@ type pollActions struct {
 	waitFor    map[string]func(context.Context, *unstructured.Unstructured) error
 }
 
+const ShipwrightGroup = "shipwright.io"
+
 var (
 	retryInterval = time.Second * 5
 	timeout       = time.Second * 30
@@ -58,6 +60,8 @@ func New(kubeClient clients.ClientsInterface, lc lifecycle.Lifecycle, storage st
 		"Pod":                      actions.forPod,
 		"DaemonSet":                actions.ForDaemonSet,
 		"BuildConfig":              actions.forBuild,
+		"Build":                    actions.forShipwrightBuild,
+		"BuildRun":                 actions.forBuildRun,
 		"Secret":                   actions.forSecret,
 		"CustomResourceDefinition": actions.forCRD,
 		"Job":                      actions.forJob,
@@ -450,6 +454,80 @@ func (p *pollActions) forBuild(ctx context.Context, obj *unstructured.Unstructur
 	return p.forResourceFullAvailability(ctx, build, callback)
 }
 
+// forShipwrightBuild waits for a Shipwright Build to be registered. OpenShift
+// Builds are owned by a BuildConfig and only need to exist.
+func (p *pollActions) forShipwrightBuild(ctx context.Context, obj *unstructured.Unstructured) error {
+
+	if err := p.forResourceAvailability(ctx, obj); err != nil {
+		return err
+	}
+
+	if obj.GroupVersionKind().Group != ShipwrightGroup {
+		return nil
+	}
+
+	return p.forResourceFullAvailability(ctx, obj, func(_ context.Context, obj *unstructured.Unstructured) (bool, error) {
+
+		registered, found, err := unstructured.NestedString(obj.Object, "status", "registered")
+		if err != nil || !found {
+			return false, nil
+		}
+
+		if registered == "False" {
+			reason, _, _ := unstructured.NestedString(obj.Object, "status", "reason")
+			message, _, _ := unstructured.NestedString(obj.Object, "status", "message")
+			return false, fmt.Errorf("Build %s/%s is not registered: %s: %s", obj.GetNamespace(), obj.GetName(), reason, message)
+		}
+
+		return registered == "True", nil
+	})
+}
+
+// BuildRunCondition returns the status, reason and message of the Succeeded
+// condition of a Shipwright BuildRun.
+func BuildRunCondition(obj *unstructured.Unstructured) (string, string, string) {
+
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	utils.WarnOnError(err)
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Succeeded" {
+			continue
+		}
+
+		status, _, _ := unstructured.NestedString(condition, "status")
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		message, _, _ := unstructured.NestedString(condition, "message")
+
+		return status, reason, message
+	}
+
+	return "", "", ""
+}
+
+func (p *pollActions) forBuildRun(ctx context.Context, obj *unstructured.Unstructured) error {
+
+	if err := p.forResourceAvailability(ctx, obj); err != nil {
+		return err
+	}
+
+	return p.forResourceFullAvailability(ctx, obj, func(_ context.Context, obj *unstructured.Unstructured) (bool, error) {
+
+		status, reason, message := BuildRunCondition(obj)
+
+		switch status {
+		case "True":
+			return true, nil
+		case "False":
+			return false, fmt.Errorf("BuildRun %s/%s failed: %s: %s", obj.GetNamespace(), obj.GetName(), reason, message)
+		}
+
+		p.log.Info("BuildRun not finished", "name", obj.GetName(), "reason", reason)
+		return false, nil
+	})
+}
+
 func (p *pollActions) forResourceFullAvailability(ctx context.Context, obj *unstructured.Unstructured, callback statusCallback) error {
 
 	found := obj.DeepCopy()
@@ -312,6 +312,74 @@ var _ = Context("Waiting for Build", func() {
 	})
 })
 
+var _ = Context("Waiting for Shipwright", func() {
+
+	prepareShipwright := func(kind, name string) *unstructured.Unstructured {
+		obj := prepareUnstructured(kind, name, namespace)
+		obj.SetAPIVersion("shipwright.io/v1alpha1")
+		return obj
+	}
+
+	DescribeTable("BuildRuns",
+		func(status string, matcher gtypes.GomegaMatcher) {
+			// forResourceAvailability
+			mockClientsInterface.EXPECT().Get(Any(), Any(), Any()).Return(nil)
+
+			// forResourceFullAvailability
+			mockClientsInterface.EXPECT().Get(Any(), Any(), Any()).
+				DoAndReturn(func(_ context.Context, _ client.ObjectKey, o client.Object) error {
+					u := o.(*unstructured.Unstructured)
+					err := unstructured.SetNestedSlice(u.Object,
+						[]interface{}{
+							map[string]interface{}{
+								"type":    "Succeeded",
+								"status":  status,
+								"reason":  "SomeReason",
+								"message": "some message",
+							}},
+						"status", "conditions")
+					Expect(err).ToNot(HaveOccurred())
+					return nil
+				}).AnyTimes()
+
+			Expect(pa.ForResource(context.Background(), prepareShipwright("BuildRun", "buildrun-name"))).To(matcher)
+		},
+		Entry("which have succeeded", "True", Succeed()),
+		Entry("which have failed", "False", MatchError(ContainSubstring("SomeReason: some message"))),
+		Entry("which are still running", "Unknown", MatchError(wait.ErrWaitTimeout)),
+	)
+
+	DescribeTable("Builds",
+		func(registered string, matcher gtypes.GomegaMatcher) {
+			// forResourceAvailability
+			mockClientsInterface.EXPECT().Get(Any(), Any(), Any()).Return(nil)
+
+			// forResourceFullAvailability
+			mockClientsInterface.EXPECT().Get(Any(), Any(), Any()).
+				DoAndReturn(func(_ context.Context, _ client.ObjectKey, o client.Object) error {
+					u := o.(*unstructured.Unstructured)
+					Expect(unstructured.SetNestedField(u.Object, registered, "status", "registered")).To(Succeed())
+					Expect(unstructured.SetNestedField(u.Object, "BuildStrategyNotFound", "status", "reason")).To(Succeed())
+					return nil
+				}).AnyTimes()
+
+			Expect(pa.ForResource(context.Background(), prepareShipwright("Build", "build-name"))).To(matcher)
+		},
+		Entry("which are registered", "True", Succeed()),
+		Entry("which are not registered", "False", MatchError(ContainSubstring("BuildStrategyNotFound"))),
+	)
+
+	It("should only wait for OpenShift Builds to exist", func() {
+		// forResourceAvailability
+		mockClientsInterface.EXPECT().Get(Any(), Any(), Any()).Return(nil)
+
+		build := prepareUnstructured("Build", "build-name", namespace)
+		build.SetAPIVersion("build.openshift.io/v1")
+
+		Expect(pa.ForResource(context.Background(), build)).To(Succeed())
+	})
+})
+
 var _ = Context("Waiting for DaemonSet", func() {
 	namespacedName := types.NamespacedName{Namespace: namespace, Name: daemonSetName}
 	var obj *unstructured.Unstructured
@@ -0,0 +1,307 @@
+package postrender
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/yamlutil"
+	"helm.sh/helm/v3/pkg/postrender"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// NewPostRenderer returns the post-renderer applying spec to the manifests rendered by
+// Helm, or nil if spec has nothing to apply.
+func NewPostRenderer(spec *srov1beta1.SpecialResourcePostRender) (postrender.PostRenderer, error) {
+
+	if spec == nil || (len(spec.Patches) == 0 && len(spec.Images) == 0) {
+		return nil, nil
+	}
+
+	r := &renderer{images: spec.Images}
+
+	for i, p := range spec.Patches {
+		c, err := compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid post-render patch %d: %w", i, err)
+		}
+		r.patches = append(r.patches, c)
+	}
+
+	return r, nil
+}
+
+type renderer struct {
+	patches []*patch
+	images  []srov1beta1.SpecialResourceImageOverride
+}
+
+type patch struct {
+	target   srov1beta1.SpecialResourcePatchTarget
+	selector labels.Selector
+
+	// Exactly one of json6902 and merge is set
+	json6902 jsonpatch.Patch
+	merge    []byte
+}
+
+func compile(p srov1beta1.SpecialResourcePatch) (*patch, error) {
+
+	doc, err := yaml.YAMLToJSON([]byte(p.Patch))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse patch: %w", err)
+	}
+
+	c := &patch{selector: labels.Everything()}
+	if p.Target != nil {
+		c.target = *p.Target
+	}
+
+	switch p.Type {
+	case srov1beta1.PatchTypeJSON6902:
+		if p.Target == nil {
+			return nil, errors.New("JSON6902 patches require a target")
+		}
+		if c.json6902, err = jsonpatch.DecodePatch(doc); err != nil {
+			return nil, fmt.Errorf("cannot decode JSON6902 patch: %w", err)
+		}
+
+	case "", srov1beta1.PatchTypeStrategicMerge:
+		obj := map[string]interface{}{}
+		if err = json.Unmarshal(doc, &obj); err != nil {
+			return nil, fmt.Errorf("strategic merge patch is not an object: %w", err)
+		}
+
+		// Like kustomize, an untargeted patch selects the objects of its own kind and name
+		if p.Target == nil {
+			u := unstructured.Unstructured{Object: obj}
+			gvk := u.GroupVersionKind()
+			c.target = srov1beta1.SpecialResourcePatchTarget{
+				Group:     gvk.Group,
+				Version:   gvk.Version,
+				Kind:      gvk.Kind,
+				Name:      u.GetName(),
+				Namespace: u.GetNamespace(),
+			}
+		}
+		c.merge = doc
+
+	default:
+		return nil, fmt.Errorf("unsupported patch type %q", p.Type)
+	}
+
+	if c.target.LabelSelector != "" {
+		if c.selector, err = labels.Parse(c.target.LabelSelector); err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", c.target.LabelSelector, err)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *patch) matches(obj *unstructured.Unstructured) bool {
+
+	gvk := obj.GroupVersionKind()
+	t := c.target
+
+	return (t.Group == "" || t.Group == gvk.Group) &&
+		(t.Version == "" || t.Version == gvk.Version) &&
+		(t.Kind == "" || t.Kind == gvk.Kind) &&
+		(t.Name == "" || t.Name == obj.GetName()) &&
+		(t.Namespace == "" || t.Namespace == obj.GetNamespace()) &&
+		c.selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+func (c *patch) apply(obj *unstructured.Unstructured) error {
+
+	orig, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+
+	switch {
+	case c.json6902 != nil:
+		patched, err = c.json6902.Apply(orig)
+	default:
+		// Kinds without a Go type, e.g. custom resources, have no patch strategy
+		if typed, e := scheme.Scheme.New(obj.GroupVersionKind()); e == nil {
+			patched, err = strategicpatch.StrategicMergePatch(orig, c.merge, typed)
+		} else {
+			patched, err = jsonpatch.MergePatch(orig, c.merge)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot patch %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	return obj.UnmarshalJSON(patched)
+}
+
+// Run applies the patches and image overrides to every object of renderedManifests.
+func (r *renderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+
+	out := &bytes.Buffer{}
+
+	scanner := yamlutil.NewYAMLScanner(renderedManifests.Bytes())
+
+	for scanner.Scan() {
+
+		doc, err := yaml.YAMLToJSON(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse rendered manifest: %w", err)
+		}
+
+		// Templates rendering to nothing only hold their # Source comment
+		if bytes.Equal(bytes.TrimSpace(doc), []byte("null")) {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err = obj.UnmarshalJSON(doc); err != nil {
+			return nil, fmt.Errorf("cannot decode rendered manifest: %w", err)
+		}
+
+		for _, p := range r.patches {
+			if !p.matches(obj) {
+				continue
+			}
+			if err = p.apply(obj); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(r.images) > 0 {
+			VisitContainers(obj.Object, func(container map[string]interface{}) {
+				if image, ok := container["image"].(string); ok {
+					container["image"] = overrideImage(image, r.images)
+				}
+			})
+		}
+
+		manifest, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(out, "---\n%s%s", comments(scanner.Bytes()), manifest)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan manifest: %w", err)
+	}
+
+	return out, nil
+}
+
+// comments returns the leading comment lines of doc, e.g. the # Source of the template.
+func comments(doc []byte) string {
+
+	var b strings.Builder
+
+	s := bufio.NewScanner(bytes.NewReader(doc))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "---" || line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+// VisitContainers calls fn for every container, init container and ephemeral container
+// of the pod specs anywhere in obj, e.g. of Pods, DaemonSets and CronJobs.
+func VisitContainers(obj interface{}, fn func(container map[string]interface{})) {
+
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "containers" || key == "initContainers" || key == "ephemeralContainers" {
+				if list, ok := value.([]interface{}); ok {
+					for _, c := range list {
+						if container, ok := c.(map[string]interface{}); ok {
+							fn(container)
+						}
+					}
+					continue
+				}
+			}
+			VisitContainers(value, fn)
+		}
+	case []interface{}:
+		for _, value := range v {
+			VisitContainers(value, fn)
+		}
+	}
+}
+
+// SplitImage splits an image reference into its name, tag and digest.
+func SplitImage(image string) (name, tag, digest string) {
+
+	name = image
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+
+	// A colon before the last slash separates the port of the registry
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+
+	return name, tag, digest
+}
+
+// JoinImage is the inverse of SplitImage.
+func JoinImage(name, tag, digest string) string {
+
+	image := name
+	if tag != "" {
+		image += ":" + tag
+	}
+	if digest != "" {
+		image += "@" + digest
+	}
+
+	return image
+}
+
+func overrideImage(image string, overrides []srov1beta1.SpecialResourceImageOverride) string {
+
+	name, tag, digest := SplitImage(image)
+
+	for _, o := range overrides {
+		if o.Name != name {
+			continue
+		}
+
+		if o.NewName != "" {
+			name = o.NewName
+		}
+
+		if o.Digest != "" {
+			tag, digest = "", o.Digest
+		} else if o.NewTag != "" {
+			tag, digest = o.NewTag, ""
+		}
+
+		return JoinImage(name, tag, digest)
+	}
+
+	return image
+}
@@ -0,0 +1,241 @@
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/yamlutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPostRender(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PostRender Suite")
+}
+
+const manifests = `---
+# Source: driver/templates/0000-driver-container.yaml
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: driver
+  labels:
+    app: driver
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: quay.io/vendor/init:1.0
+      containers:
+      - name: driver
+        image: quay.io/vendor/driver:1.0
+        env:
+        - name: A
+          value: a
+---
+# Source: driver/templates/empty.yaml
+---
+# Source: driver/templates/cr.yaml
+apiVersion: example.com/v1
+kind: Custom
+metadata:
+  name: custom
+spec:
+  replicas: 1
+  keep: true
+`
+
+func run(spec *srov1beta1.SpecialResourcePostRender) []*unstructured.Unstructured {
+
+	r, err := NewPostRenderer(spec)
+	Expect(err).NotTo(HaveOccurred())
+
+	out, err := r.Run(bytes.NewBufferString(manifests))
+	Expect(err).NotTo(HaveOccurred())
+
+	var objs []*unstructured.Unstructured
+
+	scanner := yamlutil.NewYAMLScanner(out.Bytes())
+	for scanner.Scan() {
+		doc, err := yaml.YAMLToJSON(scanner.Bytes())
+		Expect(err).NotTo(HaveOccurred())
+
+		obj := &unstructured.Unstructured{}
+		Expect(obj.UnmarshalJSON(doc)).To(Succeed())
+		objs = append(objs, obj)
+	}
+	Expect(scanner.Err()).NotTo(HaveOccurred())
+
+	return objs
+}
+
+func containers(obj *unstructured.Unstructured) []interface{} {
+	c, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	Expect(err).NotTo(HaveOccurred())
+	return c
+}
+
+var _ = Describe("NewPostRenderer", func() {
+	It("should return nil without patches or images", func() {
+		r, err := NewPostRenderer(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(BeNil())
+
+		r, err = NewPostRenderer(&srov1beta1.SpecialResourcePostRender{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(BeNil())
+	})
+
+	It("should reject a JSON6902 patch without target", func() {
+		_, err := NewPostRenderer(&srov1beta1.SpecialResourcePostRender{
+			Patches: []srov1beta1.SpecialResourcePatch{
+				{Type: srov1beta1.PatchTypeJSON6902, Patch: `[{"op": "remove", "path": "/spec"}]`},
+			},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject an invalid label selector", func() {
+		_, err := NewPostRenderer(&srov1beta1.SpecialResourcePostRender{
+			Patches: []srov1beta1.SpecialResourcePatch{
+				{Patch: "spec: {}", Target: &srov1beta1.SpecialResourcePatchTarget{LabelSelector: "a in b"}},
+			},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("renderer_Run", func() {
+	It("should merge a strategic merge patch into the containers of its target", func() {
+		objs := run(&srov1beta1.SpecialResourcePostRender{
+			Patches: []srov1beta1.SpecialResourcePatch{
+				{
+					Patch: `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: driver
+spec:
+  template:
+    spec:
+      priorityClassName: system-node-critical
+      tolerations:
+      - operator: Exists
+      containers:
+      - name: driver
+        resources:
+          limits:
+            memory: 1Gi
+`,
+				},
+			},
+		})
+
+		Expect(objs).To(HaveLen(2))
+
+		c := containers(objs[0])
+		Expect(c).To(HaveLen(1))
+
+		driver := c[0].(map[string]interface{})
+		Expect(driver["image"]).To(Equal("quay.io/vendor/driver:1.0"))
+		Expect(driver["env"]).To(HaveLen(1))
+		Expect(driver["resources"]).To(HaveKeyWithValue("limits", HaveKeyWithValue("memory", "1Gi")))
+
+		pc, _, _ := unstructured.NestedString(objs[0].Object, "spec", "template", "spec", "priorityClassName")
+		Expect(pc).To(Equal("system-node-critical"))
+
+		// The patch of the DaemonSet leaves the custom resource alone
+		Expect(objs[1].Object["spec"]).To(Equal(map[string]interface{}{"replicas": int64(1), "keep": true}))
+	})
+
+	It("should merge patch objects without a strategic merge schema", func() {
+		objs := run(&srov1beta1.SpecialResourcePostRender{
+			Patches: []srov1beta1.SpecialResourcePatch{
+				{
+					Patch:  "spec: {replicas: 3}",
+					Target: &srov1beta1.SpecialResourcePatchTarget{Kind: "Custom"},
+				},
+			},
+		})
+
+		Expect(objs[1].Object["spec"]).To(Equal(map[string]interface{}{"replicas": int64(3), "keep": true}))
+	})
+
+	It("should apply JSON6902 patches to the objects matching the label selector", func() {
+		objs := run(&srov1beta1.SpecialResourcePostRender{
+			Patches: []srov1beta1.SpecialResourcePatch{
+				{
+					Type:   srov1beta1.PatchTypeJSON6902,
+					Patch:  `[{"op": "add", "path": "/spec/template/spec/containers/0/env/-", "value": {"name": "B", "value": "b"}}]`,
+					Target: &srov1beta1.SpecialResourcePatchTarget{LabelSelector: "app=driver"},
+				},
+			},
+		})
+
+		env := containers(objs[0])[0].(map[string]interface{})["env"]
+		Expect(env).To(HaveLen(2))
+	})
+
+	It("should return an error when a JSON6902 patch does not apply", func() {
+		r, err := NewPostRenderer(&srov1beta1.SpecialResourcePostRender{
+			Patches: []srov1beta1.SpecialResourcePatch{
+				{
+					Type:   srov1beta1.PatchTypeJSON6902,
+					Patch:  `[{"op": "replace", "path": "/spec/missing/field", "value": 1}]`,
+					Target: &srov1beta1.SpecialResourcePatchTarget{Kind: "DaemonSet"},
+				},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = r.Run(bytes.NewBufferString(manifests))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should override the images of containers and init containers", func() {
+		objs := run(&srov1beta1.SpecialResourcePostRender{
+			Images: []srov1beta1.SpecialResourceImageOverride{
+				{Name: "quay.io/vendor/driver", NewName: "mirror.corp/vendor/driver", NewTag: "1.1"},
+				{Name: "quay.io/vendor/init", Digest: "sha256:0123"},
+			},
+		})
+
+		Expect(containers(objs[0])[0]).To(HaveKeyWithValue("image", "mirror.corp/vendor/driver:1.1"))
+
+		init, _, _ := unstructured.NestedSlice(objs[0].Object, "spec", "template", "spec", "initContainers")
+		Expect(init[0]).To(HaveKeyWithValue("image", "quay.io/vendor/init@sha256:0123"))
+	})
+
+	It("should keep the source comments of the templates", func() {
+		r, err := NewPostRenderer(&srov1beta1.SpecialResourcePostRender{
+			Images: []srov1beta1.SpecialResourceImageOverride{{Name: "none"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		out, err := r.Run(bytes.NewBufferString(manifests))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("# Source: driver/templates/0000-driver-container.yaml\n"))
+		Expect(out.String()).NotTo(ContainSubstring("empty.yaml"))
+	})
+})
+
+var _ = Describe("SplitImage", func() {
+	DescribeTable("should split image references",
+		func(image, name, tag, digest string) {
+			n, t, d := SplitImage(image)
+			Expect([]string{n, t, d}).To(Equal([]string{name, tag, digest}))
+			Expect(JoinImage(n, t, d)).To(Equal(image))
+		},
+		Entry("name only", "busybox", "busybox", "", ""),
+		Entry("tag", "quay.io/a/b:1.0", "quay.io/a/b", "1.0", ""),
+		Entry("registry port", "registry:5000/a/b", "registry:5000/a/b", "", ""),
+		Entry("port and tag", "registry:5000/a/b:1.0", "registry:5000/a/b", "1.0", ""),
+		Entry("digest", "quay.io/a/b@sha256:abc", "quay.io/a/b", "", "sha256:abc"),
+		Entry("tag and digest", "quay.io/a/b:1.0@sha256:abc", "quay.io/a/b", "1.0", "sha256:abc"),
+	)
+})
@@ -42,6 +42,25 @@ func NewProxyAPI(kubeClient clients.ClientsInterface) ProxyAPI {
 	}
 }
 
+// NewStaticProxyAPI returns a ProxyAPI setting up the objects with config instead of
+// the cluster-wide proxy configuration, e.g. to render charts without a cluster.
+func NewStaticProxyAPI(config Configuration) ProxyAPI {
+	return &staticProxy{
+		proxy: proxy{
+			log:    zap.New(zap.UseDevMode(true)).WithName(utils.Print("proxy", utils.Green)),
+			config: config,
+		},
+	}
+}
+
+type staticProxy struct {
+	proxy
+}
+
+func (p *staticProxy) ClusterConfiguration(ctx context.Context) (Configuration, error) {
+	return p.config, nil
+}
+
 func (p *proxy) Setup(obj *unstructured.Unstructured) error {
 
 	if strings.Compare(obj.GetKind(), "Pod") == 0 {
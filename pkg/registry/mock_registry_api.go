@@ -50,6 +50,36 @@ func (mr *MockRegistryMockRecorder) ExtractToolkitRelease(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractToolkitRelease", reflect.TypeOf((*MockRegistry)(nil).ExtractToolkitRelease), arg0)
 }
 
+// ImageDigest mocks base method.
+func (m *MockRegistry) ImageDigest(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImageDigest", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImageDigest indicates an expected call of ImageDigest.
+func (mr *MockRegistryMockRecorder) ImageDigest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageDigest", reflect.TypeOf((*MockRegistry)(nil).ImageDigest), arg0, arg1)
+}
+
+// ImageExists mocks base method.
+func (m *MockRegistry) ImageExists(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImageExists", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImageExists indicates an expected call of ImageExists.
+func (mr *MockRegistryMockRecorder) ImageExists(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageExists", reflect.TypeOf((*MockRegistry)(nil).ImageExists), arg0, arg1)
+}
+
 // LastLayer mocks base method.
 func (m *MockRegistry) LastLayer(arg0 context.Context, arg1 string) (v1.Layer, error) {
 	m.ctrl.T.Helper()
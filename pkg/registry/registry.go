@@ -7,12 +7,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-containerregistry/pkg/crane"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
 	"github.com/pkg/errors"
@@ -46,6 +48,8 @@ type Registry interface {
 	LastLayer(context.Context, string) (v1.Layer, error)
 	ExtractToolkitRelease(v1.Layer) (DriverToolkitEntry, error)
 	ReleaseManifests(v1.Layer) (string, string, error)
+	ImageExists(context.Context, string) (bool, error)
+	ImageDigest(context.Context, string) (string, error)
 }
 
 func NewRegistry(kubeClient clients.ClientsInterface) Registry {
@@ -120,6 +124,38 @@ func (r *registry) LastLayer(ctx context.Context, entry string) (v1.Layer, error
 	return crane.PullLayer(repo + "@" + digest)
 }
 
+// ImageExists returns true if the manifest of the image, referenced by tag or
+// digest, can be found in its registry.
+func (r *registry) ImageExists(ctx context.Context, image string) (bool, error) {
+	digest, err := r.ImageDigest(ctx, image)
+	if err != nil {
+		return false, err
+	}
+
+	return digest != "", nil
+}
+
+// ImageDigest returns the digest of the manifest the image, referenced by tag or
+// digest, resolves to in its registry, or an empty digest if it cannot be found.
+func (r *registry) ImageDigest(ctx context.Context, image string) (string, error) {
+	if err := r.writeImageRegistryCredentials(ctx); err != nil {
+		return "", err
+	}
+
+	digest, err := crane.Digest(image, crane.WithContext(ctx))
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			r.log.Info("Image not found", "image", image)
+			return "", nil
+		}
+		return "", fmt.Errorf("cannot get digest of image %s: %w", image, err)
+	}
+
+	r.log.Info("Image found", "image", image, "digest", digest)
+	return digest, nil
+}
+
 func (r *registry) ExtractToolkitRelease(layer v1.Layer) (DriverToolkitEntry, error) {
 	var dtk DriverToolkitEntry
 
@@ -0,0 +1,277 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	srov1 "github.com/openshift-psap/special-resource-operator/api/v1"
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
+	"github.com/openshift-psap/special-resource-operator/pkg/proxy"
+	"github.com/openshift-psap/special-resource-operator/pkg/registry"
+	"github.com/openshift-psap/special-resource-operator/pkg/upgrade"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+const nfd = "feature.node.kubernetes.io/"
+
+var errOffline = errors.New("the registry is not available without a cluster")
+
+// Cluster describes the cluster a chart is rendered for, without contacting it.
+type Cluster struct {
+	// Platform is OCP or K8S, OCP by default.
+	Platform string `json:"platform,omitempty"`
+
+	// ClusterVersion is the version of OpenShift, e.g. 4.9.8.
+	ClusterVersion string `json:"clusterVersion,omitempty"`
+
+	// KubeVersion is the version of Kubernetes the charts see, Helm's default if empty.
+	KubeVersion string `json:"kubeVersion,omitempty"`
+
+	// APIVersions are the API versions served in addition to Helm's default ones.
+	APIVersions []string `json:"apiVersions,omitempty"`
+
+	OSImageURL     string              `json:"osImageURL,omitempty"`
+	PushSecretName string              `json:"pushSecretName,omitempty"`
+	Proxy          proxy.Configuration `json:"proxy,omitempty"`
+
+	Nodes []Node `json:"nodes"`
+}
+
+// Node describes a node of the cluster, its NFD labels are derived from its kernel and
+// operating system.
+type Node struct {
+	Name string `json:"name,omitempty"`
+
+	// Labels are added to the NFD labels, and override them.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	KernelFullVersion string `json:"kernelFullVersion"`
+
+	// OSRelease is the ID of the operating system, rhcos by default.
+	OSRelease string `json:"osRelease,omitempty"`
+
+	// OSVersion is the VERSION_ID of the operating system, e.g. 4.9 for RHCOS.
+	OSVersion string `json:"osVersion"`
+
+	// RHELVersion is the RHEL version of RHCOS, e.g. 8.4.
+	RHELVersion string `json:"rhelVersion,omitempty"`
+
+	// DriverToolkitImage is the driver toolkit image for the kernel of the node.
+	DriverToolkitImage string `json:"driverToolkitImage,omitempty"`
+}
+
+// Load reads the description of a cluster from path.
+func Load(path string) (*Cluster, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &Cluster{}
+	if err = yaml.UnmarshalStrict(data, cluster); err != nil {
+		return nil, fmt.Errorf("cannot parse cluster %s: %w", path, err)
+	}
+
+	if cluster.Platform == "" {
+		cluster.Platform = "OCP"
+	}
+	if cluster.Platform != "OCP" && cluster.Platform != "K8S" {
+		return nil, fmt.Errorf("invalid platform %q, should be OCP or K8S", cluster.Platform)
+	}
+
+	if len(cluster.Nodes) == 0 {
+		return nil, fmt.Errorf("cluster %s has no nodes", path)
+	}
+
+	for i, node := range cluster.Nodes {
+		if node.KernelFullVersion == "" || node.OSVersion == "" {
+			return nil, fmt.Errorf("node %d: kernelFullVersion and osVersion are required", i)
+		}
+	}
+
+	return cluster, nil
+}
+
+// LoadSpecialResource reads a v1beta1 or v1 SpecialResource from path.
+func LoadSpecialResource(path string) (*srov1beta1.SpecialResource, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := metav1.TypeMeta{}
+	if err = yaml.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("cannot parse SpecialResource %s: %w", path, err)
+	}
+
+	sr := &srov1beta1.SpecialResource{}
+
+	switch meta.APIVersion {
+	case srov1beta1.GroupVersion.String():
+		err = yaml.UnmarshalStrict(data, sr)
+	case srov1.GroupVersion.String():
+		v1sr := &srov1.SpecialResource{}
+		if err = yaml.UnmarshalStrict(data, v1sr); err == nil {
+			err = v1sr.ConvertTo(sr)
+			sr.TypeMeta = metav1.TypeMeta{APIVersion: srov1beta1.GroupVersion.String(), Kind: meta.Kind}
+		}
+	default:
+		return nil, fmt.Errorf("%s is not a SpecialResource: apiVersion %q", path, meta.APIVersion)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse SpecialResource %s: %w", path, err)
+	}
+
+	if meta.Kind != "SpecialResource" {
+		return nil, fmt.Errorf("%s is not a SpecialResource: kind %q", path, meta.Kind)
+	}
+
+	return sr, nil
+}
+
+// NodeList returns the nodes matching selector, with their NFD labels.
+func (c *Cluster) NodeList(selector map[string]string) *corev1.NodeList {
+
+	nodes := &corev1.NodeList{}
+
+	for i, n := range c.Nodes {
+
+		node := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   n.Name,
+				Labels: n.labels(),
+			},
+		}
+		if node.Name == "" {
+			node.Name = fmt.Sprintf("node-%d", i)
+		}
+
+		if labels.SelectorFromSet(selector).Matches(labels.Set(node.Labels)) {
+			nodes.Items = append(nodes.Items, node)
+		}
+	}
+
+	return nodes
+}
+
+func (n *Node) labels() map[string]string {
+
+	osRelease := n.OSRelease
+	if osRelease == "" {
+		osRelease = "rhcos"
+	}
+
+	l := map[string]string{
+		nfd + "kernel-version.full":          n.KernelFullVersion,
+		nfd + "system-os_release.ID":         osRelease,
+		nfd + "system-os_release.VERSION_ID": n.OSVersion,
+	}
+
+	version := strings.SplitN(n.OSVersion, ".", 2)
+	l[nfd+"system-os_release.VERSION_ID.major"] = version[0]
+	if len(version) > 1 {
+		l[nfd+"system-os_release.VERSION_ID.minor"] = version[1]
+	}
+
+	if n.RHELVersion != "" {
+		l[nfd+"system-os_release.RHEL_VERSION"] = n.RHELVersion
+	}
+
+	for key, value := range n.Labels {
+		l[key] = value
+	}
+
+	return l
+}
+
+// ClusterVersionMajorMinor returns the major and minor version of the cluster, e.g. 4.9.
+func (c *Cluster) ClusterVersionMajorMinor() string {
+	s := strings.Split(c.ClusterVersion, ".")
+	if len(s) > 1 {
+		return s[0] + "." + s[1]
+	}
+	return s[0]
+}
+
+// ClusterUpgradeInfo returns the versions of nodes by kernel, with the driver toolkit
+// image of the nodes running the kernel.
+func (c *Cluster) ClusterUpgradeInfo(nodes *corev1.NodeList) (map[string]upgrade.NodeVersion, error) {
+
+	info, err := upgrade.NodeVersions(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range c.Nodes {
+		version, ok := info[n.KernelFullVersion]
+		if !ok || n.DriverToolkitImage == "" {
+			continue
+		}
+		version.DriverToolkit = registry.DriverToolkitEntry{
+			ImageURL:          n.DriverToolkitImage,
+			KernelFullVersion: n.KernelFullVersion,
+			OSVersion:         version.OSVersion,
+		}
+		info[n.KernelFullVersion] = version
+	}
+
+	return info, nil
+}
+
+// Capabilities returns the capabilities of the cluster the charts are rendered with.
+func (c *Cluster) Capabilities() (*chartutil.Capabilities, error) {
+
+	capabilities := &chartutil.Capabilities{
+		KubeVersion: chartutil.DefaultCapabilities.KubeVersion,
+		APIVersions: c.APIVersions,
+	}
+
+	if c.KubeVersion != "" {
+		version, err := chartutil.ParseKubeVersion(c.KubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeVersion %q: %w", c.KubeVersion, err)
+		}
+		capabilities.KubeVersion = *version
+	}
+
+	return capabilities, nil
+}
+
+// NewRegistry returns a Registry failing to reach any registry, the images are not
+// checked nor resolved without a cluster.
+func NewRegistry() registry.Registry {
+	return offlineRegistry{}
+}
+
+type offlineRegistry struct{}
+
+func (offlineRegistry) LastLayer(context.Context, string) (v1.Layer, error) {
+	return nil, errOffline
+}
+
+func (offlineRegistry) ExtractToolkitRelease(v1.Layer) (registry.DriverToolkitEntry, error) {
+	return registry.DriverToolkitEntry{}, errOffline
+}
+
+func (offlineRegistry) ReleaseManifests(v1.Layer) (string, string, error) {
+	return "", "", errOffline
+}
+
+func (offlineRegistry) ImageExists(context.Context, string) (bool, error) {
+	return false, errOffline
+}
+
+func (offlineRegistry) ImageDigest(context.Context, string) (string, error) {
+	return "", errOffline
+}
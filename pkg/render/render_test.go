@@ -0,0 +1,164 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRender(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Render Suite")
+}
+
+func file(content string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "file.yaml")
+	ExpectWithOffset(1, os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	return path
+}
+
+const cluster = `
+clusterVersion: 4.9.8
+proxy:
+  httpProxy: http://proxy.corp:3128
+nodes:
+- name: worker-0
+  kernelFullVersion: 4.18.0-305.25.1.el8_4.x86_64
+  osVersion: "4.9"
+  rhelVersion: "8.4"
+  driverToolkitImage: quay.io/openshift-release-dev/dtk@sha256:1111
+  labels:
+    node-role.kubernetes.io/worker: ""
+- name: master-0
+  kernelFullVersion: 4.18.0-305.28.1.el8_4.x86_64
+  osVersion: "4.9"
+  rhelVersion: "8.4"
+`
+
+var _ = Describe("Load", func() {
+
+	It("should load the cluster with its defaults", func() {
+		c, err := Load(file(cluster))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Platform).To(Equal("OCP"))
+		Expect(c.ClusterVersionMajorMinor()).To(Equal("4.9"))
+		Expect(c.Proxy.HttpProxy).To(Equal("http://proxy.corp:3128"))
+		Expect(c.Nodes).To(HaveLen(2))
+	})
+
+	DescribeTable("should reject an invalid cluster",
+		func(content string) {
+			_, err := Load(file(content))
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("unknown field", "nodes: []\nkernel: 4.18\n"),
+		Entry("no nodes", "clusterVersion: 4.9.8\n"),
+		Entry("invalid platform", "platform: EKS\nnodes:\n- kernelFullVersion: 4.18\n  osVersion: \"4.9\"\n"),
+		Entry("no kernel", "nodes:\n- osVersion: \"4.9\"\n"),
+	)
+})
+
+var _ = Describe("Cluster", func() {
+
+	var c *Cluster
+
+	BeforeEach(func() {
+		var err error
+		c, err = Load(file(cluster))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should label the nodes like NFD", func() {
+		nodes := c.NodeList(nil)
+		Expect(nodes.Items).To(HaveLen(2))
+
+		Expect(nodes.Items[0].Name).To(Equal("worker-0"))
+		Expect(nodes.Items[0].Labels).To(Equal(map[string]string{
+			"feature.node.kubernetes.io/kernel-version.full":                "4.18.0-305.25.1.el8_4.x86_64",
+			"feature.node.kubernetes.io/system-os_release.ID":               "rhcos",
+			"feature.node.kubernetes.io/system-os_release.VERSION_ID":       "4.9",
+			"feature.node.kubernetes.io/system-os_release.VERSION_ID.major": "4",
+			"feature.node.kubernetes.io/system-os_release.VERSION_ID.minor": "9",
+			"feature.node.kubernetes.io/system-os_release.RHEL_VERSION":     "8.4",
+			"node-role.kubernetes.io/worker":                                "",
+		}))
+	})
+
+	It("should select the nodes", func() {
+		nodes := c.NodeList(map[string]string{"node-role.kubernetes.io/worker": ""})
+		Expect(nodes.Items).To(HaveLen(1))
+		Expect(nodes.Items[0].Name).To(Equal("worker-0"))
+	})
+
+	It("should set the driver toolkit image by kernel", func() {
+		info, err := c.ClusterUpgradeInfo(c.NodeList(nil))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(info).To(HaveLen(2))
+
+		worker := info["4.18.0-305.25.1.el8_4.x86_64"]
+		Expect(worker.OSVersion).To(Equal("8.4"))
+		Expect(worker.ClusterVersion).To(Equal("4.9"))
+		Expect(worker.DriverToolkit.ImageURL).To(Equal("quay.io/openshift-release-dev/dtk@sha256:1111"))
+		Expect(worker.DriverToolkit.KernelFullVersion).To(Equal("4.18.0-305.25.1.el8_4.x86_64"))
+
+		Expect(info["4.18.0-305.28.1.el8_4.x86_64"].DriverToolkit.ImageURL).To(BeEmpty())
+	})
+
+	It("should parse the Kubernetes version", func() {
+		c.KubeVersion = "v1.22.3"
+		c.APIVersions = []string{"build.openshift.io/v1"}
+
+		capabilities, err := c.Capabilities()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(capabilities.KubeVersion.Minor).To(Equal("22"))
+		Expect(capabilities.APIVersions.Has("build.openshift.io/v1")).To(BeTrue())
+
+		c.KubeVersion = "latest"
+		_, err = c.Capabilities()
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("LoadSpecialResource", func() {
+
+	It("should load a v1beta1 SpecialResource", func() {
+		sr, err := LoadSpecialResource(file(`
+apiVersion: sro.openshift.io/v1beta1
+kind: SpecialResource
+metadata:
+  name: simple-kmod
+spec:
+  namespace: simple-kmod
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sr.Name).To(Equal("simple-kmod"))
+		Expect(sr.Spec.Namespace).To(Equal("simple-kmod"))
+	})
+
+	It("should convert a v1 SpecialResource", func() {
+		sr, err := LoadSpecialResource(file(`
+apiVersion: sro.openshift.io/v1
+kind: SpecialResource
+metadata:
+  name: simple-kmod
+spec:
+  namespace: simple-kmod
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sr.APIVersion).To(Equal("sro.openshift.io/v1beta1"))
+		Expect(sr.Spec.Namespace).To(Equal("simple-kmod"))
+	})
+
+	It("should reject another object", func() {
+		_, err := LoadSpecialResource(file("apiVersion: v1\nkind: ConfigMap\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
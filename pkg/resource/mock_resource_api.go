@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	v1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -36,15 +37,44 @@ func (m *MockCreator) EXPECT() *MockCreatorMockRecorder {
 }
 
 // CreateFromYAML mocks base method.
-func (m *MockCreator) CreateFromYAML(arg0 context.Context, arg1 []byte, arg2 bool, arg3 v1.Object, arg4, arg5 string, arg6 map[string]string, arg7, arg8 string) error {
+func (m *MockCreator) CreateFromYAML(arg0 context.Context, arg1 []byte, arg2 bool, arg3 v1.Object, arg4, arg5 string, arg6 map[string]string, arg7, arg8 string, arg9 *Drivers) (*Result, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateFromYAML", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CreateFromYAML", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
+	ret0, _ := ret[0].(*Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateFromYAML indicates an expected call of CreateFromYAML.
-func (mr *MockCreatorMockRecorder) CreateFromYAML(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8 interface{}) *gomock.Call {
+func (mr *MockCreatorMockRecorder) CreateFromYAML(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFromYAML", reflect.TypeOf((*MockCreator)(nil).CreateFromYAML), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFromYAML", reflect.TypeOf((*MockCreator)(nil).CreateFromYAML), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
+}
+
+// Forget mocks base method.
+func (m *MockCreator) Forget(arg0 v1.Object) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Forget", arg0)
+}
+
+// Forget indicates an expected call of Forget.
+func (mr *MockCreatorMockRecorder) Forget(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forget", reflect.TypeOf((*MockCreator)(nil).Forget), arg0)
+}
+
+// PrepareDrivers mocks base method.
+func (m *MockCreator) PrepareDrivers(arg0 context.Context, arg1 v1.Object, arg2 []Manifest) (*Drivers, []v1beta1.DriverImageStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrepareDrivers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*Drivers)
+	ret1, _ := ret[1].([]v1beta1.DriverImageStatus)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PrepareDrivers indicates an expected call of PrepareDrivers.
+func (mr *MockCreatorMockRecorder) PrepareDrivers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareDrivers", reflect.TypeOf((*MockCreator)(nil).PrepareDrivers), arg0, arg1, arg2)
 }
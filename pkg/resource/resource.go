@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -18,14 +20,22 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/yaml"
 
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/internal/resourcehelper"
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	"github.com/openshift-psap/special-resource-operator/pkg/drift"
+	"github.com/openshift-psap/special-resource-operator/pkg/dryrun"
 	"github.com/openshift-psap/special-resource-operator/pkg/filter"
+	"github.com/openshift-psap/special-resource-operator/pkg/imagepolicy"
 	"github.com/openshift-psap/special-resource-operator/pkg/kernel"
 	"github.com/openshift-psap/special-resource-operator/pkg/lifecycle"
+	"github.com/openshift-psap/special-resource-operator/pkg/maintenance"
 	"github.com/openshift-psap/special-resource-operator/pkg/metrics"
 	"github.com/openshift-psap/special-resource-operator/pkg/poll"
 	"github.com/openshift-psap/special-resource-operator/pkg/proxy"
+	"github.com/openshift-psap/special-resource-operator/pkg/registry"
+	"github.com/openshift-psap/special-resource-operator/pkg/signing"
+	"github.com/openshift-psap/special-resource-operator/pkg/state"
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
 	"github.com/openshift-psap/special-resource-operator/pkg/yamlutil"
 )
@@ -34,13 +44,86 @@ type resourceCallbacks map[string]func(obj *unstructured.Unstructured, sr interf
 
 var (
 	customCallback = make(resourceCallbacks)
-	UpdateVendor   string
+
+	errSkipBuild = errors.New("driver container build skipped")
 )
 
+// RolloutTokenAnnotation records the rollout token of the SpecialResource on the
+// objects it owns and on the pod templates of its workloads.
+const RolloutTokenAnnotation = "specialresource.openshift.io/rollout-token"
+
 //go:generate mockgen -source=resource.go -package=resource -destination=mock_resource_api.go
 
 type Creator interface {
-	CreateFromYAML(context.Context, []byte, bool, v1.Object, string, string, map[string]string, string, string) error
+	CreateFromYAML(context.Context, []byte, bool, v1.Object, string, string, map[string]string, string, string, *Drivers) (*Result, error)
+	PrepareDrivers(context.Context, v1.Object, []Manifest) (*Drivers, []srov1beta1.DriverImageStatus, error)
+	Forget(v1.Object)
+}
+
+// DriverBuilds tells, by vendor, whether the build objects of the driver
+// containers of a kernel are run. The build objects of the vendors missing
+// from it are run if the status of the owner records their images missing,
+// e.g. after the Pods of their DaemonSet went into ImagePullBackOff.
+type DriverBuilds map[string]bool
+
+// Drivers are the decisions about the driver containers taken for all the states
+// of a chart before any of them is created.
+type Drivers struct {
+	// Builds are the builds of the driver containers by kernel
+	Builds map[string]DriverBuilds
+
+	// Signed are the vendors whose driver containers are signed by a
+	// BuildConfig of the chart, their DaemonSets use the signed images
+	Signed map[string]bool
+
+	// MissingSince are, by kernel and vendor, the times the driver images
+	// were first found missing, the BuildRuns that finished before are run
+	// again
+	MissingSince map[string]map[string]time.Time
+}
+
+// Manifest are the objects of a state rendered for a kernel.
+type Manifest struct {
+	State             string
+	KernelFullVersion string
+	YAML              []byte
+}
+
+// Result is what a CreateFromYAML call found and did.
+type Result struct {
+	// ResolvedImages are the images resolved by the image policy
+	ResolvedImages []srov1beta1.ResolvedImageStatus
+
+	// PendingChanges are the changes deferred to the next maintenance window
+	PendingChanges []srov1beta1.PendingChangeStatus
+
+	// DaemonSets are the DaemonSets created or updated, the nodes are
+	// labeled with a state according to the readiness of its DaemonSets
+	DaemonSets []types.NamespacedName
+
+	// Drift are the objects whose live fields differ from the chart
+	Drift []srov1beta1.DriftStatus
+
+	// DryRunChanges are the objects that would have been created or updated
+	// in dry-run mode
+	DryRunChanges []dryrun.Change
+
+	// DriverImages are the driver images the Pods of their DaemonSet could
+	// not pull, or pulled after they went missing
+	DriverImages []srov1beta1.DriverImageStatus
+}
+
+// Add appends the results of other to r.
+func (r *Result) Add(other *Result) {
+	if other == nil {
+		return
+	}
+	r.ResolvedImages = append(r.ResolvedImages, other.ResolvedImages...)
+	r.PendingChanges = append(r.PendingChanges, other.PendingChanges...)
+	r.DaemonSets = append(r.DaemonSets, other.DaemonSets...)
+	r.Drift = append(r.Drift, other.Drift...)
+	r.DryRunChanges = append(r.DryRunChanges, other.DryRunChanges...)
+	r.DriverImages = append(r.DriverImages, other.DriverImages...)
 }
 
 type creator struct {
@@ -53,6 +136,43 @@ type creator struct {
 	proxyAPI      proxy.ProxyAPI
 	scheme        *runtime.Scheme
 	helper        resourcehelper.Helper
+	registry      registry.Registry
+
+	// imagePolicy is the operator-wide image policy
+	imagePolicy *srov1beta1.SpecialResourceImagePolicy
+
+	// digests are the image digests pinned by the image policies
+	digests *digestCache
+}
+
+// request is a single CreateFromYAML call, the creator is shared by the
+// reconciliations of all the SpecialResources.
+type request struct {
+	owner v1.Object
+
+	// dryRun is true if the owner is reconciled in dry-run mode, the objects
+	// are only created and updated in server dry-run mode
+	dryRun bool
+
+	// deferDriverChanges is true outside of the owner's maintenance windows
+	deferDriverChanges bool
+
+	// kernelFullVersion is the kernel the objects are created for
+	kernelFullVersion string
+
+	// builds are the vendors whose build objects are run
+	builds DriverBuilds
+
+	// missingSince are the times the driver images of the vendors went missing
+	missingSince map[string]time.Time
+
+	// signed are the vendors whose driver containers are signed
+	signed map[string]bool
+
+	// images are the images of the objects resolved by the image policies
+	images map[*unstructured.Unstructured][]srov1beta1.ResolvedImageStatus
+
+	result Result
 }
 
 func NewCreator(
@@ -64,6 +184,8 @@ func NewCreator(
 	lc lifecycle.Lifecycle,
 	proxyAPI proxy.ProxyAPI,
 	resHelper resourcehelper.Helper,
+	reg registry.Registry,
+	imagePolicy *srov1beta1.SpecialResourceImagePolicy,
 ) Creator {
 	return &creator{
 		kubeClient:    kubeClient,
@@ -75,17 +197,20 @@ func NewCreator(
 		scheme:        scheme,
 		proxyAPI:      proxyAPI,
 		helper:        resHelper,
+		registry:      reg,
+		imagePolicy:   imagePolicy,
+		digests:       &digestCache{owners: make(map[types.UID]*ownerDigests)},
 	}
 }
 
-func (c *creator) AfterCRUD(ctx context.Context, obj *unstructured.Unstructured, namespace string) error {
+func (c *creator) AfterCRUD(ctx context.Context, req *request, obj *unstructured.Unstructured, namespace string) error {
 
 	annotations := obj.GetAnnotations()
 	clients.Namespace = namespace
 
 	if state, found := annotations["specialresource.openshift.io/state"]; found && state == "driver-container" {
 		c.log.Info("specialresource.openshift.io/state")
-		if err := c.checkForImagePullBackOff(ctx, obj, namespace); err != nil {
+		if err := c.checkForImagePullBackOff(ctx, req, obj, namespace); err != nil {
 			return fmt.Errorf("cannot check for ImagePullBackOff: %w", err)
 		}
 	}
@@ -130,38 +255,880 @@ func (c *creator) CreateFromYAML(
 	namespace string,
 	nodeSelector map[string]string,
 	kernelFullVersion string,
-	operatingSystemMajorMinor string) error {
+	operatingSystemMajorMinor string,
+	drivers *Drivers) (*Result, error) {
+
+	req := &request{owner: owner, dryRun: dryRun(owner), kernelFullVersion: kernelFullVersion}
+	if drivers != nil {
+		req.builds = drivers.Builds[kernelFullVersion]
+		req.missingSince = drivers.MissingSince[kernelFullVersion]
+		req.signed = drivers.Signed
+	}
 
-	scanner := yamlutil.NewYAMLScanner(yamlFile)
+	objs, err := decodeObjects(yamlFile)
+	if err != nil {
+		return &req.result, err
+	}
 
-	for scanner.Scan() {
+	if objs, err = c.prepareSigning(objs, owner, req.signed); err != nil {
+		return &req.result, err
+	}
 
-		yamlSpec := scanner.Bytes()
+	if req.images, err = c.resolveImages(ctx, objs, owner); err != nil {
+		return &req.result, err
+	}
+
+	// The token changes the hash of every object, the workloads roll out
+	// their pods and the build objects are recreated
+	token, rollout := rolloutToken(owner)
+
+	// Outside of the maintenance windows the driver DaemonSets are not
+	// updated and the rebuilds of a rollout wait for the next window. A dry
+	// run shows the changes the next window would apply.
+	req.deferDriverChanges = !req.dryRun && !maintenanceWindowOpen(owner)
+	if rollout && req.deferDriverChanges {
+		c.log.Info("Deferring the rebuild of the driver containers to the next maintenance window", "token", token)
+		req.deferChange("SpecialResource", "", owner.GetName(), srov1beta1.PendingRebuild)
+	}
+	if token != "" {
+		for _, obj := range objs {
+			if err = stampRolloutToken(obj, token); err != nil {
+				return &req.result, err
+			}
+		}
+	}
+
+	// The nodes are upgraded by the controller, the DaemonSet must not
+	// replace its pods on its own
+	if onDeleteUpgrade(owner) {
+		for _, obj := range objs {
+			if err = setOnDeleteUpdateStrategy(obj); err != nil {
+				return &req.result, err
+			}
+		}
+	}
+
+	// The nodes are tainted until the states are ready, the Pods of the
+	// states must run on them nonetheless
+	if notReadyTaint(owner) {
+		for _, obj := range objs {
+			if err = addNotReadyToleration(obj, owner.GetName()); err != nil {
+				return &req.result, err
+			}
+		}
+	}
+
+	affine := c.affineObjects(objs)
+
+	for _, obj := range objs {
 
 		err := c.createObjFromYAML(
 			ctx,
-			yamlSpec,
+			req,
+			obj,
+			affine,
 			releaseInstalled,
-			owner,
 			name,
 			namespace,
 			nodeSelector,
 			kernelFullVersion,
 			operatingSystemMajorMinor)
 		if err != nil {
-			return err
+			return &req.result, err
+		}
+	}
+
+	return &req.result, nil
+}
+
+// PrepareDrivers takes the decisions about the driver containers rendered in
+// manifests before any state is created: the driver containers of the vendors with
+// a signing BuildConfig are signed, and the build objects are run for the kernels
+// whose driver images are missing, the build states come before the states of the
+// DaemonSets using their images. The images are checked with the signing and the
+// image policy of owner applied.
+func (c *creator) PrepareDrivers(ctx context.Context, owner v1.Object, manifests []Manifest) (*Drivers, []srov1beta1.DriverImageStatus, error) {
+
+	drivers := &Drivers{
+		Builds:       make(map[string]DriverBuilds),
+		Signed:       make(map[string]bool),
+		MissingSince: make(map[string]map[string]time.Time),
+	}
+
+	decoded := make([][]*unstructured.Unstructured, 0, len(manifests))
+
+	for _, m := range manifests {
+
+		objs, err := decodeObjects(m.YAML)
+		if err != nil {
+			return nil, nil, err
+		}
+		decoded = append(decoded, objs)
+
+		for vendor := range signingVendors(objs, owner) {
+			drivers.Signed[vendor] = true
+		}
+	}
+
+	// A rollout deferred to the next maintenance window does not rebuild
+	_, rollout := rolloutToken(owner)
+	rollout = rollout && (dryRun(owner) || maintenanceWindowOpen(owner))
+
+	var images []srov1beta1.DriverImageStatus
+
+	for i, m := range manifests {
+
+		objs, err := c.prepareSigning(decoded[i], owner, drivers.Signed)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, err = c.resolveImages(ctx, objs, owner); err != nil {
+			return nil, nil, err
 		}
+
+		kernelBuilds, kernelImages := c.checkDriverImages(ctx, owner, objs, c.affineObjects(objs), m.State, m.KernelFullVersion, rollout)
+
+		if drivers.Builds[m.KernelFullVersion] == nil {
+			drivers.Builds[m.KernelFullVersion] = make(DriverBuilds)
+			drivers.MissingSince[m.KernelFullVersion] = make(map[string]time.Time)
+		}
+		for vendor, build := range kernelBuilds {
+			drivers.Builds[m.KernelFullVersion][vendor] = drivers.Builds[m.KernelFullVersion][vendor] || build
+		}
+
+		// The BuildRuns of a vendor run again if they finished before any
+		// of its images went missing
+		missing := drivers.MissingSince[m.KernelFullVersion]
+		for _, image := range kernelImages {
+			if !image.Present && image.MissingSince.After(missing[image.Vendor]) {
+				missing[image.Vendor] = image.MissingSince.Time
+			}
+		}
+		images = append(images, kernelImages...)
+	}
+
+	for kernelFullVersion, kernelBuilds := range drivers.Builds {
+		for vendor, build := range kernelBuilds {
+			if build {
+				c.log.Info("Driver image missing or rolled out, building driver-container", "vendor", vendor, "kernel", kernelFullVersion)
+			}
+		}
+	}
+
+	return drivers, images, nil
+}
+
+// driverImage returns the status of the driver image for the kernel recorded in
+// the status of the SpecialResource owning the objects.
+func driverImage(owner v1.Object, image, kernelFullVersion string) (srov1beta1.DriverImageStatus, bool) {
+	sr, ok := owner.(*srov1beta1.SpecialResource)
+	if !ok {
+		return srov1beta1.DriverImageStatus{}, false
+	}
+
+	for _, status := range sr.Status.DriverImages {
+		if status.Image == image && status.KernelFullVersion == kernelFullVersion {
+			return status, true
+		}
+	}
+
+	return srov1beta1.DriverImageStatus{}, false
+}
+
+// missingSince returns the time the driver image for the kernel was first found
+// missing: the time recorded in the status of the owner if it was missing
+// already, now otherwise.
+func missingSince(owner v1.Object, image, kernelFullVersion string) v1.Time {
+	if status, found := driverImage(owner, image, kernelFullVersion); found && !status.Present && !status.MissingSince.IsZero() {
+		return status.MissingSince
+	}
+
+	return v1.Now()
+}
+
+// vendorMissingSince returns the latest time a driver image of the vendor for the
+// kernel was found missing according to the status of the owner, the zero time if
+// none is missing.
+func vendorMissingSince(owner v1.Object, vendor, kernelFullVersion string) time.Time {
+	sr, ok := owner.(*srov1beta1.SpecialResource)
+	if !ok {
+		return time.Time{}
+	}
+
+	var since time.Time
+	for _, status := range sr.Status.DriverImages {
+		if status.Vendor == vendor && status.KernelFullVersion == kernelFullVersion && !status.Present && status.MissingSince.After(since) {
+			since = status.MissingSince.Time
+		}
+	}
+
+	return since
+}
+
+// containerImages returns the images of the containers of the workload obj.
+func containerImages(obj *unstructured.Unstructured) []string {
+
+	containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	if err != nil {
+		utils.WarnOnError(err)
+		return nil
+	}
+
+	images := make([]string, 0, len(containers))
+	for _, container := range containers {
+		if c, ok := container.(map[string]interface{}); ok {
+			if image, _, _ := unstructured.NestedString(c, "image"); image != "" {
+				images = append(images, image)
+			}
+		}
+	}
+
+	return images
+}
+
+// recordDriverImages records in the result whether the Pods of the driver DaemonSet
+// obj pulled its images, the pulled images are only recorded if the status of the
+// owner has them missing.
+func (req *request) recordDriverImages(obj *unstructured.Unstructured, pulled bool) {
+
+	vendor := obj.GetAnnotations()["specialresource.openshift.io/driver-container-vendor"]
+
+	for _, image := range containerImages(obj) {
+
+		recorded, found := driverImage(req.owner, image, req.kernelFullVersion)
+		if pulled && (!found || recorded.Present) {
+			continue
+		}
+
+		status := srov1beta1.DriverImageStatus{
+			Image:             image,
+			State:             state.CurrentName,
+			KernelFullVersion: req.kernelFullVersion,
+			Vendor:            vendor,
+			Present:           pulled,
+			Build:             !pulled,
+			LastCheckTime:     v1.Now(),
+		}
+		if !pulled {
+			status.MissingSince = missingSince(req.owner, image, req.kernelFullVersion)
+		}
+
+		req.result.DriverImages = append(req.result.DriverImages, status)
+	}
+}
+
+// decodeObjects decodes the objects of the YAML documents in yamlFile.
+func decodeObjects(yamlFile []byte) ([]*unstructured.Unstructured, error) {
+
+	scanner := yamlutil.NewYAMLScanner(yamlFile)
+
+	objs := make([]*unstructured.Unstructured, 0)
+
+	for scanner.Scan() {
+
+		obj, err := decodeYAML(scanner.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		objs = append(objs, obj)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("failed to scan manifest: %w", err)
+		return nil, fmt.Errorf("failed to scan manifest: %w", err)
+	}
+
+	return objs, nil
+}
+
+// affineObjects returns the kernel affine objects of objs. They are renamed per
+// kernel, the ones rendered together are remembered so that references between
+// them stay consistent.
+func (c *creator) affineObjects(objs []*unstructured.Unstructured) kernel.AffineObjects {
+	affine := make(kernel.AffineObjects)
+	for _, obj := range objs {
+		if c.kernelData.IsObjectAffine(obj) {
+			affine.Add(obj.GetKind(), obj.GetName())
+		}
+	}
+	return affine
+}
+
+// signingVendors returns the vendors of the driver container BuildConfigs of objs
+// annotated with signing.BuildAnnotation, if the SpecialResource owning them
+// enables signing.
+func signingVendors(objs []*unstructured.Unstructured, owner v1.Object) map[string]bool {
+
+	vendors := make(map[string]bool)
+
+	if sr, ok := owner.(*srov1beta1.SpecialResource); !ok || sr.Spec.Signing == nil {
+		return vendors
+	}
+
+	for _, obj := range objs {
+		annotations := obj.GetAnnotations()
+		vendor, found := annotations["specialresource.openshift.io/driver-container-vendor"]
+		if obj.GetKind() == "BuildConfig" && found && annotations[signing.BuildAnnotation] == "true" {
+			vendors[vendor] = true
+		}
+	}
+
+	return vendors
+}
+
+// prepareSigning binds the signing BuildConfig injected into a state to every
+// driver container BuildConfig of the state annotated with
+// signing.BuildAnnotation, and makes the driver containers of the signed vendors
+// use the signed images. The signing keys are only referenced by name, they are
+// never read here.
+func (c *creator) prepareSigning(objs []*unstructured.Unstructured, owner v1.Object, signed map[string]bool) ([]*unstructured.Unstructured, error) {
+
+	var spec *srov1beta1.SpecialResourceSigning
+	if sr, ok := owner.(*srov1beta1.SpecialResource); ok {
+		spec = sr.Spec.Signing
+	}
+
+	var template *unstructured.Unstructured
+	for _, obj := range objs {
+		if _, found := obj.GetAnnotations()[signing.Annotation]; found {
+			template = obj
+		}
+	}
+
+	if spec == nil {
+		if template != nil {
+			return nil, errors.New("signing BuildConfig found but the SpecialResource does not enable signing")
+		}
+		return objs, nil
+	}
+
+	prepared := make([]*unstructured.Unstructured, 0, len(objs))
+
+	for _, obj := range objs {
+
+		if obj == template {
+			continue
+		}
+
+		prepared = append(prepared, obj)
+
+		vendor, found := obj.GetAnnotations()["specialresource.openshift.io/driver-container-vendor"]
+		if !found {
+			continue
+		}
+
+		switch obj.GetKind() {
+		case "DaemonSet":
+			// Without a signing build the signed images do not exist
+			if !signed[vendor] {
+				continue
+			}
+			if err := c.useSignedImages(obj, spec); err != nil {
+				return nil, err
+			}
+
+		case "BuildConfig":
+			if template == nil || obj.GetAnnotations()[signing.BuildAnnotation] != "true" {
+				continue
+			}
+
+			// The modules can only be signed once the build is finished
+			annotations := obj.GetAnnotations()
+			annotations["specialresource.openshift.io/wait"] = "true"
+			obj.SetAnnotations(annotations)
+
+			signer := template.DeepCopy()
+			if err := signing.Bind(signer, obj, spec); err != nil {
+				return nil, fmt.Errorf("cannot sign the driver container built by %s: %w", obj.GetName(), err)
+			}
+
+			c.log.Info("Signing driver container", "BuildConfig", obj.GetName(), "Signer", signer.GetName())
+			prepared = append(prepared, signer)
+		}
+	}
+
+	return prepared, nil
+}
+
+func (c *creator) useSignedImages(obj *unstructured.Unstructured, spec *srov1beta1.SpecialResourceSigning) error {
+
+	containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	if err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		container, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		image, _, err := unstructured.NestedString(container, "image")
+		if err != nil || image == "" {
+			continue
+		}
+
+		container["image"] = signing.SignedImage(image, spec)
+	}
+
+	return unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers")
+}
+
+// resolveImages applies the image policy of the SpecialResource owning objs, then the
+// operator-wide one, to the containers of objs and returns the images resolved per
+// object.
+func (c *creator) resolveImages(ctx context.Context, objs []*unstructured.Unstructured, owner v1.Object) (map[*unstructured.Unstructured][]srov1beta1.ResolvedImageStatus, error) {
+
+	var policy *srov1beta1.SpecialResourceImagePolicy
+	if sr, ok := owner.(*srov1beta1.SpecialResource); ok {
+		policy = sr.Spec.ImagePolicy
+	}
+
+	resolver := imagepolicy.NewResolver(c.digests.registry(c.registry, owner), policy, c.imagePolicy)
+	if resolver == nil {
+		return nil, nil
+	}
+
+	resolved := make(map[*unstructured.Unstructured][]srov1beta1.ResolvedImageStatus)
+
+	for _, obj := range objs {
+		images, err := resolver.Apply(ctx, obj)
+		if err != nil {
+			return nil, fmt.Errorf("cannot apply image policy: %w", err)
+		}
+		if len(images) > 0 {
+			resolved[obj] = images
+		}
+	}
+
+	return resolved, nil
+}
+
+// digestCache holds the image digests resolved for the objects of an owner
+// until its generation changes, the registries are not queried for every image
+// on every reconciliation.
+type digestCache struct {
+	mu     sync.Mutex
+	owners map[types.UID]*ownerDigests
+}
+
+type ownerDigests struct {
+	generation int64
+	digests    map[string]string
+}
+
+// registry returns reg with the digests of the images cached for the current
+// generation of owner.
+func (d *digestCache) registry(reg registry.Registry, owner v1.Object) registry.Registry {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cached, found := d.owners[owner.GetUID()]
+	if !found || cached.generation != owner.GetGeneration() {
+		cached = &ownerDigests{generation: owner.GetGeneration(), digests: make(map[string]string)}
+		d.owners[owner.GetUID()] = cached
+	}
+
+	return &cachedRegistry{Registry: reg, mu: &d.mu, digests: cached.digests}
+}
+
+// forget drops the digests cached for owner.
+func (d *digestCache) forget(owner v1.Object) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.owners, owner.GetUID())
+}
+
+// cachedRegistry caches the digests returned by ImageDigest.
+type cachedRegistry struct {
+	registry.Registry
+
+	mu      *sync.Mutex
+	digests map[string]string
+}
+
+func (r *cachedRegistry) ImageDigest(ctx context.Context, image string) (string, error) {
+
+	r.mu.Lock()
+	digest, found := r.digests[image]
+	r.mu.Unlock()
+
+	if found {
+		return digest, nil
+	}
+
+	digest, err := r.Registry.ImageDigest(ctx, image)
+	if err != nil {
+		return "", err
+	}
+
+	// Images yet to be built are looked up again until they are pushed
+	if digest != "" {
+		r.mu.Lock()
+		r.digests[image] = digest
+		r.mu.Unlock()
+	}
+
+	return digest, nil
+}
+
+// Forget drops the state kept for owner across reconciliations, the image digests
+// pinned for it, once it is deleted.
+func (c *creator) Forget(owner v1.Object) {
+	c.digests.forget(owner)
+}
+
+// deferChange records a change deferred to the next maintenance window.
+func (req *request) deferChange(kind, namespace, name, change string) {
+	req.result.PendingChanges = append(req.result.PendingChanges, srov1beta1.PendingChangeStatus{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Change:    change,
+		Since:     v1.Now(),
+	})
+}
+
+// maintenanceWindowOpen returns true if the SpecialResource owning the objects is
+// in one of its maintenance windows, or has none.
+func maintenanceWindowOpen(owner v1.Object) bool {
+	sr, ok := owner.(*srov1beta1.SpecialResource)
+	if !ok {
+		return true
+	}
+
+	// Invalid windows never open, the controller does not reconcile them
+	open, _, err := maintenance.Open(sr.Spec.MaintenanceWindows, time.Now())
+
+	return err == nil && open
+}
+
+func isDriverDaemonSet(obj *unstructured.Unstructured) bool {
+	return obj.GetKind() == "DaemonSet" && obj.GetAnnotations()["specialresource.openshift.io/state"] == "driver-container"
+}
+
+// rolloutToken returns the rollout token of the SpecialResource owning the
+// objects, and true if the states were not reconciled with it yet.
+func rolloutToken(owner v1.Object) (string, bool) {
+	sr, ok := owner.(*srov1beta1.SpecialResource)
+	if !ok {
+		return "", false
+	}
+
+	token := sr.Spec.RolloutToken
+
+	return token, token != "" && token != sr.Status.ObservedRolloutToken
+}
+
+// stampRolloutToken annotates obj, and the pod template of workloads, with token.
+func stampRolloutToken(obj *unstructured.Unstructured, token string) error {
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[RolloutTokenAnnotation] = token
+	obj.SetAnnotations(annotations)
+
+	// The pod template of Jobs is immutable
+	switch obj.GetKind() {
+	case "DaemonSet", "Deployment", "StatefulSet":
+	default:
+		return nil
+	}
+
+	if err := unstructured.SetNestedField(obj.Object, token, "spec", "template", "metadata", "annotations", RolloutTokenAnnotation); err != nil {
+		return fmt.Errorf("cannot annotate the pod template of %s %s: %w", obj.GetKind(), obj.GetName(), err)
 	}
 
 	return nil
 }
 
+// driftPolicy returns the drift policy of the SpecialResource owning the objects.
+func driftPolicy(owner v1.Object) string {
+	sr, ok := owner.(*srov1beta1.SpecialResource)
+	if !ok || sr.Spec.Drift == nil || sr.Spec.Drift.Policy == "" {
+		return srov1beta1.DriftPolicyIgnore
+	}
+	return sr.Spec.Drift.Policy
+}
+
+// checkDrift compares the fields of obj set by the chart with the live object found
+// unless the owner ignores drift, records the drifted fields with an event, and
+// returns true if they must be corrected.
+func (c *creator) checkDrift(req *request, obj, found *unstructured.Unstructured) bool {
+
+	policy := driftPolicy(req.owner)
+	if policy == srov1beta1.DriftPolicyIgnore {
+		return false
+	}
+
+	fields := drift.Diff(obj, found)
+	if len(fields) == 0 {
+		return false
+	}
+
+	correct := policy == srov1beta1.DriftPolicyCorrect
+
+	// The diff of the dry run shows the drift
+	if req.dryRun {
+		return correct
+	}
+
+	// The correction of a driver DaemonSet waits for the next maintenance window
+	corrected := correct && !(req.deferDriverChanges && isDriverDaemonSet(obj))
+
+	req.result.Drift = append(req.result.Drift, srov1beta1.DriftStatus{
+		Kind:         obj.GetKind(),
+		Namespace:    obj.GetNamespace(),
+		Name:         obj.GetName(),
+		Fields:       fields,
+		Corrected:    corrected,
+		DetectedTime: v1.Now(),
+	})
+
+	reason := "Drifted"
+	if corrected {
+		reason = "DriftCorrected"
+	}
+	message := fmt.Sprintf("%s %s/%s differs from the chart: %s", obj.GetKind(), obj.GetNamespace(), obj.GetName(), strings.Join(fields, ", "))
+
+	c.log.Info(message, "policy", policy)
+	if sr, ok := req.owner.(*srov1beta1.SpecialResource); ok {
+		c.kubeClient.Event(sr, corev1.EventTypeWarning, reason, message)
+	}
+
+	return correct
+}
+
+// dryRun returns true if the SpecialResource owning the objects is reconciled in
+// dry-run mode.
+func dryRun(owner v1.Object) bool {
+	sr, ok := owner.(*srov1beta1.SpecialResource)
+	return ok && sr.Spec.DryRun
+}
+
+// applyDryRun creates obj, or updates it if found, in server dry-run mode and
+// records the diff from found to the object returned by the API server. A rejected
+// object is recorded with its error, the next objects are still applied. The
+// objects of a namespace that does not exist yet, e.g. the namespace of the
+// SpecialResource also created in dry-run mode, are recorded as rendered.
+func (c *creator) applyDryRun(ctx context.Context, req *request, obj, found *unstructured.Unstructured) {
+
+	change := dryrun.Change{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+
+	var err error
+	switch {
+	case found != nil:
+		err = c.kubeClient.Update(ctx, obj, client.DryRunAll)
+	case c.namespaceMissing(ctx, obj.GetNamespace()):
+		c.log.Info("Namespace not created yet, not dry running", "Kind", obj.GetKind(), "Name", obj.GetName(), "Namespace", obj.GetNamespace())
+	default:
+		err = c.kubeClient.Create(ctx, obj, client.DryRunAll)
+	}
+
+	if err == nil {
+		change.Diff, err = dryrun.Diff(found, obj)
+	}
+
+	if err != nil {
+		c.log.Info("Dry run failed", "Kind", obj.GetKind(), "Name", obj.GetName(), "error", err.Error())
+		change.Error = err.Error()
+	}
+
+	if change.Diff == "" && change.Error == "" {
+		return
+	}
+
+	req.result.DryRunChanges = append(req.result.DryRunChanges, change)
+}
+
+// namespaceMissing returns true if the namespace is set and not found, the other
+// errors are left to the dry run.
+func (c *creator) namespaceMissing(ctx context.Context, namespace string) bool {
+
+	if namespace == "" {
+		return false
+	}
+
+	err := c.kubeClient.Get(ctx, types.NamespacedName{Name: namespace}, &corev1.Namespace{})
+
+	return apierrors.IsNotFound(err)
+}
+
+// notReadyTaint returns true if the SpecialResource owning the objects taints its
+// nodes until its states are ready.
+func notReadyTaint(owner v1.Object) bool {
+	sr, ok := owner.(*srov1beta1.SpecialResource)
+	return ok && sr.Spec.NotReadyTaint
+}
+
+// addNotReadyToleration adds the toleration of the not ready taint of the
+// SpecialResource sr to the spec of Pods and the pod template of workloads. Build
+// pods cannot tolerate it, the nodes are not tainted while they run.
+func addNotReadyToleration(obj *unstructured.Unstructured, sr string) error {
+
+	var path []string
+	switch obj.GetKind() {
+	case "Pod":
+		path = []string{"spec", "tolerations"}
+	case "DaemonSet", "Deployment", "StatefulSet", "Job":
+		path = []string{"spec", "template", "spec", "tolerations"}
+	default:
+		return nil
+	}
+
+	toleration := state.NotReadyToleration(sr)
+
+	tolerations, _, err := unstructured.NestedSlice(obj.Object, path...)
+	if err != nil {
+		return fmt.Errorf("cannot get the tolerations of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	for _, t := range tolerations {
+		if m, ok := t.(map[string]interface{}); ok && m["key"] == toleration.Key {
+			return nil
+		}
+	}
+
+	tolerations = append(tolerations, map[string]interface{}{
+		"key":      toleration.Key,
+		"operator": string(toleration.Operator),
+		"effect":   string(toleration.Effect),
+	})
+	if err = unstructured.SetNestedSlice(obj.Object, tolerations, path...); err != nil {
+		return fmt.Errorf("cannot set the tolerations of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	return nil
+}
+
+// onDeleteUpgrade returns true if the SpecialResource owning the objects upgrades
+// its nodes with the OnDelete upgrade strategy.
+func onDeleteUpgrade(owner v1.Object) bool {
+	sr, ok := owner.(*srov1beta1.SpecialResource)
+	return ok && sr.Spec.UpgradeStrategy != nil && sr.Spec.UpgradeStrategy.Type == srov1beta1.UpgradeStrategyOnDelete
+}
+
+// setOnDeleteUpdateStrategy sets the OnDelete update strategy of driver DaemonSets.
+func setOnDeleteUpdateStrategy(obj *unstructured.Unstructured) error {
+
+	if !isDriverDaemonSet(obj) {
+		return nil
+	}
+
+	strategy := map[string]interface{}{"type": "OnDelete"}
+	if err := unstructured.SetNestedMap(obj.Object, strategy, "spec", "updateStrategy"); err != nil {
+		return fmt.Errorf("cannot set the update strategy of DaemonSet %s: %w", obj.GetName(), err)
+	}
+
+	return nil
+}
+
+// checkDriverImages looks up the images of kernel affine DaemonSets annotated
+// with specialresource.openshift.io/check-image in their registry and returns
+// whether the build objects of their vendor are run: if an image is missing, or
+// a rollout is requested. The vendors whose images cannot be queried are left
+// out, their builds fall back to checking the DaemonSet's Pods for
+// ImagePullBackOff.
+func (c *creator) checkDriverImages(ctx context.Context, owner v1.Object, objs []*unstructured.Unstructured, affine kernel.AffineObjects, stateName, kernelFullVersion string, rollout bool) (DriverBuilds, []srov1beta1.DriverImageStatus) {
+
+	builds := make(DriverBuilds)
+	var checked []srov1beta1.DriverImageStatus
+
+objects:
+	for _, obj := range objs {
+
+		if obj.GetKind() != "DaemonSet" || !affine.Has(obj.GetKind(), obj.GetName()) {
+			continue
+		}
+
+		annotations := obj.GetAnnotations()
+		if check, found := annotations["specialresource.openshift.io/check-image"]; !found || check != "true" {
+			continue
+		}
+
+		vendor, found := annotations["specialresource.openshift.io/driver-container-vendor"]
+		if !found {
+			c.log.Info("No annotation driver-container-vendor found, not checking image", "Name", obj.GetName())
+			continue
+		}
+
+		containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		if err != nil {
+			utils.WarnOnError(err)
+			continue
+		}
+
+		images := make([]srov1beta1.DriverImageStatus, 0, len(containers))
+		build := rollout
+
+		for _, container := range containers {
+			container, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			image, _, err := unstructured.NestedString(container, "image")
+			if err != nil || image == "" {
+				continue
+			}
+
+			present, err := c.registry.ImageExists(ctx, image)
+			if err != nil {
+				utils.WarnOnError(fmt.Errorf("cannot check driver image %s, waiting for ImagePullBackOff instead: %w", image, err))
+				continue objects
+			}
+
+			build = build || !present
+
+			status := srov1beta1.DriverImageStatus{
+				Image:             image,
+				State:             stateName,
+				KernelFullVersion: kernelFullVersion,
+				Vendor:            vendor,
+				Present:           present,
+				LastCheckTime:     v1.Now(),
+			}
+			if !present {
+				status.MissingSince = missingSince(owner, image, kernelFullVersion)
+			}
+			images = append(images, status)
+		}
+
+		for i := range images {
+			images[i].Build = build
+		}
+		checked = append(checked, images...)
+
+		builds[vendor] = builds[vendor] || build
+	}
+
+	return builds, checked
+}
+
+func decodeYAML(yamlSpec []byte) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{},
+	}
+
+	jsonSpec, err := yaml.YAMLToJSON(yamlSpec)
+	if err != nil {
+		return nil, fmt.Errorf("Could not convert yaml file to json: %s: error %w", string(yamlSpec), err)
+	}
+
+	if err = obj.UnmarshalJSON(jsonSpec); err != nil {
+		return nil, fmt.Errorf("cannot unmarshall json spec, check your manifest: %s: %w", jsonSpec, err)
+	}
+
+	return obj, nil
+}
+
 // CRUD Create Update Delete Resource
-func (c *creator) CRUD(ctx context.Context, obj *unstructured.Unstructured, releaseInstalled bool, owner v1.Object, name string, namespace string) error {
+func (c *creator) CRUD(ctx context.Context, req *request, obj *unstructured.Unstructured, releaseInstalled bool, name string, namespace string) error {
 
 	var logg logr.Logger
 	if c.helper.IsNamespaced(obj.GetKind()) {
@@ -173,7 +1140,7 @@ func (c *creator) CRUD(ctx context.Context, obj *unstructured.Unstructured, rele
 	// SpecialResource is the parent, all other objects are childs and need a reference
 	// but only set the ownerreference if created by SRO do not set ownerreference per default
 	if obj.GetKind() != "SpecialResource" && obj.GetKind() != "Namespace" {
-		if err := controllerutil.SetControllerReference(owner, obj, c.scheme); err != nil {
+		if err := controllerutil.SetControllerReference(req.owner, obj, c.scheme); err != nil {
 			return err
 		}
 
@@ -184,6 +1151,10 @@ func (c *creator) CRUD(ctx context.Context, obj *unstructured.Unstructured, rele
 
 	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
 
+	if obj.GetKind() == "DaemonSet" {
+		req.result.DaemonSets = append(req.result.DaemonSets, key)
+	}
+
 	err := c.kubeClient.Get(ctx, key, found)
 
 	if apierrors.IsNotFound(err) {
@@ -208,12 +1179,17 @@ func (c *creator) CRUD(ctx context.Context, obj *unstructured.Unstructured, rele
 		}
 
 		// If we create the resource set the owner reference
-		if err = controllerutil.SetControllerReference(owner, obj, c.scheme); err != nil {
+		if err = controllerutil.SetControllerReference(req.owner, obj, c.scheme); err != nil {
 			return fmt.Errorf("could not set the owner reference: %w", err)
 		}
 
 		c.helper.SetMetaData(obj, name, namespace)
 
+		if req.dryRun {
+			c.applyDryRun(ctx, req, obj, nil)
+			return nil
+		}
+
 		if err = c.kubeClient.Create(ctx, obj); err != nil {
 			if apierrors.IsForbidden(err) {
 				return fmt.Errorf("API error: forbidden: %w", err)
@@ -244,11 +1220,18 @@ func (c *creator) CRUD(ctx context.Context, obj *unstructured.Unstructured, rele
 	if err != nil {
 		return err
 	}
-	if equal {
+	// Unchanged in the chart, the live object may have been edited nonetheless
+	if equal && !c.checkDrift(req, obj, found) {
 		logg.Info("Found, not updating, hash the same: " + found.GetKind() + "/" + found.GetName())
 		return nil
 	}
 
+	if req.deferDriverChanges && isDriverDaemonSet(obj) {
+		logg.Info("Outside of the maintenance windows, deferring the update")
+		req.deferChange(obj.GetKind(), obj.GetNamespace(), obj.GetName(), srov1beta1.PendingDaemonSetUpdate)
+		return nil
+	}
+
 	logg.Info("Found, updating")
 	required := obj.DeepCopy()
 
@@ -263,6 +1246,11 @@ func (c *creator) CRUD(ctx context.Context, obj *unstructured.Unstructured, rele
 		return fmt.Errorf("couldn't Update ResourceVersion: %w", err)
 	}
 
+	if req.dryRun {
+		c.applyDryRun(ctx, req, required, found)
+		return nil
+	}
+
 	if err = c.kubeClient.Update(ctx, required); err != nil {
 		return fmt.Errorf("couldn't Update Resource: %w", err)
 	}
@@ -270,9 +1258,10 @@ func (c *creator) CRUD(ctx context.Context, obj *unstructured.Unstructured, rele
 	return nil
 }
 
-func (c *creator) checkForImagePullBackOff(ctx context.Context, obj *unstructured.Unstructured, namespace string) error {
+func (c *creator) checkForImagePullBackOff(ctx context.Context, req *request, obj *unstructured.Unstructured, namespace string) error {
 
 	if err := c.pollActions.ForDaemonSet(ctx, obj); err == nil {
+		req.recordDriverImages(obj, true)
 		return nil
 	}
 
@@ -330,13 +1319,12 @@ func (c *creator) checkForImagePullBackOff(ctx context.Context, obj *unstructure
 		if reason == "ImagePullBackOff" || reason == "ErrImagePull" {
 			annotations := obj.GetAnnotations()
 			if vendor, ok := annotations["specialresource.openshift.io/driver-container-vendor"]; ok {
-				UpdateVendor = vendor
-				return fmt.Errorf("ImagePullBackOff need to rebuild %s driver-container", UpdateVendor)
+				req.recordDriverImages(obj, false)
+				return fmt.Errorf("ImagePullBackOff need to rebuild %s driver-container", vendor)
 			}
 		}
 
-		c.log.Info("Unsetting updateVendor, Pods not in ImagePullBackOff or ErrImagePull")
-		UpdateVendor = ""
+		c.log.Info("Pods not in ImagePullBackOff or ErrImagePull")
 		return nil
 	}
 
@@ -345,26 +1333,17 @@ func (c *creator) checkForImagePullBackOff(ctx context.Context, obj *unstructure
 
 func (c *creator) createObjFromYAML(
 	ctx context.Context,
-	yamlSpec []byte,
+	req *request,
+	obj *unstructured.Unstructured,
+	affine kernel.AffineObjects,
 	releaseInstalled bool,
-	owner v1.Object,
 	name string,
 	namespace string,
 	nodeSelector map[string]string,
 	kernelFullVersion string,
 	operatingSystemMajorMinor string) error {
-	obj := &unstructured.Unstructured{
-		Object: map[string]interface{}{},
-	}
 
-	jsonSpec, err := yaml.YAMLToJSON(yamlSpec)
-	if err != nil {
-		return fmt.Errorf("Could not convert yaml file to json: %s: error %w", string(yamlSpec), err)
-	}
-
-	if err = obj.UnmarshalJSON(jsonSpec); err != nil {
-		return fmt.Errorf("cannot unmarshall json spec, check your manifest: %s: %w", jsonSpec, err)
-	}
+	var err error
 
 	//  Do not override the namespace if already set
 	if c.helper.IsNamespaced(obj.GetKind()) && obj.GetNamespace() == "" {
@@ -388,12 +1367,18 @@ func (c *creator) createObjFromYAML(
 	}
 	// kernel affinity related attributes only set if there is an
 	// annotation specialresource.openshift.io/kernel-affine: true
-	if c.kernelData.IsObjectAffine(obj) {
-		if err = c.kernelData.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor); err != nil {
+	if affine.Has(obj.GetKind(), obj.GetName()) {
+		if err = c.kernelData.SetAffineAttributes(obj, kernelFullVersion, operatingSystemMajorMinor, affine); err != nil {
 			return fmt.Errorf("cannot set kernel affine attributes: %w", err)
 		}
 	}
 
+	// The images are recorded under the name the object is created with
+	for _, image := range req.images[obj] {
+		image.Namespace, image.Name = obj.GetNamespace(), obj.GetName()
+		req.result.ResolvedImages = append(req.result.ResolvedImages, image)
+	}
+
 	// Add nodeSelector terms defined for the specialresource CR to the object
 	// we do not want to spread HW enablement stacks on all nodes
 	if err = c.helper.SetNodeSelectorTerms(obj, nodeSelector); err != nil {
@@ -403,17 +1388,20 @@ func (c *creator) createObjFromYAML(
 	// We are only building a driver-container if we cannot pull the image
 	// We are asuming that vendors provide pre compiled DriverContainers
 	// If err == nil, build a new container, if err != nil skip it
-	if err = c.rebuildDriverContainer(obj); err != nil {
-		c.log.Info("Skipping building driver-container", "Name", obj.GetName())
-		return nil
+	if err = c.rebuildDriverContainer(ctx, req, obj); err != nil {
+		if errors.Is(err, errSkipBuild) {
+			c.log.Info("Skipping building driver-container", "Name", obj.GetName())
+			return nil
+		}
+		return fmt.Errorf("cannot rebuild driver-container: %w", err)
 	}
 
 	// Callbacks before CRUD will update the manifests
-	if err = c.BeforeCRUD(obj, owner); err != nil {
+	if err = c.BeforeCRUD(obj, req.owner); err != nil {
 		return fmt.Errorf("before CRUD hooks failed: %w", err)
 	}
 	// Create Update Delete Patch resources
-	err = c.CRUD(ctx, obj, releaseInstalled, owner, name, namespace)
+	err = c.CRUD(ctx, req, obj, releaseInstalled, name, namespace)
 	if err != nil {
 		if strings.Contains(err.Error(), "failed calling webhook") {
 			return fmt.Errorf("webhook not ready, requeue: %w", err)
@@ -422,9 +1410,12 @@ func (c *creator) createObjFromYAML(
 		return fmt.Errorf("CRUD exited non-zero on Object: %+v: %w", obj, err)
 	}
 
-	// Callbacks after CRUD will wait for ressource and check status
-	if err = c.AfterCRUD(ctx, obj, namespace); err != nil {
-		return fmt.Errorf("after CRUD hooks failed: %w", err)
+	// Callbacks after CRUD will wait for ressource and check status, nothing
+	// was created in dry-run mode
+	if !req.dryRun {
+		if err = c.AfterCRUD(ctx, req, obj, namespace); err != nil {
+			return fmt.Errorf("after CRUD hooks failed: %w", err)
+		}
 	}
 
 	c.sendNodesMetrics(ctx, obj, name)
@@ -433,26 +1424,123 @@ func (c *creator) createObjFromYAML(
 	return nil
 }
 
-func (c *creator) rebuildDriverContainer(obj *unstructured.Unstructured) error {
+func (c *creator) rebuildDriverContainer(ctx context.Context, req *request, obj *unstructured.Unstructured) error {
 
 	logger := c.log.WithValues("Kind", obj.GetKind(), "Namespace", obj.GetNamespace(), "Name", obj.GetName())
 	// BuildConfig are currently not triggered by an update need to delete first
-	if obj.GetKind() == "BuildConfig" {
-		annotations := obj.GetAnnotations()
-		if vendor, ok := annotations["specialresource.openshift.io/driver-container-vendor"]; ok {
-			logger.Info("driver-container-vendor", "vendor", vendor)
-			if vendor == UpdateVendor {
-				logger.Info("vendor == updateVendor", "vendor", vendor, "updateVendor", UpdateVendor)
-				return nil
-			}
-			logger.Info("vendor != updateVendor", "vendor", vendor, "updateVendor", UpdateVendor)
-			return errors.New("vendor != updateVendor")
-		}
+	if obj.GetKind() != "BuildConfig" && obj.GetKind() != "BuildRun" {
+		return nil
+	}
+
+	annotations := obj.GetAnnotations()
+	vendor, ok := annotations["specialresource.openshift.io/driver-container-vendor"]
+	if !ok {
 		logger.Info("No annotation driver-container-vendor found, not skipping")
 		return nil
 	}
 
-	return nil
+	logger.Info("driver-container-vendor", "vendor", vendor)
+
+	// The images of the vendor's DaemonSets were checked for this kernel,
+	// otherwise the build runs if the status records them missing, e.g.
+	// once their Pods went into ImagePullBackOff
+	build, checked := req.builds[vendor]
+	since := req.missingSince[vendor]
+	if !checked {
+		since = vendorMissingSince(req.owner, vendor, req.kernelFullVersion)
+		build = !since.IsZero()
+	}
+	if !build {
+		logger.Info("Driver images present, skipping the build", "vendor", vendor, "checked", checked)
+		return errSkipBuild
+	}
+	logger.Info("Driver images missing, running the build", "vendor", vendor, "checked", checked, "missingSince", since)
+
+	if req.dryRun {
+		logger.Info("Dry run, not restarting the build")
+		return nil
+	}
+
+	if obj.GetKind() == "BuildRun" {
+		return c.restartBuildRun(ctx, obj, since)
+	}
+
+	return c.restartBuildConfig(ctx, obj)
+}
+
+// restartBuildConfig deletes a BuildConfig created with another rollout token, its
+// ConfigChange trigger only starts a build when it is created.
+func (c *creator) restartBuildConfig(ctx context.Context, obj *unstructured.Unstructured) error {
+
+	token, found := obj.GetAnnotations()[RolloutTokenAnnotation]
+	if !found {
+		return nil
+	}
+
+	existing := obj.DeepCopy()
+
+	err := c.kubeClient.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get BuildConfig %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	if existing.GetAnnotations()[RolloutTokenAnnotation] == token {
+		return nil
+	}
+
+	c.log.Info("Deleting BuildConfig of a previous rollout to trigger a rebuild", "Name", obj.GetName(), "token", token)
+
+	if err = c.kubeClient.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete BuildConfig %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	return c.pollActions.ForResourceUnavailability(ctx, existing)
+}
+
+// restartBuildRun deletes a BuildRun that finished before the driver image went
+// missing at since, or with another rollout token, a BuildRun only runs once so a
+// new one has to be created.
+func (c *creator) restartBuildRun(ctx context.Context, obj *unstructured.Unstructured, since time.Time) error {
+
+	found := obj.DeepCopy()
+
+	err := c.kubeClient.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, found)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get BuildRun %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	if status, _, _ := poll.BuildRunCondition(found); status != "True" && status != "False" {
+		c.log.Info("BuildRun still running, not restarting", "Name", obj.GetName())
+		return nil
+	}
+
+	completion, _, err := unstructured.NestedString(found.Object, "status", "completionTime")
+	if err != nil {
+		return err
+	}
+
+	// A BuildRun of a previous rollout token runs again as well
+	token, stamped := obj.GetAnnotations()[RolloutTokenAnnotation]
+	rolledOut := stamped && found.GetAnnotations()[RolloutTokenAnnotation] != token
+
+	if completed, err := time.Parse(time.RFC3339, completion); err == nil && completed.After(since) && !rolledOut {
+		c.log.Info("BuildRun completed after the driver image went missing, not restarting", "Name", obj.GetName())
+		return nil
+	}
+
+	c.log.Info("Deleting finished BuildRun to trigger a rebuild", "Name", obj.GetName())
+
+	if err = c.kubeClient.Delete(ctx, found); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete BuildRun %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	return c.pollActions.ForResourceUnavailability(ctx, found)
 }
 
 func (c *creator) sendNodesMetrics(ctx context.Context, obj *unstructured.Unstructured, crName string) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -18,13 +19,17 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	kubetypes "k8s.io/apimachinery/pkg/types"
 
+	srov1beta1 "github.com/openshift-psap/special-resource-operator/api/v1beta1"
 	"github.com/openshift-psap/special-resource-operator/internal/resourcehelper"
 	"github.com/openshift-psap/special-resource-operator/pkg/clients"
+	"github.com/openshift-psap/special-resource-operator/pkg/imagepolicy"
 	"github.com/openshift-psap/special-resource-operator/pkg/kernel"
 	"github.com/openshift-psap/special-resource-operator/pkg/lifecycle"
 	"github.com/openshift-psap/special-resource-operator/pkg/metrics"
 	"github.com/openshift-psap/special-resource-operator/pkg/poll"
 	"github.com/openshift-psap/special-resource-operator/pkg/proxy"
+	"github.com/openshift-psap/special-resource-operator/pkg/registry"
+	"github.com/openshift-psap/special-resource-operator/pkg/signing"
 	"github.com/openshift-psap/special-resource-operator/pkg/utils"
 )
 
@@ -105,12 +110,12 @@ spec:
 		}
 
 		gomock.InOrder(
+			kernelData.EXPECT().IsObjectAffine(gomock.Any()).Times(1).Return(false),
 			helper.EXPECT().IsNamespaced("Pod").Times(1).Return(true),
 			helper.EXPECT().SetLabel(gomock.Any(), ownedLabel).Times(1).
 				DoAndReturn(func(obj *unstructured.Unstructured, label string) error {
 					return resourcehelper.New().SetLabel(obj, label)
 				}),
-			kernelData.EXPECT().IsObjectAffine(gomock.Any()).Times(1).Return(false),
 			helper.EXPECT().SetNodeSelectorTerms(gomock.Any(), nodeSelector).Times(1).
 				DoAndReturn(func(obj *unstructured.Unstructured, terms map[string]string) error {
 					return resourcehelper.New().SetNodeSelectorTerms(obj, terms)
@@ -130,8 +135,8 @@ spec:
 		err := v1.AddToScheme(scheme)
 		Expect(err).NotTo(HaveOccurred())
 
-		err =
-			NewCreator(kubeClient, metricsClient, pollActions, kernelData, scheme, mockLifecycle, proxyAPI, helper).
+		_, err =
+			NewCreator(kubeClient, metricsClient, pollActions, kernelData, scheme, mockLifecycle, proxyAPI, helper, nil, nil).
 				CreateFromYAML(
 					context.TODO(),
 					yamlSpec,
@@ -142,6 +147,7 @@ spec:
 					nodeSelector,
 					kernelFullVersion,
 					operatingSystemMajorMinor,
+					nil,
 				)
 
 		Expect(err).NotTo(HaveOccurred())
@@ -218,12 +224,12 @@ spec:
 		}
 
 		gomock.InOrder(
+			kernelData.EXPECT().IsObjectAffine(gomock.Any()).Times(1).Return(false),
 			helper.EXPECT().IsNamespaced("Pod").Times(1).Return(true),
 			helper.EXPECT().SetLabel(gomock.Any(), ownedLabel).Times(1).
 				DoAndReturn(func(obj *unstructured.Unstructured, label string) error {
 					return resourcehelper.New().SetLabel(obj, label)
 				}),
-			kernelData.EXPECT().IsObjectAffine(gomock.Any()).Times(1).Return(false),
 			helper.EXPECT().SetNodeSelectorTerms(gomock.Any(), nodeSelector).Times(1).
 				DoAndReturn(func(obj *unstructured.Unstructured, terms map[string]string) error {
 					return resourcehelper.New().SetNodeSelectorTerms(obj, terms)
@@ -255,8 +261,8 @@ spec:
 		err = v1.AddToScheme(scheme)
 		Expect(err).NotTo(HaveOccurred())
 
-		err =
-			NewCreator(kubeClient, metricsClient, pollActions, kernelData, scheme, mockLifecycle, proxyAPI, helper).
+		_, err =
+			NewCreator(kubeClient, metricsClient, pollActions, kernelData, scheme, mockLifecycle, proxyAPI, helper, nil, nil).
 				CreateFromYAML(
 					context.TODO(),
 					yamlSpec,
@@ -267,10 +273,47 @@ spec:
 					nodeSelector,
 					kernelFullVersion,
 					operatingSystemMajorMinor,
+					nil,
 				)
 
 		Expect(err).NotTo(HaveOccurred())
 	})
+	It("should record the resolved images under the kernel affine names", func() {
+		const namespace = "ns"
+
+		mockRegistry := registry.NewMockRegistry(ctrl)
+		operator := &srov1beta1.SpecialResourceImagePolicy{PinDigests: true}
+
+		gomock.InOrder(
+			mockRegistry.EXPECT().ImageDigest(context.TODO(), "nginx:1.14.2").Return("sha256:0123", nil),
+			kernelData.EXPECT().IsObjectAffine(gomock.Any()).Return(true),
+			helper.EXPECT().IsNamespaced("Pod").Return(true),
+			helper.EXPECT().SetLabel(gomock.Any(), ownedLabel),
+			kernelData.EXPECT().SetAffineAttributes(gomock.Any(), "1.2.3", "8.5", gomock.Any()).
+				DoAndReturn(func(obj *unstructured.Unstructured, _, _ string, _ kernel.AffineObjects) error {
+					obj.SetName(obj.GetName() + "-efb0a5d31af5b3fd")
+					return nil
+				}),
+			helper.EXPECT().SetNodeSelectorTerms(gomock.Any(), nil).Return(errors.New("random error")),
+			metricsClient.EXPECT().SetCompletedKind("special-resource", "Pod", "nginx", namespace, 0),
+		)
+
+		res, err :=
+			NewCreator(kubeClient, metricsClient, pollActions, kernelData, nil, mockLifecycle, proxyAPI, helper, mockRegistry, operator).
+				CreateFromYAML(context.TODO(), yamlSpec, false, &v1.Pod{}, "special-resource", namespace, nil, "1.2.3", "8.5", nil)
+		Expect(err).To(HaveOccurred())
+
+		Expect(res.ResolvedImages).To(Equal([]srov1beta1.ResolvedImageStatus{
+			{
+				Kind:      "Pod",
+				Namespace: namespace,
+				Name:      "nginx-efb0a5d31af5b3fd",
+				Container: "nginx",
+				Image:     "nginx:1.14.2",
+				Resolved:  "nginx:1.14.2@sha256:0123",
+			},
+		}))
+	})
 })
 
 var _ = Describe("creator_CheckForImagePullBackOff", func() {
@@ -278,16 +321,14 @@ var _ = Describe("creator_CheckForImagePullBackOff", func() {
 		ctrl        *gomock.Controller
 		kubeClient  *clients.MockClientsInterface
 		pollActions *poll.MockPollActions
+		req         *request
 	)
 
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
 		kubeClient = clients.NewMockClientsInterface(ctrl)
 		pollActions = poll.NewMockPollActions(ctrl)
-	})
-
-	AfterEach(func() {
-		UpdateVendor = ""
+		req = &request{kernelFullVersion: "5.14.0"}
 	})
 
 	const (
@@ -305,10 +346,11 @@ var _ = Describe("creator_CheckForImagePullBackOff", func() {
 
 		pollActions.EXPECT().ForDaemonSet(context.TODO(), ds)
 
-		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil).(*creator).
-			checkForImagePullBackOff(context.TODO(), ds, namespace)
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			checkForImagePullBackOff(context.TODO(), req, ds, namespace)
 
 		Expect(err).NotTo(HaveOccurred())
+		Expect(req.result.DriverImages).To(BeEmpty())
 	})
 
 	getDaemonSet := func() *unstructured.Unstructured {
@@ -316,10 +358,43 @@ var _ = Describe("creator_CheckForImagePullBackOff", func() {
 		ds.SetAPIVersion("v1")
 		ds.SetKind("DaemonSet")
 		ds.SetLabels(map[string]string{"app": app})
+		Expect(unstructured.SetNestedSlice(ds.Object, []interface{}{
+			map[string]interface{}{"name": "driver", "image": "registry/driver-container:5.14.0"},
+		}, "spec", "template", "spec", "containers")).To(Succeed())
 
 		return ds
 	}
 
+	It("should record the pulled images the status has missing", func() {
+		const vendor = "test-vendor"
+
+		ds := getDaemonSet()
+		ds.SetAnnotations(map[string]string{"specialresource.openshift.io/driver-container-vendor": vendor})
+
+		req.owner = &srov1beta1.SpecialResource{
+			Status: srov1beta1.SpecialResourceStatus{
+				DriverImages: []srov1beta1.DriverImageStatus{
+					{
+						Image:             "registry/driver-container:5.14.0",
+						KernelFullVersion: "5.14.0",
+						Vendor:            vendor,
+						MissingSince:      metav1.Now(),
+					},
+				},
+			},
+		}
+
+		pollActions.EXPECT().ForDaemonSet(context.TODO(), ds)
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			checkForImagePullBackOff(context.TODO(), req, ds, namespace)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(req.result.DriverImages).To(HaveLen(1))
+		Expect(req.result.DriverImages[0].Present).To(BeTrue())
+		Expect(req.result.DriverImages[0].MissingSince).To(BeZero())
+	})
+
 	It("should return an error if we cannot get the pod list", func() {
 		randomError := errors.New("random error")
 		ds := getDaemonSet()
@@ -329,8 +404,8 @@ var _ = Describe("creator_CheckForImagePullBackOff", func() {
 			kubeClient.EXPECT().List(context.TODO(), &v1.PodList{}, opts...).Return(randomError),
 		)
 
-		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil).(*creator).
-			checkForImagePullBackOff(context.TODO(), ds, namespace)
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			checkForImagePullBackOff(context.TODO(), req, ds, namespace)
 
 		Expect(err).To(Equal(randomError))
 	})
@@ -343,8 +418,8 @@ var _ = Describe("creator_CheckForImagePullBackOff", func() {
 			kubeClient.EXPECT().List(context.TODO(), &v1.PodList{}, opts...),
 		)
 
-		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil).(*creator).
-			checkForImagePullBackOff(context.TODO(), ds, namespace)
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			checkForImagePullBackOff(context.TODO(), req, ds, namespace)
 
 		Expect(err).To(HaveOccurred())
 	})
@@ -382,11 +457,16 @@ var _ = Describe("creator_CheckForImagePullBackOff", func() {
 				}),
 		)
 
-		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil).(*creator).
-			checkForImagePullBackOff(context.TODO(), ds, namespace)
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			checkForImagePullBackOff(context.TODO(), req, ds, namespace)
 
 		Expect(err).To(MatchError("ImagePullBackOff need to rebuild " + vendor + " driver-container"))
-		Expect(UpdateVendor).To(Equal(vendor))
+		Expect(req.result.DriverImages).To(HaveLen(1))
+		Expect(req.result.DriverImages[0].Image).To(Equal("registry/driver-container:5.14.0"))
+		Expect(req.result.DriverImages[0].KernelFullVersion).To(Equal("5.14.0"))
+		Expect(req.result.DriverImages[0].Vendor).To(Equal(vendor))
+		Expect(req.result.DriverImages[0].Present).To(BeFalse())
+		Expect(req.result.DriverImages[0].MissingSince).ToNot(BeZero())
 	})
 
 	It("should return an error if one of the pods is Waiting for a random reason", func() {
@@ -419,11 +499,11 @@ var _ = Describe("creator_CheckForImagePullBackOff", func() {
 				}),
 		)
 
-		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil).(*creator).
-			checkForImagePullBackOff(context.TODO(), ds, namespace)
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			checkForImagePullBackOff(context.TODO(), req, ds, namespace)
 
 		Expect(err).NotTo(HaveOccurred())
-		Expect(UpdateVendor).To(BeEmpty())
+		Expect(req.result.DriverImages).To(BeEmpty())
 	})
 
 	It("should not panic if a container is not waiting", func() {
@@ -445,11 +525,11 @@ var _ = Describe("creator_CheckForImagePullBackOff", func() {
 				}),
 		)
 
-		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil).(*creator).
-			checkForImagePullBackOff(context.TODO(), ds, namespace)
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			checkForImagePullBackOff(context.TODO(), req, ds, namespace)
 
 		Expect(err).NotTo(HaveOccurred())
-		Expect(UpdateVendor).To(BeEmpty())
+		Expect(req.result.DriverImages).To(BeEmpty())
 	})
 })
 
@@ -472,7 +552,7 @@ var _ = Describe("creator_BeforeCRUD", func() {
 
 		proxyAPI.EXPECT().Setup(obj).Return(nil).Times(1)
 
-		err := NewCreator(nil, nil, nil, nil, nil, nil, proxyAPI, nil).(*creator).
+		err := NewCreator(nil, nil, nil, nil, nil, nil, proxyAPI, nil, nil, nil).(*creator).
 			BeforeCRUD(obj, nil)
 
 		Expect(err).ToNot(HaveOccurred())
@@ -491,7 +571,7 @@ var _ = Describe("creator_BeforeCRUD", func() {
 			"specialresource.openshift.io/callback": callbackName,
 		})
 
-		err := NewCreator(nil, nil, nil, nil, nil, nil, proxyAPI, nil).(*creator).
+		err := NewCreator(nil, nil, nil, nil, nil, nil, proxyAPI, nil, nil, nil).(*creator).
 			BeforeCRUD(obj, nil)
 
 		Expect(err).ToNot(HaveOccurred())
@@ -499,6 +579,759 @@ var _ = Describe("creator_BeforeCRUD", func() {
 	})
 })
 
+var _ = Describe("creator_rebuildDriverContainer", func() {
+	const vendorAnnotation = "specialresource.openshift.io/driver-container-vendor"
+
+	var (
+		ctrl        *gomock.Controller
+		kubeClient  *clients.MockClientsInterface
+		pollActions *poll.MockPollActions
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		kubeClient = clients.NewMockClientsInterface(ctrl)
+		pollActions = poll.NewMockPollActions(ctrl)
+	})
+
+	missingOwner := func(since time.Time) *srov1beta1.SpecialResource {
+		return &srov1beta1.SpecialResource{
+			Status: srov1beta1.SpecialResourceStatus{
+				DriverImages: []srov1beta1.DriverImageStatus{
+					{
+						Image:             "registry/driver-container:5.14.0",
+						KernelFullVersion: "5.14.0",
+						Vendor:            "vendor",
+						MissingSince:      metav1.NewTime(since),
+					},
+				},
+			},
+		}
+	}
+
+	prepareBuildRun := func() *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("shipwright.io/v1alpha1")
+		obj.SetKind("BuildRun")
+		obj.SetName("driver-build")
+		obj.SetNamespace("ns")
+		obj.SetAnnotations(map[string]string{vendorAnnotation: "vendor"})
+		return obj
+	}
+
+	finishedAt := func(completion time.Time) func(context.Context, types.NamespacedName, client.Object) error {
+		return func(_ context.Context, _ types.NamespacedName, o client.Object) error {
+			u := o.(*unstructured.Unstructured)
+			Expect(unstructured.SetNestedSlice(u.Object, []interface{}{
+				map[string]interface{}{"type": "Succeeded", "status": "True"},
+			}, "status", "conditions")).To(Succeed())
+			Expect(unstructured.SetNestedField(u.Object, completion.Format(time.RFC3339), "status", "completionTime")).To(Succeed())
+			return nil
+		}
+	}
+
+	It("should skip the BuildRun if the vendor's image can be pulled", func() {
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			rebuildDriverContainer(context.Background(), &request{}, prepareBuildRun())
+
+		Expect(err).To(MatchError(errSkipBuild))
+	})
+
+	It("should skip the BuildRun if the images of another kernel are missing", func() {
+		req := &request{owner: missingOwner(time.Now()), kernelFullVersion: "5.15.0"}
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			rebuildDriverContainer(context.Background(), req, prepareBuildRun())
+
+		Expect(err).To(MatchError(errSkipBuild))
+	})
+
+	It("should follow the driver images checked for the kernel", func() {
+		c := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator)
+
+		req := &request{owner: missingOwner(time.Now()), kernelFullVersion: "5.14.0", builds: DriverBuilds{"vendor": false}}
+		err := c.rebuildDriverContainer(context.Background(), req, prepareBuildRun())
+		Expect(err).To(MatchError(errSkipBuild))
+
+		kubeClient.EXPECT().
+			Get(context.Background(), gomock.Any(), unstructuredMatcher).
+			Return(k8serrors.NewNotFound(v1.Resource("buildruns"), "driver-build"))
+
+		err = c.rebuildDriverContainer(context.Background(), &request{builds: DriverBuilds{"vendor": true}}, prepareBuildRun())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should not touch a BuildRun that does not exist yet", func() {
+		kubeClient.EXPECT().
+			Get(context.Background(), gomock.Any(), unstructuredMatcher).
+			Return(k8serrors.NewNotFound(v1.Resource("buildruns"), "driver-build"))
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			rebuildDriverContainer(context.Background(), &request{owner: missingOwner(time.Now()), kernelFullVersion: "5.14.0"}, prepareBuildRun())
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should delete a BuildRun that finished before the image went missing", func() {
+		since := time.Now()
+
+		gomock.InOrder(
+			kubeClient.EXPECT().
+				Get(context.Background(), gomock.Any(), unstructuredMatcher).
+				DoAndReturn(finishedAt(since.Add(-time.Hour))),
+			kubeClient.EXPECT().Delete(context.Background(), unstructuredMatcher),
+			pollActions.EXPECT().ForResourceUnavailability(context.Background(), unstructuredMatcher),
+		)
+
+		req := &request{builds: DriverBuilds{"vendor": true}, missingSince: map[string]time.Time{"vendor": since}}
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			rebuildDriverContainer(context.Background(), req, prepareBuildRun())
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should delete a BuildRun that finished before the image of the status went missing", func() {
+		since := time.Now()
+
+		gomock.InOrder(
+			kubeClient.EXPECT().
+				Get(context.Background(), gomock.Any(), unstructuredMatcher).
+				DoAndReturn(finishedAt(since.Add(-time.Hour))),
+			kubeClient.EXPECT().Delete(context.Background(), unstructuredMatcher),
+			pollActions.EXPECT().ForResourceUnavailability(context.Background(), unstructuredMatcher),
+		)
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			rebuildDriverContainer(context.Background(), &request{owner: missingOwner(since), kernelFullVersion: "5.14.0"}, prepareBuildRun())
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should keep a BuildRun that finished after the image went missing", func() {
+		since := time.Now()
+
+		kubeClient.EXPECT().
+			Get(context.Background(), gomock.Any(), unstructuredMatcher).
+			DoAndReturn(finishedAt(since.Add(time.Hour)))
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			rebuildDriverContainer(context.Background(), &request{owner: missingOwner(since), kernelFullVersion: "5.14.0"}, prepareBuildRun())
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should delete a finished BuildRun of a previous rollout token", func() {
+		obj := prepareBuildRun()
+		obj.SetAnnotations(map[string]string{vendorAnnotation: "vendor", RolloutTokenAnnotation: "2"})
+
+		gomock.InOrder(
+			kubeClient.EXPECT().
+				Get(context.Background(), gomock.Any(), unstructuredMatcher).
+				DoAndReturn(func(ctx context.Context, key types.NamespacedName, o client.Object) error {
+					o.SetAnnotations(map[string]string{RolloutTokenAnnotation: "1"})
+					return finishedAt(time.Now())(ctx, key, o)
+				}),
+			kubeClient.EXPECT().Delete(context.Background(), unstructuredMatcher),
+			pollActions.EXPECT().ForResourceUnavailability(context.Background(), unstructuredMatcher),
+		)
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			rebuildDriverContainer(context.Background(), &request{builds: DriverBuilds{"vendor": true}}, obj)
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("creator_restartBuildConfig", func() {
+	var (
+		ctrl        *gomock.Controller
+		kubeClient  *clients.MockClientsInterface
+		pollActions *poll.MockPollActions
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		kubeClient = clients.NewMockClientsInterface(ctrl)
+		pollActions = poll.NewMockPollActions(ctrl)
+	})
+
+	prepareBuildConfig := func(token string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("build.openshift.io/v1")
+		obj.SetKind("BuildConfig")
+		obj.SetName("driver-build")
+		obj.SetNamespace("ns")
+		obj.SetAnnotations(map[string]string{RolloutTokenAnnotation: token})
+		return obj
+	}
+
+	withToken := func(token string) func(context.Context, types.NamespacedName, client.Object) error {
+		return func(_ context.Context, _ types.NamespacedName, o client.Object) error {
+			o.SetAnnotations(map[string]string{RolloutTokenAnnotation: token})
+			return nil
+		}
+	}
+
+	It("should delete a BuildConfig of a previous rollout", func() {
+		gomock.InOrder(
+			kubeClient.EXPECT().Get(context.Background(), gomock.Any(), gomock.Any()).DoAndReturn(withToken("1")),
+			kubeClient.EXPECT().Delete(context.Background(), gomock.Any()),
+			pollActions.EXPECT().ForResourceUnavailability(context.Background(), gomock.Any()),
+		)
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			restartBuildConfig(context.Background(), prepareBuildConfig("2"))
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should keep a BuildConfig of the current rollout", func() {
+		kubeClient.EXPECT().Get(context.Background(), gomock.Any(), gomock.Any()).DoAndReturn(withToken("2"))
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			restartBuildConfig(context.Background(), prepareBuildConfig("2"))
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should not look up a BuildConfig without a rollout token", func() {
+		obj := prepareBuildConfig("")
+		obj.SetAnnotations(nil)
+
+		err := NewCreator(kubeClient, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			restartBuildConfig(context.Background(), obj)
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("stampRolloutToken", func() {
+	It("should annotate the pod template of workloads", func() {
+		ds := &unstructured.Unstructured{}
+		ds.SetKind("DaemonSet")
+		ds.SetName("driver-container")
+
+		Expect(stampRolloutToken(ds, "1")).To(Succeed())
+		Expect(ds.GetAnnotations()).To(HaveKeyWithValue(RolloutTokenAnnotation, "1"))
+
+		token, _, err := unstructured.NestedString(ds.Object, "spec", "template", "metadata", "annotations", RolloutTokenAnnotation)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("1"))
+	})
+
+	It("should only annotate other objects", func() {
+		job := &unstructured.Unstructured{}
+		job.SetKind("Job")
+		job.SetName("driver-job")
+
+		Expect(stampRolloutToken(job, "1")).To(Succeed())
+		Expect(job.GetAnnotations()).To(HaveKeyWithValue(RolloutTokenAnnotation, "1"))
+		Expect(job.Object).NotTo(HaveKey("spec"))
+	})
+})
+
+var _ = Describe("setOnDeleteUpdateStrategy", func() {
+	It("should only set the update strategy of driver DaemonSets", func() {
+		ds := &unstructured.Unstructured{}
+		ds.SetKind("DaemonSet")
+		ds.SetName("driver-container")
+		ds.SetAnnotations(map[string]string{"specialresource.openshift.io/state": "driver-container"})
+		Expect(unstructured.SetNestedField(ds.Object, "RollingUpdate", "spec", "updateStrategy", "type")).To(Succeed())
+
+		other := ds.DeepCopy()
+		other.SetAnnotations(nil)
+
+		Expect(setOnDeleteUpdateStrategy(ds)).To(Succeed())
+		Expect(setOnDeleteUpdateStrategy(other)).To(Succeed())
+
+		strategy, _, err := unstructured.NestedMap(ds.Object, "spec", "updateStrategy")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strategy).To(Equal(map[string]interface{}{"type": "OnDelete"}))
+
+		strategyType, _, err := unstructured.NestedString(other.Object, "spec", "updateStrategy", "type")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strategyType).To(Equal("RollingUpdate"))
+	})
+})
+
+var _ = Describe("addNotReadyToleration", func() {
+	It("should add the toleration once to the pod templates", func() {
+		ds := &unstructured.Unstructured{}
+		ds.SetKind("DaemonSet")
+		ds.SetName("driver-container")
+		Expect(unstructured.SetNestedSlice(ds.Object, []interface{}{
+			map[string]interface{}{"operator": "Exists"},
+		}, "spec", "template", "spec", "tolerations")).To(Succeed())
+
+		cm := &unstructured.Unstructured{}
+		cm.SetKind("ConfigMap")
+
+		for i := 0; i < 2; i++ {
+			Expect(addNotReadyToleration(ds, "simple-kmod")).To(Succeed())
+			Expect(addNotReadyToleration(cm, "simple-kmod")).To(Succeed())
+		}
+
+		tolerations, _, err := unstructured.NestedSlice(ds.Object, "spec", "template", "spec", "tolerations")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tolerations).To(Equal([]interface{}{
+			map[string]interface{}{"operator": "Exists"},
+			map[string]interface{}{
+				"key":      "specialresource.openshift.io/simple-kmod-not-ready",
+				"operator": "Exists",
+				"effect":   "NoSchedule",
+			},
+		}))
+		Expect(cm.Object).NotTo(HaveKey("spec"))
+	})
+})
+
+var _ = Describe("rolloutToken", func() {
+	It("should be pending until observed", func() {
+		sr := &srov1beta1.SpecialResource{}
+		sr.Spec.RolloutToken = "1"
+
+		token, pending := rolloutToken(sr)
+		Expect(token).To(Equal("1"))
+		Expect(pending).To(BeTrue())
+
+		sr.Status.ObservedRolloutToken = "1"
+		_, pending = rolloutToken(sr)
+		Expect(pending).To(BeFalse())
+
+		sr.Spec.RolloutToken = ""
+		_, pending = rolloutToken(sr)
+		Expect(pending).To(BeFalse())
+	})
+})
+
+var _ = Describe("creator_PrepareDrivers", func() {
+	const (
+		image  = "registry/driver-container:5.14.0"
+		vendor = "vendor"
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockRegistry *registry.MockRegistry
+		affine       kernel.AffineObjects
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockRegistry = registry.NewMockRegistry(ctrl)
+
+		affine = make(kernel.AffineObjects)
+		affine.Add("DaemonSet", "driver-container")
+	})
+
+	prepareDaemonSet := func(annotations map[string]string) *unstructured.Unstructured {
+		ds := &unstructured.Unstructured{}
+		ds.SetKind("DaemonSet")
+		ds.SetName("driver-container")
+		ds.SetAnnotations(annotations)
+		Expect(unstructured.SetNestedSlice(ds.Object, []interface{}{
+			map[string]interface{}{"name": "driver", "image": image},
+		}, "spec", "template", "spec", "containers")).To(Succeed())
+		return ds
+	}
+
+	checkAnnotations := map[string]string{
+		"specialresource.openshift.io/check-image":             "true",
+		"specialresource.openshift.io/driver-container-vendor": vendor,
+	}
+
+	It("should not query the registry without the check-image annotation", func() {
+		ds := prepareDaemonSet(map[string]string{"specialresource.openshift.io/driver-container-vendor": vendor})
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, nil).(*creator)
+		builds, images := c.checkDriverImages(context.Background(), nil, []*unstructured.Unstructured{ds}, affine, "sr-0000", "5.14.0", false)
+
+		Expect(builds).To(BeEmpty())
+		Expect(images).To(BeEmpty())
+	})
+
+	It("should run the build objects if the image is missing", func() {
+		mockRegistry.EXPECT().ImageExists(context.Background(), image).Return(false, nil)
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, nil).(*creator)
+		builds, images := c.checkDriverImages(context.Background(), nil, []*unstructured.Unstructured{prepareDaemonSet(checkAnnotations)}, affine, "sr-0000", "5.14.0", false)
+
+		Expect(builds).To(Equal(DriverBuilds{vendor: true}))
+		Expect(images).To(HaveLen(1))
+		Expect(images[0].Image).To(Equal(image))
+		Expect(images[0].State).To(Equal("sr-0000"))
+		Expect(images[0].KernelFullVersion).To(Equal("5.14.0"))
+		Expect(images[0].Vendor).To(Equal(vendor))
+		Expect(images[0].Present).To(BeFalse())
+		Expect(images[0].Build).To(BeTrue())
+		Expect(images[0].MissingSince).ToNot(BeZero())
+	})
+
+	It("should keep the time the image went missing", func() {
+		since := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+		owner := &srov1beta1.SpecialResource{
+			Status: srov1beta1.SpecialResourceStatus{
+				DriverImages: []srov1beta1.DriverImageStatus{
+					{Image: image, KernelFullVersion: "5.14.0", Vendor: vendor, MissingSince: since},
+				},
+			},
+		}
+
+		mockRegistry.EXPECT().ImageExists(context.Background(), image).Return(false, nil)
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, nil).(*creator)
+		_, images := c.checkDriverImages(context.Background(), owner, []*unstructured.Unstructured{prepareDaemonSet(checkAnnotations)}, affine, "sr-0000", "5.14.0", false)
+
+		Expect(images).To(HaveLen(1))
+		Expect(images[0].MissingSince).To(Equal(since))
+	})
+
+	It("should skip the containers that are not objects", func() {
+		ds := prepareDaemonSet(checkAnnotations)
+		Expect(unstructured.SetNestedSlice(ds.Object, []interface{}{
+			"driver",
+			map[string]interface{}{"name": "driver", "image": image},
+		}, "spec", "template", "spec", "containers")).To(Succeed())
+
+		mockRegistry.EXPECT().ImageExists(context.Background(), image).Return(true, nil)
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, nil).(*creator)
+		builds, images := c.checkDriverImages(context.Background(), nil, []*unstructured.Unstructured{ds}, affine, "sr-0000", "5.14.0", false)
+
+		Expect(builds).To(Equal(DriverBuilds{vendor: false}))
+		Expect(images).To(HaveLen(1))
+		Expect(images[0].Image).To(Equal(image))
+	})
+
+	It("should skip the build objects if the image is present", func() {
+		mockRegistry.EXPECT().ImageExists(context.Background(), image).Return(true, nil)
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, nil).(*creator)
+		builds, images := c.checkDriverImages(context.Background(), nil, []*unstructured.Unstructured{prepareDaemonSet(checkAnnotations)}, affine, "sr-0000", "5.14.0", false)
+
+		Expect(builds).To(Equal(DriverBuilds{vendor: false}))
+		Expect(images).To(HaveLen(1))
+		Expect(images[0].Present).To(BeTrue())
+		Expect(images[0].Build).To(BeFalse())
+	})
+
+	It("should run the build objects of a rollout if the image is present", func() {
+		mockRegistry.EXPECT().ImageExists(context.Background(), image).Return(true, nil)
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, nil).(*creator)
+		builds, images := c.checkDriverImages(context.Background(), nil, []*unstructured.Unstructured{prepareDaemonSet(checkAnnotations)}, affine, "sr-0000", "5.14.0", true)
+
+		Expect(builds).To(Equal(DriverBuilds{vendor: true}))
+		Expect(images).To(HaveLen(1))
+		Expect(images[0].Present).To(BeTrue())
+		Expect(images[0].Build).To(BeTrue())
+	})
+
+	It("should fall back to ImagePullBackOff if the registry cannot be queried", func() {
+		mockRegistry.EXPECT().ImageExists(context.Background(), image).Return(false, errors.New("some error"))
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, nil).(*creator)
+		builds, images := c.checkDriverImages(context.Background(), nil, []*unstructured.Unstructured{prepareDaemonSet(checkAnnotations)}, affine, "sr-0000", "5.14.0", false)
+
+		Expect(builds).To(BeEmpty())
+		Expect(images).To(BeEmpty())
+	})
+
+	It("should decide the builds of every kernel before the states are created", func() {
+		const daemonSet = `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: driver-container
+  annotations:
+    specialresource.openshift.io/check-image: "true"
+    specialresource.openshift.io/driver-container-vendor: vendor
+spec:
+  template:
+    spec:
+      containers:
+      - name: driver
+        image: registry/driver-container:`
+
+		kernelData := kernel.NewMockKernelData(ctrl)
+		kernelData.EXPECT().IsObjectAffine(gomock.Any()).Return(true).AnyTimes()
+
+		gomock.InOrder(
+			mockRegistry.EXPECT().ImageExists(context.Background(), "registry/driver-container:5.14.0").Return(false, nil),
+			mockRegistry.EXPECT().ImageExists(context.Background(), "registry/driver-container:5.15.0").Return(true, nil),
+		)
+
+		c := NewCreator(nil, nil, nil, kernelData, nil, nil, nil, nil, mockRegistry, nil)
+		drivers, images, err := c.PrepareDrivers(context.Background(), &srov1beta1.SpecialResource{}, []Manifest{
+			{State: "sr-0001", KernelFullVersion: "5.14.0", YAML: []byte(daemonSet + "5.14.0\n")},
+			{State: "sr-0001", KernelFullVersion: "5.15.0", YAML: []byte(daemonSet + "5.15.0\n")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(drivers.Builds).To(Equal(map[string]DriverBuilds{
+			"5.14.0": {vendor: true},
+			"5.15.0": {vendor: false},
+		}))
+		Expect(drivers.Signed).To(BeEmpty())
+		Expect(images).To(HaveLen(2))
+		Expect(images[0].State).To(Equal("sr-0001"))
+
+		// Built for one of the kernels, its finished BuildRuns run again
+		Expect(drivers.MissingSince["5.14.0"][vendor]).ToNot(BeZero())
+		Expect(drivers.MissingSince["5.15.0"]).To(BeEmpty())
+	})
+})
+
+var _ = Describe("creator_resolveImages", func() {
+	var (
+		ctrl         *gomock.Controller
+		mockRegistry *registry.MockRegistry
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockRegistry = registry.NewMockRegistry(ctrl)
+	})
+
+	newDaemonSet := func(image string) *unstructured.Unstructured {
+		ds := &unstructured.Unstructured{}
+		ds.SetKind("DaemonSet")
+		ds.SetName("driver")
+		Expect(unstructured.SetNestedSlice(ds.Object, []interface{}{
+			map[string]interface{}{"name": "driver", "image": image},
+		}, "spec", "template", "spec", "containers")).To(Succeed())
+		return ds
+	}
+
+	image := func(obj *unstructured.Unstructured) string {
+		containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		Expect(err).NotTo(HaveOccurred())
+		return containers[0].(map[string]interface{})["image"].(string)
+	}
+
+	It("should leave the images alone without a policy", func() {
+		ds := newDaemonSet("quay.io/vendor/driver:1.0")
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, nil).(*creator)
+		resolved, err := c.resolveImages(context.Background(), []*unstructured.Unstructured{ds}, &srov1beta1.SpecialResource{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(image(ds)).To(Equal("quay.io/vendor/driver:1.0"))
+		Expect(resolved).To(BeEmpty())
+	})
+
+	It("should apply the policy of the SpecialResource and the operator-wide one", func() {
+		ds := newDaemonSet("quay.io/vendor/driver:1.0")
+
+		sr := &srov1beta1.SpecialResource{
+			Spec: srov1beta1.SpecialResourceSpec{
+				ImagePolicy: &srov1beta1.SpecialResourceImagePolicy{
+					RegistryRewrites: []srov1beta1.SpecialResourceRegistryRewrite{{From: "quay.io", To: "mirror.corp/quay"}},
+				},
+			},
+		}
+
+		operator := &srov1beta1.SpecialResourceImagePolicy{PinDigests: true, AllowedRegistries: []string{"mirror.corp"}}
+
+		mockRegistry.EXPECT().ImageDigest(context.Background(), "mirror.corp/quay/vendor/driver:1.0").Return("sha256:0123", nil)
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, operator).(*creator)
+		resolved, err := c.resolveImages(context.Background(), []*unstructured.Unstructured{ds}, sr)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(image(ds)).To(Equal("mirror.corp/quay/vendor/driver:1.0@sha256:0123"))
+		Expect(resolved[ds]).To(Equal([]srov1beta1.ResolvedImageStatus{
+			{
+				Kind:      "DaemonSet",
+				Name:      "driver",
+				Container: "driver",
+				Image:     "quay.io/vendor/driver:1.0",
+				Resolved:  "mirror.corp/quay/vendor/driver:1.0@sha256:0123",
+			},
+		}))
+	})
+
+	It("should query the digests once per generation of the SpecialResource", func() {
+		operator := &srov1beta1.SpecialResourceImagePolicy{PinDigests: true}
+		sr := &srov1beta1.SpecialResource{ObjectMeta: metav1.ObjectMeta{UID: "sr-uid", Generation: 1}}
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, operator).(*creator)
+
+		resolve := func() string {
+			ds := newDaemonSet("quay.io/vendor/driver:1.0")
+			_, err := c.resolveImages(context.Background(), []*unstructured.Unstructured{ds}, sr)
+			Expect(err).NotTo(HaveOccurred())
+			return image(ds)
+		}
+
+		// Not pushed yet, looked up again
+		mockRegistry.EXPECT().ImageDigest(context.Background(), "quay.io/vendor/driver:1.0").Return("", nil)
+		Expect(resolve()).To(Equal("quay.io/vendor/driver:1.0"))
+
+		mockRegistry.EXPECT().ImageDigest(context.Background(), "quay.io/vendor/driver:1.0").Return("sha256:0123", nil)
+		Expect(resolve()).To(Equal("quay.io/vendor/driver:1.0@sha256:0123"))
+		Expect(resolve()).To(Equal("quay.io/vendor/driver:1.0@sha256:0123"))
+
+		sr.Generation = 2
+
+		mockRegistry.EXPECT().ImageDigest(context.Background(), "quay.io/vendor/driver:1.0").Return("sha256:4567", nil)
+		Expect(resolve()).To(Equal("quay.io/vendor/driver:1.0@sha256:4567"))
+		Expect(resolve()).To(Equal("quay.io/vendor/driver:1.0@sha256:4567"))
+	})
+
+	It("should forget the digests of a deleted SpecialResource", func() {
+		operator := &srov1beta1.SpecialResourceImagePolicy{PinDigests: true}
+		sr := &srov1beta1.SpecialResource{ObjectMeta: metav1.ObjectMeta{UID: "sr-uid", Generation: 1}}
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, operator).(*creator)
+
+		mockRegistry.EXPECT().ImageDigest(context.Background(), "quay.io/vendor/driver:1.0").Return("sha256:0123", nil)
+		_, err := c.resolveImages(context.Background(), []*unstructured.Unstructured{newDaemonSet("quay.io/vendor/driver:1.0")}, sr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.digests.owners).To(HaveKey(types.UID("sr-uid")))
+
+		c.Forget(sr)
+		Expect(c.digests.owners).To(BeEmpty())
+	})
+
+	It("should return an error for images outside the allowed registries", func() {
+		operator := &srov1beta1.SpecialResourceImagePolicy{AllowedRegistries: []string{"mirror.corp"}}
+
+		c := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, mockRegistry, operator).(*creator)
+		_, err := c.resolveImages(context.Background(), []*unstructured.Unstructured{newDaemonSet("quay.io/vendor/driver:1.0")}, &srov1beta1.SpecialResource{})
+		Expect(errors.Is(err, imagepolicy.ErrNotAllowed)).To(BeTrue())
+	})
+})
+
+var _ = Describe("creator_prepareSigning", func() {
+	const vendorAnnotation = "specialresource.openshift.io/driver-container-vendor"
+
+	newObj := func(kind, name string, annotations map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind(kind)
+		obj.SetName(name)
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+
+	newObjs := func() []*unstructured.Unstructured {
+		build := newObj("BuildConfig", "driver-build", map[string]string{vendorAnnotation: "vendor", signing.BuildAnnotation: "true"})
+		Expect(unstructured.SetNestedMap(build.Object, map[string]interface{}{
+			"kind": "DockerImage",
+			"name": "quay.io/vendor/driver:v1",
+		}, "spec", "output", "to")).To(Succeed())
+
+		ds := newObj("DaemonSet", "driver-container", map[string]string{vendorAnnotation: "vendor"})
+		Expect(unstructured.SetNestedSlice(ds.Object, []interface{}{
+			map[string]interface{}{"name": "driver", "image": "quay.io/vendor/driver:v1"},
+		}, "spec", "template", "spec", "containers")).To(Succeed())
+
+		return []*unstructured.Unstructured{
+			newObj("BuildConfig", "signing", map[string]string{signing.Annotation: "true"}),
+			build,
+			newObj("ImageStream", "driver", nil),
+			ds,
+		}
+	}
+
+	sr := &srov1beta1.SpecialResource{
+		Spec: srov1beta1.SpecialResourceSpec{
+			Signing: &srov1beta1.SpecialResourceSigning{
+				KeySecretRef:  srov1beta1.SpecialResourceSecretKeyRef{Name: "key", Key: "key.priv"},
+				CertSecretRef: srov1beta1.SpecialResourceSecretKeyRef{Name: "cert", Key: "cert.der"},
+			},
+		},
+	}
+
+	It("should not change anything if signing is not enabled", func() {
+		objs := newObjs()[1:]
+
+		signed, err := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			prepareSigning(objs, &srov1beta1.SpecialResource{}, nil)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signed).To(Equal(objs))
+	})
+
+	It("should fail if a signing BuildConfig is rendered without signing", func() {
+		_, err := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			prepareSigning(newObjs(), &srov1beta1.SpecialResource{}, nil)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should sign after the build and use the signed images", func() {
+		objs := newObjs()
+		Expect(signingVendors(objs, sr)).To(Equal(map[string]bool{"vendor": true}))
+
+		signed, err := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			prepareSigning(objs, sr, signingVendors(objs, sr))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signed).To(HaveLen(4))
+
+		Expect(signed[0].GetName()).To(Equal("driver-build"))
+		Expect(signed[0].GetAnnotations()).To(HaveKeyWithValue("specialresource.openshift.io/wait", "true"))
+		Expect(signed[1].GetName()).To(Equal("driver-build-signing"))
+		Expect(signed[2].GetKind()).To(Equal("ImageStream"))
+
+		containers, _, err := unstructured.NestedSlice(signed[3].Object, "spec", "template", "spec", "containers")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers[0].(map[string]interface{})["image"]).To(Equal("quay.io/vendor/driver:v1-signed"))
+	})
+
+	It("should only sign the annotated BuildConfigs and keep the images of unsigned vendors", func() {
+		objs := newObjs()
+		annotations := objs[1].GetAnnotations()
+		delete(annotations, signing.BuildAnnotation)
+		objs[1].SetAnnotations(annotations)
+
+		Expect(signingVendors(objs, sr)).To(BeEmpty())
+
+		signed, err := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			prepareSigning(objs, sr, signingVendors(objs, sr))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signed).To(HaveLen(3))
+		Expect(signed[0].GetAnnotations()).NotTo(HaveKey("specialresource.openshift.io/wait"))
+
+		containers, _, err := unstructured.NestedSlice(signed[2].Object, "spec", "template", "spec", "containers")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers[0].(map[string]interface{})["image"]).To(Equal("quay.io/vendor/driver:v1"))
+	})
+
+	It("should use the signed images of a vendor signed in another state", func() {
+		ds := newObjs()[3]
+
+		signed, err := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			prepareSigning([]*unstructured.Unstructured{ds}, sr, map[string]bool{"vendor": true})
+
+		Expect(err).ToNot(HaveOccurred())
+
+		containers, _, err := unstructured.NestedSlice(signed[0].Object, "spec", "template", "spec", "containers")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers[0].(map[string]interface{})["image"]).To(Equal("quay.io/vendor/driver:v1-signed"))
+	})
+
+	It("should skip the containers that are not objects", func() {
+		ds := newObjs()[3]
+		Expect(unstructured.SetNestedSlice(ds.Object, []interface{}{
+			"driver",
+			map[string]interface{}{"name": "driver", "image": "quay.io/vendor/driver:v1"},
+		}, "spec", "template", "spec", "containers")).To(Succeed())
+
+		signed, err := NewCreator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			prepareSigning([]*unstructured.Unstructured{ds}, sr, map[string]bool{"vendor": true})
+
+		Expect(err).ToNot(HaveOccurred())
+
+		containers, _, err := unstructured.NestedSlice(signed[0].Object, "spec", "template", "spec", "containers")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers[0]).To(Equal("driver"))
+		Expect(containers[1].(map[string]interface{})["image"]).To(Equal("quay.io/vendor/driver:v1-signed"))
+	})
+})
+
 var _ = Describe("creator_AfterCRUD", func() {
 	var (
 		ctrl        *gomock.Controller
@@ -519,8 +1352,8 @@ var _ = Describe("creator_AfterCRUD", func() {
 
 			expectations()
 
-			err := NewCreator(nil, nil, pollActions, nil, nil, nil, nil, nil).(*creator).
-				AfterCRUD(context.Background(), obj, "ns")
+			err := NewCreator(nil, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+				AfterCRUD(context.Background(), &request{}, obj, "ns")
 
 			Expect(err).ToNot(HaveOccurred())
 
@@ -558,8 +1391,8 @@ var _ = Describe("creator_AfterCRUD", func() {
 
 		pollActions.EXPECT().ForResource(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
-		err := NewCreator(nil, nil, pollActions, nil, nil, nil, nil, nil).(*creator).
-			AfterCRUD(context.Background(), obj, "ns")
+		err := NewCreator(nil, nil, pollActions, nil, nil, nil, nil, nil, nil, nil).(*creator).
+			AfterCRUD(context.Background(), &request{}, obj, "ns")
 
 		Expect(err).ToNot(HaveOccurred())
 	})
@@ -582,7 +1415,7 @@ var _ = Describe("creator_CRUD", func() {
 		scheme := runtime.NewScheme()
 		Expect(v1.AddToScheme(scheme)).To(Succeed())
 
-		c = NewCreator(kubeClient, nil, nil, nil, scheme, nil, nil, helper).(*creator)
+		c = NewCreator(kubeClient, nil, nil, nil, scheme, nil, nil, helper, nil, nil).(*creator)
 	})
 
 	specialResourceName := "special-resource"
@@ -619,7 +1452,7 @@ var _ = Describe("creator_CRUD", func() {
 			}
 			helper.EXPECT().SetMetaData(u, specialResourceName, namespace).Times(times)
 
-			Expect(c.CRUD(context.Background(), u, false, &owner, specialResourceName, namespace)).To(Succeed())
+			Expect(c.CRUD(context.Background(), &request{owner: &owner}, u, false, specialResourceName, namespace)).To(Succeed())
 		},
 		Entry("neither SpecialResource nor Namespace", "Pod", "name", namespace, true, true),
 		Entry("Namespace", "Namespace", namespace, "", false, false),
@@ -645,7 +1478,7 @@ var _ = Describe("creator_CRUD", func() {
 			}
 			kubeClient.EXPECT().Create(gomock.Any(), gomock.Any()).Times(times)
 
-			Expect(c.CRUD(context.Background(), obj, releaseInstalled, &owner, specialResourceName, namespace)).To(Succeed())
+			Expect(c.CRUD(context.Background(), &request{owner: &owner}, obj, releaseInstalled, specialResourceName, namespace)).To(Succeed())
 		},
 		Entry("object is OneTimer & release is installed = no object recreation", true, true),
 		Entry("object is OneTimer & release is not installed = object recreation", true, false),
@@ -664,7 +1497,7 @@ var _ = Describe("creator_CRUD", func() {
 				Return(&k8serrors.StatusError{ErrStatus: metav1.Status{Reason: errReason}})
 
 			releaseInstalled := false
-			err := c.CRUD(context.Background(), obj, releaseInstalled, &owner, specialResourceName, namespace)
+			err := c.CRUD(context.Background(), &request{owner: &owner}, obj, releaseInstalled, specialResourceName, namespace)
 			Expect(err.Error()).To(ContainSubstring(expectedSubstring))
 		},
 		Entry("forbidden error", metav1.StatusReasonForbidden, "forbidden"),
@@ -684,7 +1517,7 @@ var _ = Describe("creator_CRUD", func() {
 			assert()
 
 			releaseInstalled := false
-			Expect(c.CRUD(context.Background(), obj, releaseInstalled, &owner, specialResourceName, namespace)).To(Succeed())
+			Expect(c.CRUD(context.Background(), &request{owner: &owner}, obj, releaseInstalled, specialResourceName, namespace)).To(Succeed())
 
 		},
 		Entry("won't happen if object is not updateable",
@@ -738,4 +1571,165 @@ var _ = Describe("creator_CRUD", func() {
 			},
 		),
 	)
+	It("should defer the update of a driver DaemonSet outside of the maintenance windows", func() {
+		obj := prepareUnstructured("DaemonSet", "driver-container", namespace)
+		obj.SetAnnotations(map[string]string{"specialresource.openshift.io/state": "driver-container"})
+
+		helper.EXPECT().IsNamespaced(obj.GetKind()).Return(true)
+		helper.EXPECT().SetMetaData(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		helper.EXPECT().IsNotUpdateable(obj.GetKind()).Return(false)
+		kubeClient.EXPECT().
+			Get(gomock.Any(), types.NamespacedName{Namespace: namespace, Name: obj.GetName()}, gomock.Any()).
+			Return(nil)
+
+		req := &request{owner: &owner, deferDriverChanges: true}
+		Expect(c.CRUD(context.Background(), req, obj, false, specialResourceName, namespace)).To(Succeed())
+
+		changes := req.result.PendingChanges
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Kind).To(Equal("DaemonSet"))
+		Expect(changes[0].Name).To(Equal("driver-container"))
+		Expect(changes[0].Change).To(Equal(srov1beta1.PendingDaemonSetUpdate))
+
+		// The nodes are still labeled according to the DaemonSet
+		Expect(req.result.DaemonSets).To(Equal([]types.NamespacedName{{Namespace: namespace, Name: "driver-container"}}))
+	})
+
+	DescribeTable("should handle the drift of live objects according to the drift policy",
+		func(policy string, corrected bool) {
+			sr := srov1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: specialResourceName},
+				Spec: srov1beta1.SpecialResourceSpec{
+					Drift: &srov1beta1.SpecialResourceDriftSpec{Policy: policy},
+				},
+			}
+			Expect(srov1beta1.AddToScheme(c.scheme)).To(Succeed())
+
+			obj := prepareUnstructured("ConfigMap", "config", namespace)
+			obj.SetAPIVersion("v1")
+			Expect(unstructured.SetNestedField(obj.Object, "value", "data", "key")).To(Succeed())
+
+			helper.EXPECT().IsNamespaced(obj.GetKind()).Return(true)
+			helper.EXPECT().SetMetaData(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			helper.EXPECT().IsNotUpdateable(obj.GetKind()).Return(false)
+			kubeClient.EXPECT().
+				Get(gomock.Any(), types.NamespacedName{Namespace: namespace, Name: obj.GetName()}, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ client.ObjectKey, o client.Object) error {
+					u := o.(*unstructured.Unstructured)
+					obj.DeepCopyInto(u)
+					Expect(utils.Annotate(u)).To(Succeed())
+					return unstructured.SetNestedField(u.Object, "patched", "data", "key")
+				})
+			kubeClient.EXPECT().
+				Event(&sr, "Warning", gomock.Any(), "ConfigMap ns/config differs from the chart: data.key")
+
+			if corrected {
+				helper.EXPECT().UpdateResourceVersion(gomock.Any(), gomock.Any()).Return(nil)
+				kubeClient.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, o client.Object) error {
+					value, _, _ := unstructured.NestedString(o.(*unstructured.Unstructured).Object, "data", "key")
+					Expect(value).To(Equal("value"))
+					return nil
+				})
+			}
+
+			req := &request{owner: &sr}
+			Expect(c.CRUD(context.Background(), req, obj, false, specialResourceName, namespace)).To(Succeed())
+
+			drift := req.result.Drift
+			Expect(drift).To(HaveLen(1))
+			Expect(drift[0].Name).To(Equal("config"))
+			Expect(drift[0].Fields).To(Equal([]string{"data.key"}))
+			Expect(drift[0].Corrected).To(Equal(corrected))
+		},
+		Entry("report-only", srov1beta1.DriftPolicyReportOnly, false),
+		Entry("correct", srov1beta1.DriftPolicyCorrect, true),
+	)
+
+	Context("in dry-run mode", func() {
+		var sr srov1beta1.SpecialResource
+
+		BeforeEach(func() {
+			sr = srov1beta1.SpecialResource{
+				ObjectMeta: metav1.ObjectMeta{Name: specialResourceName},
+				Spec:       srov1beta1.SpecialResourceSpec{DryRun: true},
+			}
+			Expect(srov1beta1.AddToScheme(c.scheme)).To(Succeed())
+
+			helper.EXPECT().IsNamespaced(gomock.Any()).Return(true)
+			helper.EXPECT().SetMetaData(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		})
+
+		It("should create the object in server dry-run mode and record its diff", func() {
+			obj := prepareUnstructured("ConfigMap", "config", namespace)
+			obj.SetAPIVersion("v1")
+
+			kubeClient.EXPECT().
+				Get(gomock.Any(), types.NamespacedName{Namespace: namespace, Name: obj.GetName()}, gomock.Any()).
+				Return(&k8serrors.StatusError{ErrStatus: metav1.Status{Reason: metav1.StatusReasonNotFound}})
+			helper.EXPECT().IsOneTimer(obj).Return(false, nil)
+			kubeClient.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: namespace}, gomock.AssignableToTypeOf(&v1.Namespace{})).Return(nil)
+			kubeClient.EXPECT().Create(gomock.Any(), obj, client.DryRunAll).Return(nil)
+
+			req := &request{owner: &sr, dryRun: true}
+			Expect(c.CRUD(context.Background(), req, obj, false, specialResourceName, namespace)).To(Succeed())
+
+			changes := req.result.DryRunChanges
+			Expect(changes).To(HaveLen(1))
+			Expect(changes[0].Key()).To(Equal("configmap.ns.config.diff"))
+			Expect(changes[0].Diff).To(HavePrefix("--- /dev/null\n+++ dry-run\n"))
+			Expect(changes[0].Diff).To(ContainSubstring("+kind: ConfigMap\n"))
+		})
+
+		It("should record the object as rendered if its namespace does not exist yet", func() {
+			obj := prepareUnstructured("ConfigMap", "config", namespace)
+			obj.SetAPIVersion("v1")
+
+			kubeClient.EXPECT().
+				Get(gomock.Any(), types.NamespacedName{Namespace: namespace, Name: obj.GetName()}, gomock.Any()).
+				Return(k8serrors.NewNotFound(v1.Resource("configmaps"), obj.GetName()))
+			helper.EXPECT().IsOneTimer(obj).Return(false, nil)
+			kubeClient.EXPECT().
+				Get(gomock.Any(), types.NamespacedName{Name: namespace}, gomock.AssignableToTypeOf(&v1.Namespace{})).
+				Return(k8serrors.NewNotFound(v1.Resource("namespaces"), namespace))
+
+			req := &request{owner: &sr, dryRun: true}
+			Expect(c.CRUD(context.Background(), req, obj, false, specialResourceName, namespace)).To(Succeed())
+
+			changes := req.result.DryRunChanges
+			Expect(changes).To(HaveLen(1))
+			Expect(changes[0].Error).To(BeEmpty())
+			Expect(changes[0].Diff).To(HavePrefix("--- /dev/null\n+++ dry-run\n"))
+			Expect(changes[0].Diff).To(ContainSubstring("+kind: ConfigMap\n"))
+		})
+
+		It("should record the error of an update rejected by the API server", func() {
+			obj := prepareUnstructured("ConfigMap", "config", namespace)
+			obj.SetAPIVersion("v1")
+
+			kubeClient.EXPECT().
+				Get(gomock.Any(), types.NamespacedName{Namespace: namespace, Name: obj.GetName()}, gomock.Any()).
+				Return(nil)
+			helper.EXPECT().IsNotUpdateable(obj.GetKind()).Return(false)
+			helper.EXPECT().UpdateResourceVersion(gomock.Any(), gomock.Any()).Return(nil)
+			kubeClient.EXPECT().Update(gomock.Any(), gomock.Any(), client.DryRunAll).Return(errors.New("admission webhook denied the request"))
+
+			req := &request{owner: &sr, dryRun: true}
+			Expect(c.CRUD(context.Background(), req, obj, false, specialResourceName, namespace)).To(Succeed())
+
+			changes := req.result.DryRunChanges
+			Expect(changes).To(HaveLen(1))
+			Expect(changes[0].Diff).To(BeEmpty())
+			Expect(changes[0].Error).To(Equal("admission webhook denied the request"))
+		})
+	})
+})
+
+var _ = Describe("maintenanceWindowOpen", func() {
+	It("should be open without windows and closed with invalid ones", func() {
+		sr := &srov1beta1.SpecialResource{}
+		Expect(maintenanceWindowOpen(sr)).To(BeTrue())
+
+		sr.Spec.MaintenanceWindows = []srov1beta1.SpecialResourceMaintenanceWindow{{Schedule: "invalid"}}
+		Expect(maintenanceWindowOpen(sr)).To(BeFalse())
+	})
 })
@@ -0,0 +1,218 @@
+package signing
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
+)
+
+const (
+	// Annotation marks the BuildConfig rendered from Template.
+	Annotation = "specialresource.openshift.io/signing"
+
+	// BuildAnnotation marks the driver container BuildConfigs whose image is
+	// signed, the DaemonSets of their vendor use the signed image.
+	BuildAnnotation = "specialresource.openshift.io/sign"
+
+	defaultTagSuffix   = "-signed"
+	defaultModulesPath = "/opt/lib/modules"
+
+	keyMountPath  = "/run/secrets/signing-key"
+	certMountPath = "/run/secrets/signing-cert"
+
+	internalRegistry = "image-registry.openshift-image-registry.svc:5000"
+)
+
+// TemplateName is the name of the template injected into the states of a chart.
+// It does not start with four digits, so it is never treated as a state itself.
+const TemplateName = "templates/specialresource-signing.yaml"
+
+// Template is the signing BuildConfig injected after the BuildConfigs of a state.
+// It is rendered by Helm for the DTK and kernel version and is bound to each of the
+// state's BuildConfigs annotated with BuildAnnotation by Bind, otherwise dropped. The private key and certificate are only mounted
+// while the modules are signed and never end up in the signed image.
+const Template = `apiVersion: build.openshift.io/v1
+kind: BuildConfig
+metadata:
+  name: {{.Values.specialresource.metadata.name}}-signing
+  annotations:
+    specialresource.openshift.io/wait: "true"
+    ` + Annotation + `: "true"
+spec:
+  runPolicy: "Serial"
+  triggers:
+    - type: "ConfigChange"
+  source:
+    type: Dockerfile
+    dockerfile: |
+      ARG UNSIGNED_IMAGE
+      ARG DTK_IMAGE
+      FROM ${UNSIGNED_IMAGE} AS unsigned
+      FROM ${DTK_IMAGE} AS signer
+      ARG KVER
+      ARG MODULES_PATH
+      ARG KEY_FILE
+      ARG CERT_FILE
+      COPY --from=unsigned ${MODULES_PATH} /modules
+      RUN find /modules -name '*.ko' -exec /usr/src/kernels/${KVER}/scripts/sign-file sha256 ${KEY_FILE} ${CERT_FILE} {} \;
+      FROM unsigned
+      ARG MODULES_PATH
+      COPY --from=signer /modules ${MODULES_PATH}
+  strategy:
+    dockerStrategy:
+      buildArgs:
+        - name: DTK_IMAGE
+          value: {{ .Values.driverToolkitImage }}
+        - name: KVER
+          value: {{ .Values.kernelFullVersion }}
+`
+
+// TemplateFile returns Template as a chart file to append to a state's templates.
+func TemplateFile() *chart.File {
+	return &chart.File{Name: TemplateName, Data: []byte(Template)}
+}
+
+func tagSuffix(spec *v1beta1.SpecialResourceSigning) string {
+	if spec.TagSuffix == "" {
+		return defaultTagSuffix
+	}
+	return spec.TagSuffix
+}
+
+func modulesPath(spec *v1beta1.SpecialResourceSigning) string {
+	if spec.ModulesPath == "" {
+		return defaultModulesPath
+	}
+	return spec.ModulesPath
+}
+
+// SignedImage returns the reference of the signed image for image, the tag is
+// suffixed with the signing TagSuffix. Images referenced by digest cannot be
+// renamed and are returned as is.
+func SignedImage(image string, spec *v1beta1.SpecialResourceSigning) string {
+
+	if strings.Contains(image, "@") {
+		return image
+	}
+
+	suffix := tagSuffix(spec)
+
+	// A colon after the last slash separates the tag, otherwise it is a registry port
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		if strings.HasSuffix(image, suffix) {
+			return image
+		}
+		return image + suffix
+	}
+
+	return image + ":latest" + suffix
+}
+
+// Bind binds the signing BuildConfig to the output image of build: the image
+// built by build is signed and pushed under the signed tag next to it.
+func Bind(signer, build *unstructured.Unstructured, spec *v1beta1.SpecialResourceSigning) error {
+
+	signer.SetName(build.GetName() + "-signing")
+	signer.SetNamespace(build.GetNamespace())
+
+	// The signing build is skipped, rebuilt and replicated per kernel like build
+	annotations := signer.GetAnnotations()
+	for _, key := range []string{
+		"specialresource.openshift.io/driver-container-vendor",
+		"specialresource.openshift.io/kernel-affine",
+	} {
+		if value, found := build.GetAnnotations()[key]; found {
+			annotations[key] = value
+		}
+	}
+	signer.SetAnnotations(annotations)
+
+	to, found, err := unstructured.NestedMap(build.Object, "spec", "output", "to")
+	if err != nil || !found {
+		return fmt.Errorf("BuildConfig %s has no output image to sign: %v", build.GetName(), err)
+	}
+
+	kind, _, _ := unstructured.NestedString(to, "kind")
+	name, _, _ := unstructured.NestedString(to, "name")
+
+	var unsigned string
+
+	switch kind {
+	case "ImageStreamTag":
+		namespace, _, _ := unstructured.NestedString(to, "namespace")
+		if namespace == "" {
+			namespace = build.GetNamespace()
+		}
+		unsigned = internalRegistry + "/" + namespace + "/" + name
+
+		// The first FROM is replaced by the ImageStreamTag, so that every new
+		// unsigned image triggers a signing build
+		from := map[string]interface{}{"kind": kind, "name": name, "namespace": namespace}
+		if err = unstructured.SetNestedMap(signer.Object, from, "spec", "strategy", "dockerStrategy", "from"); err != nil {
+			return err
+		}
+		triggers, _, _ := unstructured.NestedSlice(signer.Object, "spec", "triggers")
+		triggers = append(triggers, map[string]interface{}{"type": "ImageChange", "imageChange": map[string]interface{}{}})
+		if err = unstructured.SetNestedSlice(signer.Object, triggers, "spec", "triggers"); err != nil {
+			return err
+		}
+	case "DockerImage":
+		unsigned = name
+	default:
+		return fmt.Errorf("BuildConfig %s output kind %q cannot be signed", build.GetName(), kind)
+	}
+
+	to["name"] = SignedImage(name, spec)
+	if err = unstructured.SetNestedMap(signer.Object, to, "spec", "output", "to"); err != nil {
+		return err
+	}
+
+	if pushSecret, found, _ := unstructured.NestedMap(build.Object, "spec", "output", "pushSecret"); found {
+		if err = unstructured.SetNestedMap(signer.Object, pushSecret, "spec", "output", "pushSecret"); err != nil {
+			return err
+		}
+		if err = unstructured.SetNestedMap(signer.Object, pushSecret, "spec", "strategy", "dockerStrategy", "pullSecret"); err != nil {
+			return err
+		}
+	}
+
+	args, _, err := unstructured.NestedSlice(signer.Object, "spec", "strategy", "dockerStrategy", "buildArgs")
+	if err != nil {
+		return err
+	}
+
+	args = append(args,
+		map[string]interface{}{"name": "UNSIGNED_IMAGE", "value": unsigned},
+		map[string]interface{}{"name": "MODULES_PATH", "value": modulesPath(spec)},
+		map[string]interface{}{"name": "KEY_FILE", "value": keyMountPath + "/" + spec.KeySecretRef.Key},
+		map[string]interface{}{"name": "CERT_FILE", "value": certMountPath + "/" + spec.CertSecretRef.Key},
+	)
+
+	if err = unstructured.SetNestedSlice(signer.Object, args, "spec", "strategy", "dockerStrategy", "buildArgs"); err != nil {
+		return err
+	}
+
+	volumes := []interface{}{
+		secretVolume("signing-key", spec.KeySecretRef.Name, keyMountPath),
+		secretVolume("signing-cert", spec.CertSecretRef.Name, certMountPath),
+	}
+
+	return unstructured.SetNestedSlice(signer.Object, volumes, "spec", "strategy", "dockerStrategy", "volumes")
+}
+
+func secretVolume(name, secret, path string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"source": map[string]interface{}{
+			"type":   "Secret",
+			"secret": map[string]interface{}{"secretName": secret},
+		},
+		"mounts": []interface{}{
+			map[string]interface{}{"destinationPath": path},
+		},
+	}
+}
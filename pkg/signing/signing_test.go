@@ -0,0 +1,164 @@
+package signing
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift-psap/special-resource-operator/api/v1beta1"
+)
+
+func TestSigning(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Signing Suite")
+}
+
+var spec = &v1beta1.SpecialResourceSigning{
+	KeySecretRef:  v1beta1.SpecialResourceSecretKeyRef{Name: "signing-key", Key: "key.priv"},
+	CertSecretRef: v1beta1.SpecialResourceSecretKeyRef{Name: "signing-cert", Key: "cert.der"},
+}
+
+func renderTemplate() *unstructured.Unstructured {
+
+	c := &chart.Chart{
+		Metadata:  &chart.Metadata{Name: "simple-kmod", Version: "0.0.1"},
+		Templates: []*chart.File{TemplateFile()},
+	}
+
+	values, err := chartutil.ToRenderValues(c, map[string]interface{}{
+		"specialresource": map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "simple-kmod"},
+		},
+		"driverToolkitImage": "quay.io/openshift/driver-toolkit:4.10",
+		"kernelFullVersion":  "4.18.0-305.19.1.el8_4.x86_64",
+	}, chartutil.ReleaseOptions{Name: "simple-kmod", Namespace: "simple-kmod"}, nil)
+	Expect(err).ToNot(HaveOccurred())
+
+	rendered, err := engine.Render(c, values)
+	Expect(err).ToNot(HaveOccurred())
+
+	obj := &unstructured.Unstructured{}
+	Expect(yaml.Unmarshal([]byte(rendered["simple-kmod/"+TemplateName]), &obj.Object)).To(Succeed())
+
+	return obj
+}
+
+func newBuildConfig(kind, image string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetKind("BuildConfig")
+	obj.SetName("simple-kmod-driver-build")
+	obj.SetNamespace("simple-kmod")
+	obj.SetAnnotations(map[string]string{
+		"specialresource.openshift.io/driver-container-vendor": "simple-kmod",
+	})
+	Expect(unstructured.SetNestedMap(obj.Object, map[string]interface{}{
+		"kind": kind,
+		"name": image,
+	}, "spec", "output", "to")).To(Succeed())
+
+	return obj
+}
+
+func buildArgs(obj *unstructured.Unstructured) map[string]string {
+	args, _, err := unstructured.NestedSlice(obj.Object, "spec", "strategy", "dockerStrategy", "buildArgs")
+	Expect(err).ToNot(HaveOccurred())
+
+	m := make(map[string]string)
+	for _, arg := range args {
+		arg := arg.(map[string]interface{})
+		m[arg["name"].(string)] = arg["value"].(string)
+	}
+	return m
+}
+
+var _ = Describe("SignedImage", func() {
+	DescribeTable("suffixes the image tag",
+		func(image, expected string) {
+			Expect(SignedImage(image, spec)).To(Equal(expected))
+		},
+		Entry("tag", "quay.io/vendor/driver:v4.18", "quay.io/vendor/driver:v4.18-signed"),
+		Entry("registry port", "registry:5000/vendor/driver:v4.18", "registry:5000/vendor/driver:v4.18-signed"),
+		Entry("no tag", "registry:5000/vendor/driver", "registry:5000/vendor/driver:latest-signed"),
+		Entry("already signed", "quay.io/vendor/driver:v4.18-signed", "quay.io/vendor/driver:v4.18-signed"),
+		Entry("digest", "quay.io/vendor/driver@sha256:abcd", "quay.io/vendor/driver@sha256:abcd"),
+	)
+
+	It("uses the configured suffix", func() {
+		custom := spec.DeepCopy()
+		custom.TagSuffix = "-sb"
+		Expect(SignedImage("quay.io/vendor/driver:v4.18", custom)).To(Equal("quay.io/vendor/driver:v4.18-sb"))
+	})
+})
+
+var _ = Describe("Bind", func() {
+	It("signs an ImageStreamTag and rebuilds on image changes", func() {
+		signer := renderTemplate()
+		build := newBuildConfig("ImageStreamTag", "simple-kmod-driver-container:v4.18")
+
+		Expect(Bind(signer, build, spec)).To(Succeed())
+
+		Expect(signer.GetName()).To(Equal("simple-kmod-driver-build-signing"))
+		Expect(signer.GetNamespace()).To(Equal("simple-kmod"))
+		Expect(signer.GetAnnotations()).To(HaveKeyWithValue("specialresource.openshift.io/driver-container-vendor", "simple-kmod"))
+		Expect(signer.GetAnnotations()).To(HaveKeyWithValue(Annotation, "true"))
+
+		Expect(buildArgs(signer)).To(Equal(map[string]string{
+			"DTK_IMAGE":      "quay.io/openshift/driver-toolkit:4.10",
+			"KVER":           "4.18.0-305.19.1.el8_4.x86_64",
+			"UNSIGNED_IMAGE": "image-registry.openshift-image-registry.svc:5000/simple-kmod/simple-kmod-driver-container:v4.18",
+			"MODULES_PATH":   "/opt/lib/modules",
+			"KEY_FILE":       "/run/secrets/signing-key/key.priv",
+			"CERT_FILE":      "/run/secrets/signing-cert/cert.der",
+		}))
+
+		output, _, err := unstructured.NestedString(signer.Object, "spec", "output", "to", "name")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal("simple-kmod-driver-container:v4.18-signed"))
+
+		from, _, err := unstructured.NestedString(signer.Object, "spec", "strategy", "dockerStrategy", "from", "name")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(from).To(Equal("simple-kmod-driver-container:v4.18"))
+
+		triggers, _, err := unstructured.NestedSlice(signer.Object, "spec", "triggers")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(triggers).To(HaveLen(2))
+
+		volumes, _, err := unstructured.NestedSlice(signer.Object, "spec", "strategy", "dockerStrategy", "volumes")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(volumes).To(HaveLen(2))
+	})
+
+	It("signs a DockerImage with the push secret of the build", func() {
+		signer := renderTemplate()
+		build := newBuildConfig("DockerImage", "quay.io/vendor/driver:v4.18")
+		Expect(unstructured.SetNestedField(build.Object, "push-secret", "spec", "output", "pushSecret", "name")).To(Succeed())
+
+		Expect(Bind(signer, build, spec)).To(Succeed())
+
+		Expect(buildArgs(signer)).To(HaveKeyWithValue("UNSIGNED_IMAGE", "quay.io/vendor/driver:v4.18"))
+
+		output, _, _ := unstructured.NestedString(signer.Object, "spec", "output", "to", "name")
+		Expect(output).To(Equal("quay.io/vendor/driver:v4.18-signed"))
+
+		push, _, _ := unstructured.NestedString(signer.Object, "spec", "output", "pushSecret", "name")
+		Expect(push).To(Equal("push-secret"))
+		pull, _, _ := unstructured.NestedString(signer.Object, "spec", "strategy", "dockerStrategy", "pullSecret", "name")
+		Expect(pull).To(Equal("push-secret"))
+
+		_, found, _ := unstructured.NestedMap(signer.Object, "spec", "strategy", "dockerStrategy", "from")
+		Expect(found).To(BeFalse())
+	})
+
+	It("fails if the build has no output", func() {
+		build := newBuildConfig("DockerImage", "")
+		unstructured.RemoveNestedField(build.Object, "spec", "output")
+
+		Expect(Bind(renderTemplate(), build, spec)).ToNot(Succeed())
+	})
+})
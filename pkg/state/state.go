@@ -2,16 +2,52 @@ package state
 
 import (
 	"path"
+	"strings"
 
 	"helm.sh/helm/v3/pkg/chart"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	notReadyTaintPrefix = "specialresource.openshift.io/"
+	notReadyTaintSuffix = "-not-ready"
 )
 
 var CurrentName string
 
 func GenerateName(file *chart.File, sr string) {
+	CurrentName = Name(file, sr)
+}
+
+// Name returns the name of the state of the SpecialResource sr in file, the key
+// of the label of the nodes on which it is ready.
+func Name(file *chart.File, sr string) string {
 
-	prefix := "specialresource.openshift.io/state-"
 	seq := path.Base(file.Name)[:4]
 
-	CurrentName = prefix + sr + "-" + seq
+	return Prefix(sr) + seq
+}
+
+// Prefix returns the prefix of the labels of the states of the SpecialResource sr,
+// followed by the sequence number of the state.
+func Prefix(sr string) string {
+	return "specialresource.openshift.io/state-" + sr + "-"
+}
+
+// NotReadyTaint returns the taint of the nodes on which the states of sr are not ready.
+func NotReadyTaint(sr string) v1.Taint {
+	return v1.Taint{Key: notReadyTaintPrefix + sr + notReadyTaintSuffix, Effect: v1.TaintEffectNoSchedule}
+}
+
+// NotReadyToleration returns the toleration of the taint of the nodes on which the
+// states of sr are not ready.
+func NotReadyToleration(sr string) v1.Toleration {
+	return v1.Toleration{Key: NotReadyTaint(sr).Key, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}
+}
+
+// IsNotReadyTaint returns true if taint is the not ready taint of a SpecialResource.
+func IsNotReadyTaint(taint v1.Taint) bool {
+	return taint.Effect == v1.TaintEffectNoSchedule &&
+		strings.HasPrefix(taint.Key, notReadyTaintPrefix) &&
+		strings.HasSuffix(taint.Key, notReadyTaintSuffix)
 }
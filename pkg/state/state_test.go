@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"helm.sh/helm/v3/pkg/chart"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/openshift-psap/special-resource-operator/pkg/state"
 )
@@ -28,3 +29,11 @@ var _ = Describe("GenerateName", func() {
 		Expect(state.CurrentName).To(Equal("specialresource.openshift.io/state-some-sr-test"))
 	})
 })
+
+var _ = Describe("IsNotReadyTaint", func() {
+	It("should only match the not ready taints of SpecialResources", func() {
+		Expect(state.IsNotReadyTaint(state.NotReadyTaint("simple-kmod"))).To(BeTrue())
+		Expect(state.IsNotReadyTaint(v1.Taint{Key: "node.kubernetes.io/not-ready", Effect: v1.TaintEffectNoSchedule})).To(BeFalse())
+		Expect(state.IsNotReadyTaint(v1.Taint{Key: state.NotReadyTaint("simple-kmod").Key, Effect: v1.TaintEffectNoExecute})).To(BeFalse())
+	})
+})
@@ -59,7 +59,7 @@ type clusterInfo struct {
 // GetClusterInfo returns a map[full kernel version]NodeVersion
 func (ci *clusterInfo) GetClusterInfo(ctx context.Context, nodeList *corev1.NodeList) (map[string]NodeVersion, error) {
 
-	info, err := ci.nodeVersionInfo(nodeList)
+	info, err := NodeVersions(nodeList)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get upgrade info: %w", err)
 	}
@@ -78,7 +78,9 @@ func (ci *clusterInfo) GetClusterInfo(ctx context.Context, nodeList *corev1.Node
 
 }
 
-func (ci *clusterInfo) nodeVersionInfo(nodeList *corev1.NodeList) (map[string]NodeVersion, error) {
+// NodeVersions returns the operating system and cluster version of the nodes by their
+// full kernel version, from their NFD labels.
+func NodeVersions(nodeList *corev1.NodeList) (map[string]NodeVersion, error) {
 
 	var found bool
 	var info = make(map[string]NodeVersion)
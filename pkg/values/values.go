@@ -0,0 +1,222 @@
+package values
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// runInfoSchema is the schema of the runtime information injected into the
+// values of every chart.
+//
+//go:embed runinfo.schema.json
+var runInfoSchema []byte
+
+// contextDelimiter separates the fields of an error context, it cannot be part of a JSON key.
+const contextDelimiter = "\x00"
+
+// ErrInvalid is wrapped by the errors of values violating a schema.
+var ErrInvalid = errors.New("values invalid")
+
+// Violation is a value violating a schema.
+type Violation struct {
+	// Pointer is the RFC 6901 JSON pointer of the value.
+	Pointer string
+	// Message describes the violation.
+	Message string
+}
+
+// ValidationError lists the violations of the values of a chart.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Pointer+": "+v.Message)
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalid, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalid
+}
+
+// Pointers returns the JSON pointers of the violations.
+func (e *ValidationError) Pointers() []string {
+	pointers := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		pointers = append(pointers, v.Pointer)
+	}
+	return pointers
+}
+
+// Validate validates the merged values of a chart against the schemas of the
+// chart and of its subcharts, and the injected runtime information against the
+// operator's schema. It returns a *ValidationError listing every violation.
+func Validate(ch *chart.Chart, vals map[string]interface{}) error {
+
+	violations, err := validateChart(ch, vals, "")
+	if err != nil {
+		return err
+	}
+
+	rv, err := validate(runInfoSchema, vals, "")
+	if err != nil {
+		return fmt.Errorf("cannot validate the runtime information: %w", err)
+	}
+
+	return newValidationError(append(violations, rv...))
+}
+
+// ValidateChart validates values against the schemas of the chart and of its
+// subcharts only. Violations of the keys of the runtime information, which is
+// only known while reconciling, are ignored.
+func ValidateChart(ch *chart.Chart, vals map[string]interface{}) error {
+
+	violations, err := validateChart(ch, vals, "")
+	if err != nil {
+		return err
+	}
+
+	keys, err := RunInfoKeys()
+	if err != nil {
+		return err
+	}
+
+	kept := violations[:0]
+	for _, v := range violations {
+		key := strings.SplitN(strings.TrimPrefix(v.Pointer, "/"), "/", 2)[0]
+		if !keys[unescape(key)] {
+			kept = append(kept, v)
+		}
+	}
+
+	return newValidationError(kept)
+}
+
+// RunInfoKeys returns the top-level keys of the runtime information.
+func RunInfoKeys() (map[string]bool, error) {
+
+	var s struct {
+		Properties map[string]interface{} `json:"properties"`
+	}
+
+	if err := json.Unmarshal(runInfoSchema, &s); err != nil {
+		return nil, fmt.Errorf("cannot decode the runtime information schema: %w", err)
+	}
+
+	keys := make(map[string]bool, len(s.Properties))
+	for k := range s.Properties {
+		keys[k] = true
+	}
+
+	return keys, nil
+}
+
+func newValidationError(violations []Violation) error {
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Pointer < violations[j].Pointer
+	})
+
+	return &ValidationError{Violations: violations}
+}
+
+// validateChart validates the values of ch and of its subcharts, which are
+// found under the name of the subchart, prefix is the pointer of vals.
+func validateChart(ch *chart.Chart, vals map[string]interface{}, prefix string) ([]Violation, error) {
+
+	var violations []Violation
+
+	if len(ch.Schema) > 0 {
+		v, err := validate(ch.Schema, vals, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("cannot validate the values of chart %s: %w", ch.Name(), err)
+		}
+		violations = append(violations, v...)
+	}
+
+	for _, sub := range ch.Dependencies() {
+		subvals, _ := vals[sub.Name()].(map[string]interface{})
+		if subvals == nil {
+			subvals = map[string]interface{}{}
+		}
+
+		v, err := validateChart(sub, subvals, prefix+"/"+escape(sub.Name()))
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, v...)
+	}
+
+	return violations, nil
+}
+
+// validate validates vals against schema, prefix is the pointer of vals.
+func validate(schema []byte, vals map[string]interface{}, prefix string) ([]Violation, error) {
+
+	// Round-trip through JSON so that typed values, e.g. of the runtime
+	// information, are seen as the templates see them.
+	doc, err := json.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(doc))
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]Violation, 0, len(result.Errors()))
+	for _, re := range result.Errors() {
+		violations = append(violations, Violation{
+			Pointer: prefix + pointer(re),
+			Message: re.Description(),
+		})
+	}
+
+	return violations, nil
+}
+
+// pointer returns the JSON pointer of the value a schema error is about.
+func pointer(re gojsonschema.ResultError) string {
+
+	fields := strings.Split(re.Context().String(contextDelimiter), contextDelimiter)
+
+	// A missing or unexpected property is reported on its parent object
+	switch re.Type() {
+	case "required", "additional_property_not_allowed":
+		if property, ok := re.Details()["property"].(string); ok {
+			fields = append(fields, property)
+		}
+	}
+
+	var sb strings.Builder
+	// The first field is the root of the document
+	for _, f := range fields[1:] {
+		sb.WriteString("/")
+		sb.WriteString(escape(f))
+	}
+
+	return sb.String()
+}
+
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+func unescape(key string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+}
@@ -0,0 +1,143 @@
+package values
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestValues(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Values Suite")
+}
+
+const chartSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["driver"],
+  "properties": {
+    "driver": {
+      "type": "object",
+      "required": ["version"],
+      "properties": {
+        "version": {"type": "string"},
+        "nodes/max": {"type": "integer"}
+      }
+    },
+    "tolerations": {"type": "array", "items": {"type": "object"}}
+  }
+}`
+
+const subchartSchema = `{
+  "type": "object",
+  "properties": {
+    "replicas": {"type": "integer", "minimum": 1}
+  }
+}`
+
+func runInfo() map[string]interface{} {
+	return map[string]interface{}{
+		"kind":                      "Values",
+		"operatingSystemMajor":      "rhel8",
+		"operatingSystemMajorMinor": "rhel8.4",
+		"operatingSystemDecimal":    "8.4",
+		"kernelFullVersion":         "4.18.0-305.el8.x86_64",
+		"kernelPatchVersion":        "4.18.0-305",
+		"driverToolkitImage":        "quay.io/openshift/driver-toolkit",
+		"platform":                  "OCP",
+		"clusterVersion":            "4.9.0",
+		"clusterVersionMajorMinor":  "4.9",
+		"clusterUpgradeInfo":        map[string]interface{}{},
+		"pushSecretName":            "",
+		"osImageURL":                "",
+		"proxy":                     map[string]interface{}{"HttpProxy": ""},
+		"groupName":                 map[string]interface{}{"driverBuild": "driver-build"},
+		"specialresource":           map[string]interface{}{},
+	}
+}
+
+func newChart() *chart.Chart {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "monitoring"},
+		Schema:   []byte(subchartSchema),
+	}
+
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "driver"},
+		Schema:   []byte(chartSchema),
+	}
+	ch.AddDependency(sub)
+
+	return ch
+}
+
+var _ = Describe("Validate", func() {
+	It("should accept valid values", func() {
+		vals := runInfo()
+		vals["driver"] = map[string]interface{}{"version": "1.0"}
+		vals["monitoring"] = map[string]interface{}{"replicas": 2}
+
+		Expect(Validate(newChart(), vals)).To(Succeed())
+	})
+
+	It("should accept charts without schema", func() {
+		ch := &chart.Chart{Metadata: &chart.Metadata{Name: "simple"}}
+
+		Expect(Validate(ch, runInfo())).To(Succeed())
+	})
+
+	It("should list the JSON pointers of every violation", func() {
+		vals := runInfo()
+		vals["driver"] = map[string]interface{}{"nodes/max": "three"}
+		vals["tolerations"] = []interface{}{map[string]interface{}{}, "all"}
+		vals["monitoring"] = map[string]interface{}{"replicas": 0}
+		vals["kernelFullVersion"] = 418
+		delete(vals, "groupName")
+
+		err := Validate(newChart(), vals)
+		Expect(errors.Is(err, ErrInvalid)).To(BeTrue())
+
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Pointers()).To(Equal([]string{
+			"/driver/nodes~1max",
+			"/driver/version",
+			"/groupName",
+			"/kernelFullVersion",
+			"/monitoring/replicas",
+			"/tolerations/1",
+		}))
+		Expect(err.Error()).To(ContainSubstring("/monitoring/replicas: "))
+	})
+
+	It("should return an error for an invalid chart schema", func() {
+		ch := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "broken"},
+			Schema:   []byte("{"),
+		}
+
+		err := Validate(ch, runInfo())
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrInvalid)).To(BeFalse())
+	})
+})
+
+var _ = Describe("ValidateChart", func() {
+	It("should ignore the keys of the runtime information", func() {
+		ch := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "driver"},
+			Schema:   []byte(`{"type": "object", "required": ["kernelFullVersion", "driver"]}`),
+		}
+
+		err := ValidateChart(ch, map[string]interface{}{})
+
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Pointers()).To(Equal([]string{"/driver"}))
+
+		Expect(ValidateChart(ch, map[string]interface{}{"driver": true})).To(Succeed())
+	})
+})